@@ -0,0 +1,254 @@
+// Package audit mencatat jejak setiap request yang mengubah data ke tabel
+// append-only audit_logs. middleware.Audit menulis satu baris per request
+// (actor, action, resource, IP, request ID, status); handler yang mengubah
+// resource penting menambahkan keadaan sebelum / sesudah lewat Record,
+// dan setiap Record menjadi satu baris dengan diff field yang berubah.
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"project_uas/app/model"
+)
+
+// Default dipakai middleware.Audit (nil = audit nonaktif)
+var Default *Logger
+
+// Store menyimpan baris audit; diimplementasikan repository.AuditRepo
+type Store interface {
+	Insert(entries []model.AuditLog) error
+}
+
+type Logger struct {
+	Store Store
+}
+
+func NewLogger(store Store) *Logger {
+	return &Logger{Store: store}
+}
+
+// Write menyimpan baris audit request yang sudah selesai diproses
+func (l *Logger) Write(c *fiber.Ctx, status int) error {
+	return l.Store.Insert(Entries(c, status))
+}
+
+// key Locals
+const (
+	actionKey   = "audit_action"
+	resourceKey = "audit_resource"
+	changesKey  = "audit_changes"
+)
+
+type resource struct {
+	Type string
+	ID   string
+}
+
+// Change adalah perubahan satu resource yang dicatat handler
+type Change struct {
+	ResourceType string
+	ResourceID   string
+	Before       interface{}
+	After        interface{}
+}
+
+// SetAction dipanggil middleware.Authorize: action policy route menjadi
+// action audit. Tanpa resource, jenisnya diambil dari prefix action
+// ("user:create" → "user").
+func SetAction(c *fiber.Ctx, action string, resourceType string, resourceID string) {
+	if resourceType == "" {
+		resourceType, _, _ = strings.Cut(action, ":")
+	}
+	c.Locals(actionKey, action)
+	c.Locals(resourceKey, resource{Type: resourceType, ID: resourceID})
+}
+
+// Record mencatat keadaan resource sebelum & sesudah diubah. before nil
+// berarti resource baru dibuat.
+func Record(c *fiber.Ctx, resourceType string, resourceID string, before interface{}, after interface{}) {
+	changes, _ := c.Locals(changesKey).([]Change)
+	c.Locals(changesKey, append(changes, Change{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Before:       before,
+		After:        after,
+	}))
+}
+
+// Entries menyusun baris audit untuk request: satu per Record, atau satu
+// baris tanpa diff bila handler tidak mencatat perubahan
+func Entries(c *fiber.Ctx, status int) []model.AuditLog {
+	base := model.AuditLog{
+		Method:    c.Method(),
+		Path:      truncate(c.Path(), 255),
+		Status:    status,
+		IP:        truncate(c.IP(), 64),
+		UserAgent: truncate(c.Get(fiber.HeaderUserAgent), 255),
+		RequestID: truncate(requestID(c), 100),
+	}
+	if userID, ok := c.Locals("user_id").(string); ok && userID != "" {
+		base.ActorUserID = &userID
+	}
+	base.ActorRole, _ = c.Locals("role").(string)
+
+	// request yang ditolak sebelum Authorize (mis. token tidak valid)
+	base.Action, _ = c.Locals(actionKey).(string)
+	if base.Action == "" {
+		base.Action = truncate(c.Method()+" "+c.Route().Path, 100)
+	}
+	if res, ok := c.Locals(resourceKey).(resource); ok {
+		base.ResourceType = res.Type
+		base.ResourceID = truncate(res.ID, 100)
+	}
+
+	changes, _ := c.Locals(changesKey).([]Change)
+	return expand(base, changes)
+}
+
+// System menyusun baris audit untuk perubahan di luar request HTTP (mis.
+// perintah CLI): tanpa actor, method "CLI", status 0 dan requestID untuk
+// mengelompokkan baris satu proses
+func System(action string, command string, requestID string, changes []Change) []model.AuditLog {
+	base := model.AuditLog{
+		Action:    truncate(action, 100),
+		Method:    "CLI",
+		Path:      truncate(command, 255),
+		RequestID: truncate(requestID, 100),
+	}
+	base.ResourceType, _, _ = strings.Cut(action, ":")
+	return expand(base, changes)
+}
+
+// expand: satu baris per Change, atau base saja bila tidak ada perubahan
+func expand(base model.AuditLog, changes []Change) []model.AuditLog {
+	if len(changes) == 0 {
+		return []model.AuditLog{base}
+	}
+
+	entries := make([]model.AuditLog, 0, len(changes))
+	for _, ch := range changes {
+		e := base
+		e.ResourceType = ch.ResourceType
+		e.ResourceID = truncate(ch.ResourceID, 100)
+
+		before, after := normalize(ch.Before), normalize(ch.After)
+		e.Before = marshal(before)
+		e.After = marshal(after)
+		e.Diff = marshal(Diff(before, after))
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// FieldChange: nilai lama & baru satu field
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// Diff membandingkan field tingkat atas dua objek JSON (hasil normalize).
+// Nilai yang bukan objek dianggap satu field bernama "value".
+func Diff(before interface{}, after interface{}) map[string]FieldChange {
+	diff := map[string]FieldChange{}
+
+	b, bok := before.(map[string]interface{})
+	a, aok := after.(map[string]interface{})
+	if (before != nil && !bok) || (after != nil && !aok) {
+		if !reflect.DeepEqual(before, after) {
+			diff["value"] = FieldChange{From: before, To: after}
+		}
+		return diff
+	}
+
+	for k, from := range b {
+		if to, ok := a[k]; !ok || !reflect.DeepEqual(from, to) {
+			diff[k] = FieldChange{From: from, To: a[k]}
+		}
+	}
+	for k, to := range a {
+		if _, ok := b[k]; !ok {
+			diff[k] = FieldChange{From: nil, To: to}
+		}
+	}
+	return diff
+}
+
+// normalize mengubah nilai menjadi bentuk JSON generik (map / slice /
+// string / float64 / bool) dan menyamarkan field rahasia
+func normalize(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var out interface{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil
+	}
+	return redact(out)
+}
+
+// field yang nilainya tidak pernah disimpan di audit
+var secretFields = []string{"password", "token", "secret"}
+
+func redact(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			if isSecret(k) {
+				t[k] = "[redacted]"
+				continue
+			}
+			t[k] = redact(val)
+		}
+	case []interface{}:
+		for i := range t {
+			t[i] = redact(t[i])
+		}
+	}
+	return v
+}
+
+func isSecret(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range secretFields {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// marshal: nil disimpan sebagai NULL
+func marshal(v interface{}) *json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	msg := json.RawMessage(raw)
+	return &msg
+}
+
+// requestID dari middleware requestid (header X-Request-ID)
+func requestID(c *fiber.Ctx) string {
+	if id, ok := c.Locals("requestid").(string); ok && id != "" {
+		return id
+	}
+	return c.Get(fiber.HeaderXRequestID)
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
@@ -0,0 +1,149 @@
+// Package dispatch mengirim notifikasi ke channel eksternal (email, dst).
+// Pesan tidak dikirim langsung dari request: service menulis ke tabel
+// notification_outbox dan Worker mengirimnya di background dengan retry.
+package dispatch
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"project_uas/app/model"
+)
+
+// Message adalah pesan yang siap dikirim oleh sebuah channel
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Channel adalah satu media pengiriman notifikasi
+type Channel interface {
+	// Name dipakai sebagai nilai kolom channel di outbox & preferensi
+	Name() string
+	// Address mengembalikan alamat tujuan user untuk channel ini ("" = tidak punya)
+	Address(r model.Recipient) string
+	Send(ctx context.Context, msg Message) error
+}
+
+// =====================
+// EMAIL (SMTP)
+// =====================
+
+type SMTPChannel struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (ch *SMTPChannel) Name() string { return "email" }
+
+func (ch *SMTPChannel) Address(r model.Recipient) string { return r.Email }
+
+// Send menjalankan sesi SMTP di goroutine pemanggil. Deadline ctx dipasang
+// di koneksi dan pembatalan ctx menutup koneksi, jadi tidak ada sesi yang
+// terus berjalan (dan terkirim) setelah worker menganggapnya gagal.
+func (ch *SMTPChannel) Send(ctx context.Context, msg Message) error {
+	addr := net.JoinHostPort(ch.Host, ch.Port)
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if err := ch.session(conn, msg); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
+
+// session: EHLO, STARTTLS bila ditawarkan, AUTH, MAIL, RCPT, DATA, QUIT
+// (urutan yang sama dengan smtp.SendMail)
+func (ch *SMTPChannel) session(conn net.Conn, msg Message) error {
+	c, err := smtp.NewClient(conn, ch.Host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := c.Hello("localhost"); err != nil {
+		return err
+	}
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: ch.Host}); err != nil {
+			return err
+		}
+	}
+	if ch.Username != "" {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err := c.Auth(smtp.PlainAuth("", ch.Username, ch.Password, ch.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(ch.From); err != nil {
+		return err
+	}
+	if err := c.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(ch.build(msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+func (ch *SMTPChannel) build(msg Message) []byte {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "From: %s\r\n", ch.From)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mimeHeader(msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return []byte(b.String())
+}
+
+// mimeHeader meng-encode subject non-ASCII (RFC 2047)
+func mimeHeader(s string) string {
+	for _, r := range s {
+		if r > 127 {
+			return "=?UTF-8?B?" + base64Encode(s) + "?="
+		}
+	}
+	return s
+}
@@ -0,0 +1,261 @@
+package dispatch
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+// envelope adalah satu email yang diterima stand-in SMTP
+type envelope struct {
+	from string
+	to   []string
+	data string
+	err  error
+}
+
+// fakeSMTP menjalankan server SMTP minimal di localhost (tanpa STARTTLS /
+// AUTH) untuk satu koneksi dan mengembalikan email yang diterimanya
+func fakeSMTP(t *testing.T) (string, string, <-chan envelope) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	got := make(chan envelope, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		got <- serveSMTP(conn)
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	return host, port, got
+}
+
+func serveSMTP(conn net.Conn) envelope {
+	var env envelope
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 localhost ESMTP test")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			env.err = err
+			return env
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		upper := strings.ToUpper(cmd)
+
+		switch {
+		case strings.HasPrefix(upper, "EHLO"), strings.HasPrefix(upper, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(upper, "MAIL FROM:"):
+			env.from = strings.Trim(cmd[len("MAIL FROM:"):], "<> ")
+			reply("250 OK")
+		case strings.HasPrefix(upper, "RCPT TO:"):
+			env.to = append(env.to, strings.Trim(cmd[len("RCPT TO:"):], "<> "))
+			reply("250 OK")
+		case upper == "DATA":
+			reply("354 end with <CRLF>.<CRLF>")
+			var b strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					env.err = err
+					return env
+				}
+				if l == ".\r\n" {
+					break
+				}
+				b.WriteString(strings.TrimPrefix(l, "."))
+			}
+			env.data = b.String()
+			reply("250 OK queued")
+		case upper == "QUIT":
+			reply("221 bye")
+			return env
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func TestSMTPChannelSendsRenderedTemplate(t *testing.T) {
+	templates, err := LoadTemplates("id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := map[string]interface{}{
+		"Name": "Budi",
+		"Note": "sertifikat buram",
+		"Link": "https://prestasi.example/achievements/1",
+	}
+
+	tests := []struct {
+		locale  string
+		subject string
+		body    []string
+	}{
+		{"id", "Prestasi Ditolak", []string{
+			"Halo Budi,",
+			"Prestasi Anda ditolak dengan catatan: sertifikat buram",
+			"Lihat detail: https://prestasi.example/achievements/1",
+		}},
+		{"en", "Achievement Rejected", []string{
+			"Hello Budi,",
+			"Your achievement was rejected with the note: sertifikat buram",
+			"View details: https://prestasi.example/achievements/1",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			rendered, err := templates.Render(tt.locale, "achievement_rejected", data)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+
+			host, port, got := fakeSMTP(t)
+			ch := &SMTPChannel{Host: host, Port: port, From: "noreply@prestasi.example"}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			err = ch.Send(ctx, Message{To: "budi@mail.com", Subject: rendered.Subject, Body: rendered.Body})
+			if err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+
+			env := <-got
+			if env.err != nil {
+				t.Fatalf("stand-in: %v", env.err)
+			}
+			if env.from != "noreply@prestasi.example" || len(env.to) != 1 || env.to[0] != "budi@mail.com" {
+				t.Fatalf("envelope from %q to %v", env.from, env.to)
+			}
+
+			msg, err := mail.ReadMessage(strings.NewReader(env.data))
+			if err != nil {
+				t.Fatalf("parse message: %v", err)
+			}
+			headers := map[string]string{
+				"From":                      "noreply@prestasi.example",
+				"To":                        "budi@mail.com",
+				"Subject":                   tt.subject,
+				"Mime-Version":              "1.0",
+				"Content-Type":              "text/plain; charset=UTF-8",
+				"Content-Transfer-Encoding": "8bit",
+			}
+			for k, want := range headers {
+				if v := msg.Header.Get(k); v != want {
+					t.Errorf("header %s = %q, want %q", k, v, want)
+				}
+			}
+			if _, err := msg.Header.Date(); err != nil {
+				t.Errorf("Date header: %v", err)
+			}
+
+			raw, _ := io.ReadAll(msg.Body)
+			body := string(raw)
+			if strings.Contains(strings.ReplaceAll(body, "\r\n", ""), "\n") {
+				t.Error("body has bare LF line endings")
+			}
+			for _, want := range tt.body {
+				if !strings.Contains(body, want) {
+					t.Errorf("body missing %q:\n%s", want, body)
+				}
+			}
+		})
+	}
+}
+
+func TestSMTPChannelEncodesNonASCIISubject(t *testing.T) {
+	host, port, got := fakeSMTP(t)
+	ch := &SMTPChannel{Host: host, Port: port, From: "noreply@prestasi.example"}
+
+	subject := "Prestasi «Juara 1» diverifikasi ✓"
+	if err := ch.Send(context.Background(), Message{To: "budi@mail.com", Subject: subject, Body: "isi"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	env := <-got
+	msg, err := mail.ReadMessage(strings.NewReader(env.data))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	raw := msg.Header.Get("Subject")
+	if !strings.HasPrefix(raw, "=?UTF-8?B?") {
+		t.Fatalf("subject not RFC 2047 encoded: %q", raw)
+	}
+	decoded, err := new(mime.WordDecoder).DecodeHeader(raw)
+	if err != nil || decoded != subject {
+		t.Fatalf("decoded subject = %q, %v", decoded, err)
+	}
+}
+
+// server yang menggantung setelah DATA: Send harus kembali saat deadline
+// ctx dan menutup koneksinya, bukan meninggalkan sesi yang masih berjalan
+func TestSMTPChannelHonoursDeadline(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	closed := make(chan error, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		io.WriteString(conn, "220 localhost ESMTP slow\r\n")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				closed <- err
+				return
+			}
+			switch strings.ToUpper(strings.TrimRight(line, "\r\n")) {
+			case "DATA":
+				// tidak pernah membalas
+			default:
+				io.WriteString(conn, "250 OK\r\n")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	ch := &SMTPChannel{Host: host, Port: port, From: "noreply@prestasi.example"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = ch.Send(ctx, Message{To: "budi@mail.com", Subject: "s", Body: "b"})
+	if err == nil {
+		t.Fatal("Send succeeded against a stalled server")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("Send returned after %v", elapsed)
+	}
+
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection still open after Send returned")
+	}
+}
@@ -0,0 +1,122 @@
+package dispatch
+
+import (
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// Rendered adalah hasil render satu jenis notifikasi untuk satu locale
+type Rendered struct {
+	Title   string // judul notifikasi in-app
+	Message string // isi notifikasi in-app
+	Subject string // subject email (default: Title)
+	Body    string // isi email (default: Message)
+}
+
+// Templates menyimpan template per locale (templates/<locale>.tmpl).
+// Setiap jenis notifikasi mendefinisikan blok "<type>.title",
+// "<type>.message" dan opsional "<type>.subject" / "<type>.email".
+// Tanpa "<type>.email", isi email adalah message dibungkus "email.wrap".
+type Templates struct {
+	defaultLocale string
+	byLocale      map[string]*template.Template
+}
+
+func LoadTemplates(defaultLocale string) (*Templates, error) {
+	files, err := templateFS.ReadDir("templates")
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Templates{defaultLocale: defaultLocale, byLocale: map[string]*template.Template{}}
+
+	for _, f := range files {
+		locale := strings.TrimSuffix(f.Name(), ".tmpl")
+
+		tmpl, err := template.New(locale).ParseFS(templateFS, path.Join("templates", f.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("parse template %s: %w", f.Name(), err)
+		}
+		t.byLocale[locale] = tmpl
+	}
+
+	if t.byLocale[defaultLocale] == nil {
+		return nil, fmt.Errorf("missing templates for default locale %q", defaultLocale)
+	}
+
+	return t, nil
+}
+
+// HasLocale melaporkan apakah template untuk locale tersedia
+func (t *Templates) HasLocale(locale string) bool {
+	return t.byLocale[locale] != nil
+}
+
+// Render merender notifikasi; locale yang tidak dikenal jatuh ke default
+func (t *Templates) Render(locale string, notifType string, data map[string]interface{}) (Rendered, error) {
+	tmpl := t.byLocale[locale]
+	if tmpl == nil {
+		tmpl = t.byLocale[t.defaultLocale]
+	}
+
+	var r Rendered
+	var err error
+
+	if r.Title, err = execute(tmpl, notifType+".title", data, true); err != nil {
+		return r, err
+	}
+	if r.Message, err = execute(tmpl, notifType+".message", data, true); err != nil {
+		return r, err
+	}
+	if r.Subject, err = execute(tmpl, notifType+".subject", data, false); err != nil {
+		return r, err
+	}
+	if r.Body, err = execute(tmpl, notifType+".email", data, false); err != nil {
+		return r, err
+	}
+
+	if r.Subject == "" {
+		r.Subject = r.Title
+	}
+	if r.Body == "" {
+		wrapData := map[string]interface{}{"Content": r.Message}
+		for k, v := range data {
+			wrapData[k] = v
+		}
+		if r.Body, err = execute(tmpl, "email.wrap", wrapData, false); err != nil {
+			return r, err
+		}
+		if r.Body == "" {
+			r.Body = r.Message
+		}
+	}
+
+	return r, nil
+}
+
+func execute(tmpl *template.Template, name string, data map[string]interface{}, required bool) (string, error) {
+	if tmpl.Lookup(name) == nil {
+		if required {
+			return "", fmt.Errorf("template %q not found", name)
+		}
+		return "", nil
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func base64Encode(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
@@ -0,0 +1,113 @@
+package dispatch
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"project_uas/app/model"
+	"project_uas/app/repository"
+)
+
+const (
+	maxAttempts = 8
+	baseBackoff = 30 * time.Second
+	maxBackoff  = time.Hour
+	sendTimeout = 30 * time.Second
+)
+
+// Dispatcher menyimpan channel yang aktif dan memproses outbox
+type Dispatcher struct {
+	Repo     *repository.OutboxRepo
+	channels map[string]Channel
+}
+
+func NewDispatcher(repo *repository.OutboxRepo, channels ...Channel) *Dispatcher {
+	d := &Dispatcher{Repo: repo, channels: map[string]Channel{}}
+	for _, ch := range channels {
+		d.channels[ch.Name()] = ch
+	}
+	return d
+}
+
+// Channels mengembalikan channel yang aktif (urutan tidak dijamin)
+func (d *Dispatcher) Channels() []Channel {
+	list := make([]Channel, 0, len(d.channels))
+	for _, ch := range d.channels {
+		list = append(list, ch)
+	}
+	return list
+}
+
+// Run memproses outbox secara berkala sampai ctx dibatalkan
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	if len(d.channels) == 0 {
+		log.Println("dispatch: no channels configured, outbox worker idle")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		d.processBatch(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Dispatcher) processBatch(ctx context.Context) {
+	msgs, err := d.Repo.Claim(20, 5*time.Minute)
+	if err != nil {
+		log.Println("dispatch: claim outbox:", err)
+		return
+	}
+
+	for _, m := range msgs {
+		d.deliver(ctx, m)
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, m model.OutboxMessage) {
+	ch, ok := d.channels[m.Channel]
+	if !ok {
+		_ = d.Repo.MarkRetry(m.ID, "channel not configured: "+m.Channel, time.Now(), true)
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	err := ch.Send(sendCtx, Message{To: m.Recipient, Subject: m.Subject, Body: m.Body})
+	if err == nil {
+		if err := d.Repo.MarkSent(m.ID); err != nil {
+			log.Println("dispatch: mark sent:", err)
+		}
+		return
+	}
+
+	final := m.Attempts >= maxAttempts
+	next := time.Now().Add(backoff(m.Attempts))
+
+	log.Printf("dispatch: %s to %s failed (attempt %d): %v", m.Channel, m.Recipient, m.Attempts, err)
+
+	if err := d.Repo.MarkRetry(m.ID, err.Error(), next, final); err != nil {
+		log.Println("dispatch: mark retry:", err)
+	}
+}
+
+// backoff eksponensial: 30s, 1m, 2m, 4m, ... maksimal 1 jam
+func backoff(attempt int) time.Duration {
+	d := baseBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
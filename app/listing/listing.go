@@ -0,0 +1,347 @@
+// Package listing adalah lapisan query bersama untuk semua endpoint list:
+// cursor (keyset) pagination, ?sort=, filter per field, ?q= dan total,
+// dengan envelope respons yang seragam:
+//
+//	{"data": [...], "meta": {"limit", "sort", "total", "has_more", "next_cursor"}}
+//
+// Keyset dipakai (bukan OFFSET) supaya halaman ke-1000 sama cepatnya
+// dengan halaman pertama pada tabel besar.
+package listing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Field adalah kolom yang boleh dipakai di ?sort=. Kolom harus NOT NULL
+// supaya perbandingan keyset benar. Nilai cursor dikirim tanpa cast
+// sehingga Postgres memakai tipe kolom itu sendiri (timestamp/tz, text, int).
+type Field struct {
+	Column string // ekspresi SQL, mis. "u.created_at"
+	Key    string // nama kolom hasil SELECT (tag db) untuk membaca nilai cursor
+}
+
+// Operator filter
+const (
+	OpEq    = "eq"    // kolom = nilai
+	OpIn    = "in"    // kolom = ANY(nilai dipisah koma)
+	OpBool  = "bool"  // kolom = true/false
+	OpFrom  = "from"  // kolom >= tanggal (YYYY-MM-DD)
+	OpUntil = "until" // kolom < tanggal + 1 hari
+)
+
+// Filter memetakan satu query param ke kondisi SQL
+type Filter struct {
+	Column string
+	Op     string
+}
+
+// Spec mendeskripsikan apa yang boleh di-sort / filter oleh sebuah endpoint
+type Spec struct {
+	Sorts       map[string]Field
+	DefaultSort string // mis. "-created_at"
+	IDColumn    string // tiebreaker unik, mis. "u.id"
+	IDKey       string // tag db kolom id di hasil SELECT (default "id")
+	Filters     map[string]Filter
+	Search      []string // kolom untuk ?q= (ILIKE)
+}
+
+// Params adalah hasil parsing query string untuk satu request
+type Params struct {
+	Limit int
+	Sort  string // nama field tanpa tanda
+	Desc  bool
+
+	after   *cursor
+	filters []string
+	args    []interface{}
+}
+
+// Error adalah kesalahan input (dikembalikan sebagai 400)
+type Error struct{ msg string }
+
+func (e *Error) Error() string { return e.msg }
+
+func badRequest(format string, args ...interface{}) error {
+	return &Error{msg: fmt.Sprintf(format, args...)}
+}
+
+type cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// Parse membaca ?limit, ?cursor, ?sort, ?q dan filter yang terdaftar di spec
+func Parse(c *fiber.Ctx, spec Spec) (*Params, error) {
+	p := &Params{Limit: DefaultLimit}
+
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, badRequest("limit must be a positive number")
+		}
+		if n > MaxLimit {
+			n = MaxLimit
+		}
+		p.Limit = n
+	}
+
+	sortParam := c.Query("sort", spec.DefaultSort)
+	p.Desc = strings.HasPrefix(sortParam, "-")
+	p.Sort = strings.TrimPrefix(sortParam, "-")
+	if _, ok := spec.Sorts[p.Sort]; !ok {
+		return nil, badRequest("cannot sort by %q (allowed: %s)", p.Sort, strings.Join(sortNames(spec), ", "))
+	}
+
+	if v := c.Query("cursor"); v != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(v)
+		cur := &cursor{}
+		if err != nil || json.Unmarshal(raw, cur) != nil {
+			return nil, badRequest("invalid cursor")
+		}
+		if cur.Sort != sortParam {
+			return nil, badRequest("cursor was issued for a different sort")
+		}
+		p.after = cur
+	}
+
+	for name, f := range spec.Filters {
+		v := strings.TrimSpace(c.Query(name))
+		if v == "" {
+			continue
+		}
+		if err := p.addFilter(name, f, v); err != nil {
+			return nil, err
+		}
+	}
+
+	if q := strings.TrimSpace(c.Query("q")); q != "" && len(spec.Search) > 0 {
+		like := "%" + escapeLike(q) + "%"
+		parts := make([]string, 0, len(spec.Search))
+		for _, col := range spec.Search {
+			parts = append(parts, col+" ILIKE ?")
+			p.args = append(p.args, like)
+		}
+		p.filters = append(p.filters, "("+strings.Join(parts, " OR ")+")")
+	}
+
+	return p, nil
+}
+
+func (p *Params) addFilter(name string, f Filter, v string) error {
+	switch f.Op {
+	case OpEq, "":
+		p.filters = append(p.filters, f.Column+" = ?")
+		p.args = append(p.args, v)
+	case OpIn:
+		var values []string
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				values = append(values, s)
+			}
+		}
+		p.filters = append(p.filters, f.Column+"::text = ANY(?)")
+		p.args = append(p.args, pq.Array(values))
+	case OpBool:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return badRequest("%s must be true or false", name)
+		}
+		p.filters = append(p.filters, f.Column+" = ?")
+		p.args = append(p.args, b)
+	case OpFrom, OpUntil:
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return badRequest("%s must be YYYY-MM-DD", name)
+		}
+		if f.Op == OpFrom {
+			p.filters = append(p.filters, f.Column+" >= ?")
+		} else {
+			p.filters = append(p.filters, f.Column+" < ?")
+			t = t.AddDate(0, 0, 1)
+		}
+		p.args = append(p.args, t)
+	default:
+		return fmt.Errorf("listing: unknown filter op %q", f.Op)
+	}
+	return nil
+}
+
+// Query adalah bagian tetap dari query sebuah endpoint. Placeholder
+// ditulis dengan "?" (diubah ke $n oleh sqlx.Rebind).
+type Query struct {
+	Select string   // daftar kolom
+	From   string   // tabel + join
+	Where  []string // kondisi tetap (scope), digabung dengan AND
+	Args   []interface{}
+}
+
+// Page adalah satu halaman hasil
+type Page[T any] struct {
+	Items []T
+	Meta  Meta
+}
+
+// Meta adalah bagian "meta" dari envelope respons
+type Meta struct {
+	Limit      int     `json:"limit"`
+	Sort       string  `json:"sort"`
+	Total      int     `json:"total"`
+	HasMore    bool    `json:"has_more"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+// Fetch menjalankan query halaman + total count
+func Fetch[T any](db *sqlx.DB, spec Spec, p *Params, q Query) (*Page[T], error) {
+	field := spec.Sorts[p.Sort]
+	idKey := spec.IDKey
+	if idKey == "" {
+		idKey = "id"
+	}
+
+	where := append(append([]string{}, q.Where...), p.filters...)
+	args := append(append([]interface{}{}, q.Args...), p.args...)
+
+	// total mengikuti filter, tidak terpengaruh cursor
+	var total int
+	countSQL := "SELECT COUNT(*) FROM " + q.From + whereClause(where)
+	if err := db.Get(&total, db.Rebind(countSQL), args...); err != nil {
+		return nil, err
+	}
+
+	dir, cmp := "ASC", ">"
+	if p.Desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	if p.after != nil {
+		where = append(where, fmt.Sprintf("(%s, %s::text) %s (?, ?)", field.Column, spec.IDColumn, cmp))
+		args = append(args, p.after.Value, p.after.ID)
+	}
+
+	// ambil 1 lebih untuk tahu masih ada halaman berikutnya
+	pageSQL := fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s %s, %s::text %s LIMIT %d",
+		q.Select, q.From, whereClause(where), field.Column, dir, spec.IDColumn, dir, p.Limit+1)
+
+	items := []T{}
+	if err := db.Select(&items, db.Rebind(pageSQL), args...); err != nil {
+		return nil, err
+	}
+
+	sortParam := p.Sort
+	if p.Desc {
+		sortParam = "-" + sortParam
+	}
+
+	page := &Page[T]{Items: items, Meta: Meta{Limit: p.Limit, Sort: sortParam, Total: total}}
+	if len(items) > p.Limit {
+		page.Items = items[:p.Limit]
+		page.Meta.HasMore = true
+
+		next, err := encodeCursor(db, page.Items[p.Limit-1], sortParam, field.Key, idKey)
+		if err != nil {
+			return nil, err
+		}
+		page.Meta.NextCursor = &next
+	}
+
+	return page, nil
+}
+
+// Respond menulis envelope standar; extra digabung ke level atas
+func Respond(c *fiber.Ctx, data interface{}, meta Meta, extra ...fiber.Map) error {
+	body := fiber.Map{"data": data, "meta": meta}
+	for _, m := range extra {
+		for k, v := range m {
+			body[k] = v
+		}
+	}
+	return c.JSON(body)
+}
+
+// Fail menerjemahkan error Parse/Fetch menjadi respons 400 / 500. Error
+// internal (SQL, driver) hanya di-log, tidak dikirim ke client.
+func Fail(c *fiber.Ctx, err error) error {
+	var bad *Error
+	if errors.As(err, &bad) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": bad.msg})
+	}
+	log.Printf("listing: %s %s: %v", c.Method(), c.Path(), err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": "failed fetch list",
+	})
+}
+
+func encodeCursor(db *sqlx.DB, row interface{}, sort string, key string, idKey string) (string, error) {
+	v := reflect.Indirect(reflect.ValueOf(row))
+
+	value, err := fieldString(db, v, key)
+	if err != nil {
+		return "", err
+	}
+	id, err := fieldString(db, v, idKey)
+	if err != nil {
+		return "", err
+	}
+
+	raw, _ := json.Marshal(cursor{Sort: sort, Value: value, ID: id})
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func fieldString(db *sqlx.DB, v reflect.Value, key string) (string, error) {
+	f := db.Mapper.FieldByName(v, key)
+	if !f.IsValid() {
+		return "", fmt.Errorf("listing: column %q not found in result struct", key)
+	}
+	for f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			return "", fmt.Errorf("listing: sort column %q is NULL", key)
+		}
+		f = f.Elem()
+	}
+
+	switch x := f.Interface().(type) {
+	case time.Time:
+		return x.Format(time.RFC3339Nano), nil
+	default:
+		return fmt.Sprint(x), nil
+	}
+}
+
+func whereClause(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conds, " AND ")
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func sortNames(spec Spec) []string {
+	names := make([]string, 0, len(spec.Sorts))
+	for name := range spec.Sorts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
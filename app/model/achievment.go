@@ -29,7 +29,20 @@ type AchievementHistory struct {
 	AchievementRefID string    `db:"achievement_ref_id" json:"achievement_ref_id"`
 	OldStatus        string    `db:"old_status" json:"old_status"`
 	NewStatus        string    `db:"new_status" json:"new_status"`
+	Stage            *string   `db:"stage" json:"stage"`
 	ChangedBy        string    `db:"changed_by" json:"changed_by"`
 	Note             string    `db:"note" json:"note"`
 	ChangedAt        time.Time `db:"changed_at" json:"changed_at"`
+
+	Comments []RevisionComment `db:"-" json:"comments,omitempty"`
+}
+
+// RevisionComment adalah komentar dosen untuk satu field saat meminta revisi
+type RevisionComment struct {
+	ID               string    `db:"id" json:"id"`
+	HistoryID        string    `db:"history_id" json:"history_id"`
+	AchievementRefID string    `db:"achievement_ref_id" json:"achievement_ref_id"`
+	Field            string    `db:"field" json:"field"`
+	Comment          string    `db:"comment" json:"comment"`
+	CreatedAt        time.Time `db:"created_at" json:"created_at"`
 }
@@ -1,6 +1,10 @@
 package model
 
-import "time"
+import (
+    "time"
+
+    "github.com/lib/pq"
+)
 
 // AchievementReference tracks the administrative metadata in Postgres
 type AchievementReference struct {
@@ -12,7 +16,57 @@ type AchievementReference struct {
     VerifiedAt         *time.Time `db:"verified_at" json:"verified_at"`
     VerifiedBy         *string    `db:"verified_by" json:"verified_by"`  
     RejectionNote      *string    `db:"rejection_note" json:"rejection_note"`
+    WorkflowStages     pq.StringArray `db:"workflow_stages" json:"workflow_stages"`
+    CurrentStage       int        `db:"current_stage" json:"current_stage"`
     CreatedAt          time.Time  `db:"created_at" json:"created_at"`
     UpdatedAt          time.Time  `db:"updated_at" json:"updated_at"`
 }
 
+
+// IntegrityError melaporkan reference Postgres yang dokumen Mongo-nya
+// hilang atau rusak, supaya tidak hilang diam-diam dari listing
+type IntegrityError struct {
+    RefID              string `json:"reference_id"`
+    MongoAchievementID string `json:"mongo_achievement_id"`
+    Reason             string `json:"reason"`
+}
+
+// Status scan antivirus lampiran
+const (
+    ScanPending  = "pending_scan"
+    ScanClean    = "clean"
+    ScanInfected = "infected"
+    ScanFailed   = "scan_failed" // gagal di-scan setelah batas percobaan
+)
+
+// Status pratinjau (thumbnail) lampiran
+const (
+    PreviewPending     = "pending"
+    PreviewReady       = "ready"
+    PreviewUnsupported = "unsupported"
+    PreviewFailed      = "failed"
+)
+
+// AchievementAttachment adalah metadata lampiran; isinya ada di storage
+// dengan key berbasis SHA-256 (lihat package storage). Selama belum
+// lolos scan, StorageKey menunjuk ke area karantina.
+type AchievementAttachment struct {
+    ID               string    `db:"id" json:"id"`
+    AchievementRefID string    `db:"achievement_ref_id" json:"achievement_ref_id"`
+    FileName         string    `db:"file_name" json:"file_name"`
+    FileURL          string    `db:"file_url" json:"file_url"`
+    FileType         string    `db:"file_type" json:"file_type"`
+    ContentType      string    `db:"content_type" json:"content_type"`
+    StorageKey       string    `db:"storage_key" json:"-"`
+    Checksum         string    `db:"checksum" json:"checksum"`
+    FileSize         int64     `db:"file_size" json:"file_size"`
+    ScanStatus       string     `db:"scan_status" json:"scan_status"`
+    ScanSignature    *string    `db:"scan_signature" json:"scan_signature,omitempty"`
+    ScanAttempts     int        `db:"scan_attempts" json:"-"`
+    ScannedAt        *time.Time `db:"scanned_at" json:"scanned_at,omitempty"`
+    PreviewStatus    string     `db:"preview_status" json:"preview_status"`
+    PreviewKey       *string    `db:"preview_key" json:"-"`
+    PreviewAttempts  int        `db:"preview_attempts" json:"-"`
+    UploadedBy       string    `db:"uploaded_by" json:"uploaded_by"`
+    UploadedAt       time.Time `db:"uploaded_at" json:"uploaded_at"`
+}
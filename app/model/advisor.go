@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+// Cara dosen wali ditetapkan (advisor_assignments.method)
+const (
+	AssignInitial = "initial" // advisor yang sudah ada sebelum riwayat dicatat / seed
+	AssignManual  = "manual"  // PUT /students/:id/advisor
+	AssignBulk    = "bulk"    // POST /students/advisors
+	AssignAuto    = "auto"    // POST /students/advisors/auto
+	AssignImport  = "import"  // impor user massal
+)
+
+// AdvisorAssignment adalah satu periode dosen wali seorang mahasiswa;
+// EffectiveTo nil berarti masih berlaku
+type AdvisorAssignment struct {
+	ID                string     `db:"id" json:"id"`
+	StudentID         string     `db:"student_id" json:"student_id"`
+	AdvisorID         *string    `db:"advisor_id" json:"advisor_id"`
+	AdvisorLecturerID *string    `db:"advisor_lecturer_id" json:"advisor_lecturer_id"`
+	AdvisorName       *string    `db:"advisor_name" json:"advisor_name"`
+	PreviousAdvisorID *string    `db:"previous_advisor_id" json:"previous_advisor_id"`
+	Method            string     `db:"method" json:"method"`
+	AssignedBy        *string    `db:"assigned_by" json:"assigned_by"`
+	EffectiveFrom     time.Time  `db:"effective_from" json:"effective_from"`
+	EffectiveTo       *time.Time `db:"effective_to" json:"effective_to"`
+}
+
+// AdvisorChange adalah satu penetapan yang diminta (student → lecturer).
+// Field lain diisi repository setelah diterapkan.
+type AdvisorChange struct {
+	StudentID         string  `json:"student_id"`
+	AdvisorID         string  `json:"advisor_id"`
+	StudentUserID     string  `json:"-"`
+	StudentNIM        string  `json:"student_nim,omitempty"`
+	PreviousAdvisorID *string `json:"previous_advisor_id"`
+	Changed           bool    `json:"changed"`
+}
+
+// AdvisorLoad: jumlah mahasiswa bimbingan aktif seorang dosen
+type AdvisorLoad struct {
+	LecturerID   string  `db:"id" json:"id"`
+	UserID       string  `db:"user_id" json:"user_id"`
+	LecturerCode string  `db:"lecturer_id" json:"lecturer_id"`
+	FullName     string  `db:"full_name" json:"full_name"`
+	DepartmentID *string `db:"department_id" json:"department_id"`
+	Advisees     int     `db:"advisees" json:"advisees"`
+}
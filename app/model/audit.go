@@ -0,0 +1,28 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditLog adalah satu baris jejak audit (append-only). Before / After
+// berisi keadaan resource yang dicatat handler; Diff hanya field yang
+// berubah: {"field": {"from": ..., "to": ...}}.
+type AuditLog struct {
+	ID           string           `db:"id" json:"id"`
+	OccurredAt   time.Time        `db:"occurred_at" json:"occurred_at"`
+	ActorUserID  *string          `db:"actor_user_id" json:"actor_user_id"`
+	ActorRole    string           `db:"actor_role" json:"actor_role"`
+	Action       string           `db:"action" json:"action"`
+	ResourceType string           `db:"resource_type" json:"resource_type"`
+	ResourceID   string           `db:"resource_id" json:"resource_id"`
+	Method       string           `db:"method" json:"method"`
+	Path         string           `db:"path" json:"path"`
+	Status       int              `db:"status" json:"status"`
+	IP           string           `db:"ip" json:"ip"`
+	UserAgent    string           `db:"user_agent" json:"user_agent"`
+	RequestID    string           `db:"request_id" json:"request_id"`
+	Before       *json.RawMessage `db:"before" json:"before"`
+	After        *json.RawMessage `db:"after" json:"after"`
+	Diff         *json.RawMessage `db:"diff" json:"diff"`
+}
@@ -1,8 +1,11 @@
 package model
 
+import "time"
+
 type LoginRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	Device   string `json:"device"` // opsional, mis. "Lab Komputer 3"
 }
 
 type LoginResponse struct {
@@ -16,3 +19,20 @@ type RegisterRequest struct {
 	Password string `json:"password"`
 	RoleID   string `json:"role_id"`
 }
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// RefreshToken adalah catatan server-side untuk setiap refresh token yang
+// diterbitkan. Token dalam satu FamilyID berasal dari satu login yang sama.
+type RefreshToken struct {
+	ID         string     `db:"id" json:"id"`
+	FamilyID   string     `db:"family_id" json:"family_id"`
+	UserID     string     `db:"user_id" json:"user_id"`
+	ExpiresAt  time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt     *time.Time `db:"used_at" json:"used_at"`
+	ReplacedBy *string    `db:"replaced_by" json:"replaced_by"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+}
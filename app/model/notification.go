@@ -0,0 +1,86 @@
+package model
+
+import "time"
+
+// Jenis notifikasi
+const (
+	NotifAchievementSubmitted = "achievement_submitted"
+	NotifStageApproved        = "achievement_stage_approved"
+	NotifAchievementVerified  = "achievement_verified"
+	NotifAchievementRejected  = "achievement_rejected"
+	NotifRevisionRequested    = "revision_requested"
+	NotifAdvisorAssigned      = "advisor_assigned"
+	NotifAdviseeAssigned      = "advisee_assigned"
+)
+
+// NotificationTypes adalah daftar jenis notifikasi yang bisa diatur preferensinya
+var NotificationTypes = []string{
+	NotifAchievementSubmitted,
+	NotifStageApproved,
+	NotifAchievementVerified,
+	NotifAchievementRejected,
+	NotifRevisionRequested,
+	NotifAdvisorAssigned,
+	NotifAdviseeAssigned,
+}
+
+type Notification struct {
+	ID          string     `db:"id" json:"id"`
+	UserID      string     `db:"user_id" json:"user_id"`
+	Type        string     `db:"type" json:"type"`
+	Title       string     `db:"title" json:"title"`
+	Message     string     `db:"message" json:"message"`
+	ReferenceID *string    `db:"reference_id" json:"reference_id"`
+	IsRead      bool       `db:"is_read" json:"is_read"`
+	ReadAt      *time.Time `db:"read_at" json:"read_at"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+}
+
+// PendingNotification adalah notifikasi yang sudah dirender beserta pesan
+// outbox-nya, disimpan dalam transaksi aksi yang memicunya (mis. transisi
+// workflow) supaya notifikasi tidak hilang bila proses mati setelah commit
+type PendingNotification struct {
+	Notification Notification
+	Outbox       []OutboxMessage
+}
+
+// Recipient adalah data user yang dibutuhkan untuk merender & mengirim notifikasi
+type Recipient struct {
+	UserID   string `db:"id"`
+	Email    string `db:"email"`
+	FullName string `db:"full_name"`
+	Locale   string `db:"locale"`
+}
+
+// NotificationPreference: opt-out per user, channel dan jenis notifikasi.
+// NotificationType "*" berlaku untuk semua jenis pada channel tersebut.
+type NotificationPreference struct {
+	Channel          string `db:"channel" json:"channel"`
+	NotificationType string `db:"notification_type" json:"type"`
+	Enabled          bool   `db:"enabled" json:"enabled"`
+}
+
+// Status outbox
+const (
+	OutboxPending = "pending"
+	OutboxSending = "sending"
+	OutboxSent    = "sent"
+	OutboxFailed  = "failed"
+)
+
+// OutboxMessage adalah satu pengiriman notifikasi lewat channel eksternal
+// (email, dst) yang diproses worker di background.
+type OutboxMessage struct {
+	ID             string     `db:"id" json:"id"`
+	NotificationID *string    `db:"notification_id" json:"notification_id"`
+	Channel        string     `db:"channel" json:"channel"`
+	Recipient      string     `db:"recipient" json:"recipient"`
+	Subject        string     `db:"subject" json:"subject"`
+	Body           string     `db:"body" json:"body"`
+	Status         string     `db:"status" json:"status"`
+	Attempts       int        `db:"attempts" json:"attempts"`
+	LastError      *string    `db:"last_error" json:"last_error"`
+	NextAttemptAt  time.Time  `db:"next_attempt_at" json:"next_attempt_at"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	SentAt         *time.Time `db:"sent_at" json:"sent_at"`
+}
@@ -0,0 +1,64 @@
+package model
+
+import "time"
+
+// Jenis unit organisasi (dipakai unit_admins)
+const (
+	UnitFaculty      = "faculty"
+	UnitDepartment   = "department"
+	UnitStudyProgram = "study_program"
+)
+
+type Faculty struct {
+	ID        string    `db:"id" json:"id"`
+	Code      string    `db:"code" json:"code"`
+	Name      string    `db:"name" json:"name"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+
+	Departments []Department `db:"-" json:"departments,omitempty"`
+}
+
+// Department.HeadUserID adalah kepala departemen: approver stage
+// head_of_department untuk mahasiswa di departemen ini
+type Department struct {
+	ID         string    `db:"id" json:"id"`
+	FacultyID  string    `db:"faculty_id" json:"faculty_id"`
+	Code       string    `db:"code" json:"code"`
+	Name       string    `db:"name" json:"name"`
+	HeadUserID *string   `db:"head_user_id" json:"head_user_id"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+
+	StudyPrograms []StudyProgram `db:"-" json:"study_programs,omitempty"`
+}
+
+type StudyProgram struct {
+	ID           string    `db:"id" json:"id"`
+	DepartmentID string    `db:"department_id" json:"department_id"`
+	Code         string    `db:"code" json:"code"`
+	Name         string    `db:"name" json:"name"`
+	Degree       string    `db:"degree" json:"degree"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// UnitAdmin mendelegasikan scope admin ke satu unit beserta turunannya
+type UnitAdmin struct {
+	UserID    string    `db:"user_id" json:"user_id"`
+	Username  string    `db:"username" json:"username"`
+	UnitType  string    `db:"unit_type" json:"unit_type"`
+	UnitID    string    `db:"unit_id" json:"unit_id"`
+	UnitName  string    `db:"unit_name" json:"unit_name"`
+	CreatedBy *string   `db:"created_by" json:"created_by"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// UnitReport: jumlah prestasi per status untuk satu unit
+type UnitReport struct {
+	UnitID   string         `json:"unit_id"`
+	Code     string         `json:"code"`
+	Name     string         `json:"name"`
+	Total    int            `json:"total"`
+	Statuses map[string]int `json:"statuses"`
+}
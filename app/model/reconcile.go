@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// Jenis temuan rekonsiliasi Postgres ↔ Mongo ↔ storage
+const (
+	IssueMissingDocument = "missing_document" // reference tanpa dokumen Mongo
+	IssueOrphanDocument  = "orphan_document"  // dokumen Mongo tanpa reference
+	IssueDeleteMismatch  = "delete_mismatch"  // deleted_at Mongo ≠ status 'deleted'
+	IssueMissingFile     = "missing_file"     // lampiran tanpa file di storage
+	IssueMissingPreview  = "missing_preview"  // preview ready tapi file-nya hilang
+	IssueFailedSaga      = "failed_saga"      // kompensasi saga menyerah
+)
+
+// ReconcileIssue adalah satu temuan; Fixed diisi bila mode --fix memperbaikinya
+type ReconcileIssue struct {
+	Kind         string `json:"kind"`
+	RefID        string `json:"reference_id,omitempty"`
+	MongoID      string `json:"mongo_achievement_id,omitempty"`
+	AttachmentID string `json:"attachment_id,omitempty"`
+	Detail       string `json:"detail"`
+	Fixable      bool   `json:"fixable"`
+	Fixed        bool   `json:"fixed"`
+	FixError     string `json:"fix_error,omitempty"`
+}
+
+type ReconcileReport struct {
+	StartedAt   time.Time        `json:"started_at"`
+	FinishedAt  time.Time        `json:"finished_at"`
+	Fix         bool             `json:"fix"`
+	References  int              `json:"references_checked"`
+	Documents   int              `json:"documents_checked"`
+	Attachments int              `json:"attachments_checked"`
+	Counts      map[string]int   `json:"counts"`
+	Issues      []ReconcileIssue `json:"issues"`
+}
+
+// ReferenceState adalah potongan reference yang dibutuhkan rekonsiliasi
+type ReferenceState struct {
+	ID                 string `db:"id"`
+	MongoAchievementID string `db:"mongo_achievement_id"`
+	Status             string `db:"status"`
+}
+
+// DocumentState adalah potongan dokumen Mongo yang dibutuhkan rekonsiliasi
+type DocumentState struct {
+	ID        string
+	Deleted   bool
+	CreatedAt time.Time
+}
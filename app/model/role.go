@@ -1,6 +1,16 @@
 package model
 
+import "time"
+
+// Role bawaan (IsSystem) tidak bisa dihapus atau diganti namanya karena
+// dipakai langsung oleh kode (mis. policy.Rules, stage advisor).
 type Role struct {
-	ID   string `db:"id" json:"id"`
-	Name string `db:"name" json:"name"`
+	ID          string    `db:"id" json:"id"`
+	Name        string    `db:"name" json:"name"`
+	Description *string   `db:"description" json:"description"`
+	IsSystem    bool      `db:"is_system" json:"is_system"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UserCount   int       `db:"user_count" json:"user_count"`
+
+	Permissions []Permission `db:"-" json:"permissions"`
 }
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// Jenis saga lintas Postgres ↔ Mongo
+const (
+	SagaCreateAchievement = "create_achievement"
+	SagaDeleteAchievement = "delete_achievement"
+)
+
+// State saga
+const (
+	SagaStarted      = "started"
+	SagaCompleted    = "completed"
+	SagaCompensating = "compensating"
+	SagaCompensated  = "compensated"
+	SagaFailed       = "failed" // kompensasi menyerah, perlu rekonsiliasi manual
+)
+
+// Saga mencatat operasi dua-store yang sedang berjalan. Dicatat sebelum
+// langkah pertama, sehingga recoverer tahu apa yang harus dibatalkan bila
+// proses mati di tengah jalan.
+type Saga struct {
+	ID            string    `db:"id" json:"id"`
+	Kind          string    `db:"kind" json:"kind"`
+	RefID         string    `db:"ref_id" json:"ref_id"`
+	MongoID       string    `db:"mongo_id" json:"mongo_id"`
+	State         string    `db:"state" json:"state"`
+	Attempts      int       `db:"attempts" json:"attempts"`
+	LastError     *string   `db:"last_error" json:"last_error"`
+	NextAttemptAt time.Time `db:"next_attempt_at" json:"next_attempt_at"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AchievementSearch adalah parameter /achievements/search
+type AchievementSearch struct {
+	Query        string
+	Categories   []string
+	Levels       []string
+	Statuses     []string
+	ProgramStudy []string
+	AcademicYear []string
+	From         *time.Time // event_date >= From
+	To           *time.Time // event_date < To
+	Sort         string     // relevance | newest | event_date
+	Page         int
+	Limit        int
+}
+
+// SearchScope membatasi prestasi yang boleh dilihat sesuai role.
+// StudentIDs nil berarti semua mahasiswa; Units membatasi ke mahasiswa di
+// unit admin (nil = tanpa batas unit).
+type SearchScope struct {
+	StudentIDs    []string
+	Units         []string
+	IncludeDrafts bool
+}
+
+// SearchCandidate adalah data Postgres untuk satu prestasi yang masuk scope
+type SearchCandidate struct {
+	RefID         string `db:"ref_id" json:"id"`
+	MongoID       string `db:"mongo_achievement_id" json:"mongo_achievement_id"`
+	Status        string `db:"status" json:"status"`
+	StudentID     string `db:"student_id" json:"student_id"`
+	StudentNumber string `db:"student_number" json:"student_number"`
+	StudentName   string `db:"student_name" json:"student_name"`
+	ProgramStudy  string `db:"program_study" json:"program_study"`
+	AcademicYear  string `db:"academic_year" json:"academic_year"`
+}
+
+// FacetCount adalah jumlah hasil per nilai facet
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// SearchHit adalah field Mongo satu hasil pencarian yang dibutuhkan untuk
+// filter & facet; dokumen lengkap hanya diambil untuk halaman yang diminta
+type SearchHit struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	Category  string             `bson:"category"`
+	Level     string             `bson:"level"`
+	EventDate *time.Time         `bson:"event_date"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Session mewakili satu login (satu perangkat). ID session sama dengan
+// family_id refresh token, sehingga mencabut session juga memutus rotasi.
+type Session struct {
+	ID         string     `db:"id" json:"id"`
+	UserID     string     `db:"user_id" json:"user_id"`
+	Device     string     `db:"device" json:"device"`
+	IPAddress  string     `db:"ip_address" json:"ip_address"`
+	UserAgent  string     `db:"user_agent" json:"user_agent"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	LastSeenAt time.Time  `db:"last_seen_at" json:"last_seen_at"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	Current    bool       `db:"-" json:"current"`
+}
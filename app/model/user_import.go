@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// Status hasil per baris impor
+const (
+	ImportRowValid   = "valid"   // dry-run: lolos validasi
+	ImportRowCreated = "created" // sudah dibuat
+	ImportRowInvalid = "invalid" // gagal validasi, tidak diproses
+	ImportRowFailed  = "failed"  // lolos validasi tapi gagal saat insert
+)
+
+// UserImportRow adalah satu baris file impor (header tidak peka huruf
+// besar). StudyProgram / Department boleh kode atau nama unit; Advisor
+// adalah lecturer_id (NIP) dosen wali.
+type UserImportRow struct {
+	Row          int    `json:"row"`
+	Username     string `json:"username"`
+	Email        string `json:"email"`
+	FullName     string `json:"full_name"`
+	Role         string `json:"role"`
+	Password     string `json:"-"`
+	StudentID    string `json:"student_id,omitempty"`
+	StudyProgram string `json:"study_program,omitempty"`
+	AcademicYear string `json:"academic_year,omitempty"`
+	Advisor      string `json:"advisor,omitempty"`
+	LecturerID   string `json:"lecturer_id,omitempty"`
+	Department   string `json:"department,omitempty"`
+}
+
+type ImportRowResult struct {
+	Row      int      `json:"row"`
+	Username string   `json:"username"`
+	Status   string   `json:"status"`
+	UserID   string   `json:"user_id,omitempty"` // diisi bila created
+	Errors   []string `json:"errors,omitempty"`
+}
+
+type UserImport struct {
+	ID        string            `db:"id" json:"id"`
+	Filename  string            `db:"filename" json:"filename"`
+	DryRun    bool              `db:"dry_run" json:"dry_run"`
+	Total     int               `db:"total" json:"total"`
+	Created   int               `db:"created" json:"created"`
+	Invalid   int               `db:"invalid" json:"invalid"`
+	Failed    int               `db:"failed" json:"failed"`
+	CreatedBy *string           `db:"created_by" json:"created_by"`
+	CreatedAt time.Time         `db:"created_at" json:"created_at"`
+	Rows      []ImportRowResult `db:"-" json:"rows,omitempty"`
+}
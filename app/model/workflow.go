@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// WorkflowRule menentukan rantai approver untuk prestasi dengan kategori
+// dan/atau tingkat tertentu. Category / Level NULL berarti "semua".
+type WorkflowRule struct {
+	ID        string         `db:"id" json:"id"`
+	Name      string         `db:"name" json:"name"`
+	Category  *string        `db:"category" json:"category"`
+	Level     *string        `db:"level" json:"level"`
+	Stages    pq.StringArray `db:"stages" json:"stages"`
+	CreatedAt time.Time      `db:"created_at" json:"created_at"`
+}
+
+// StatusChange adalah satu transisi status yang sudah divalidasi workflow
+type StatusChange struct {
+	RefID      string
+	FromStatus string
+	ToStatus   string
+	FromStage  int
+	ToStage    int
+	Stages     []string // hanya diisi saat submit (snapshot rantai)
+	StageName  string
+	ChangedBy  string
+	Note       string
+	Comments   []RevisionComment // komentar per-field (request revision)
+	SagaID     string            // diselesaikan dalam transaksi yang sama (lihat package saga)
+	// disimpan di transaksi yang sama; ID notifikasi diisi setelah insert
+	Notifications []*PendingNotification
+}
@@ -0,0 +1,190 @@
+package policy
+
+import (
+	"fmt"
+
+	"project_uas/app/workflow"
+)
+
+type Engine struct {
+	Rules Table
+	Facts FactSource
+}
+
+func NewEngine(rules Table, facts FactSource) *Engine {
+	return &Engine{Rules: rules, Facts: facts}
+}
+
+// Declared melaporkan apakah action ada di tabel (dicek saat route didaftarkan)
+func (e *Engine) Declared(action string) bool {
+	_, ok := e.Rules[action]
+	return ok
+}
+
+// Public melaporkan apakah action boleh diakses tanpa login
+func (e *Engine) Public(action string) bool {
+	for _, g := range e.Rules[action] {
+		if g.Public {
+			return true
+		}
+	}
+	return false
+}
+
+// Check mengevaluasi action. Facts resource hanya dimuat bila ada grant
+// yang membutuhkan relasi. Action yang tidak terdaftar selalu ditolak.
+func (e *Engine) Check(s Subject, action string, res Resource) (Decision, error) {
+	grants, ok := e.Rules[action]
+	if !ok {
+		return Decision{Reason: "undeclared action " + action}, nil
+	}
+
+	ev := evaluation{engine: e, subject: s, resource: res}
+
+	for _, g := range grants {
+		allowed, err := ev.grant(g)
+		if err != nil {
+			return Decision{}, err
+		}
+		if allowed {
+			d := Decision{Allowed: true, Relation: g.Relation}
+			if g.Relation == RelUnitAdmin {
+				d.Scope = ev.units
+			}
+			return d, nil
+		}
+	}
+
+	return Decision{Reason: "not allowed to " + action}, nil
+}
+
+// Allowed adalah Check yang menganggap error sebagai tolak
+func (e *Engine) Allowed(s Subject, action string, res Resource) bool {
+	d, err := e.Check(s, action, res)
+	return err == nil && d.Allowed
+}
+
+// evaluation menyimpan facts yang sudah dimuat selama satu Check
+type evaluation struct {
+	engine   *Engine
+	subject  Subject
+	resource Resource
+
+	facts *Facts
+	units []string
+}
+
+func (ev *evaluation) grant(g Grant) (bool, error) {
+	if g.Public {
+		return true, nil
+	}
+	if !ev.subject.Authenticated() {
+		return false, nil
+	}
+	if len(g.Roles) > 0 && !contains(g.Roles, ev.subject.Role) {
+		return false, nil
+	}
+	if g.Permission != "" && !ev.subject.HasPermission(g.Permission) {
+		return false, nil
+	}
+	if g.Relation == "" {
+		return true, nil
+	}
+	if g.Relation == RelUnitAdmin {
+		return ev.unitAdmin()
+	}
+	if ev.resource.ID == "" {
+		return false, nil
+	}
+	return ev.relation(g.Relation)
+}
+
+func (ev *evaluation) loadFacts() error {
+	if ev.facts != nil {
+		return nil
+	}
+	f, err := ev.engine.Facts.Facts(ev.resource)
+	if err != nil {
+		return err
+	}
+	ev.facts = f
+	return nil
+}
+
+// unitAdmin: action koleksi cukup memegang satu unit; resource harus
+// berada di salah satu unit subject
+func (ev *evaluation) unitAdmin() (bool, error) {
+	if ev.units == nil {
+		units, err := ev.engine.Facts.SubjectUnits(ev.subject.UserID)
+		if err != nil {
+			return false, err
+		}
+		ev.units = append([]string{}, units...)
+	}
+	if len(ev.units) == 0 {
+		return false, nil
+	}
+	if ev.resource.ID == "" {
+		return true, nil
+	}
+
+	if err := ev.loadFacts(); err != nil {
+		return false, err
+	}
+	for _, u := range ev.facts.Units {
+		if contains(ev.units, u) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (ev *evaluation) relation(rel string) (bool, error) {
+	if err := ev.loadFacts(); err != nil {
+		return false, err
+	}
+	f, s := ev.facts, ev.subject
+
+	switch rel {
+	case RelOwner:
+		return f.OwnerUserID != "" && f.OwnerUserID == s.UserID, nil
+
+	case RelAdvisor:
+		return f.AdvisorUserID != "" && f.AdvisorUserID == s.UserID, nil
+
+	case RelDepartmentHead:
+		return f.DepartmentHeadUserID != "" && f.DepartmentHeadUserID == s.UserID, nil
+
+	case RelWorkflowApprover:
+		for _, stage := range f.Stages {
+			if stage != workflow.StageAdvisor && stage == s.Role {
+				return true, nil
+			}
+		}
+		return false, nil
+
+	case RelCurrentApprover:
+		stage := workflow.CurrentStage(f.Stages, f.CurrentStage)
+		switch stage {
+		case workflow.StageAdvisor:
+			return f.AdvisorUserID != "" && f.AdvisorUserID == s.UserID, nil
+		case workflow.StageHeadOfDepartment:
+			// departemen yang sudah punya kepala hanya diputuskan kepalanya
+			if f.DepartmentHeadUserID != "" {
+				return f.DepartmentHeadUserID == s.UserID, nil
+			}
+		}
+		return stage == s.Role, nil
+	}
+
+	return false, fmt.Errorf("policy: unknown relation %q", rel)
+}
+
+func contains(list []string, v string) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
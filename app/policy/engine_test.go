@@ -0,0 +1,290 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+)
+
+// fakeFacts adalah FactSource di memori: resource dicari per ID, unit
+// subject per user ID
+type fakeFacts struct {
+	resources map[string]*Facts
+	units     map[string][]string
+	loads     int
+}
+
+func (f *fakeFacts) Facts(res Resource) (*Facts, error) {
+	f.loads++
+	facts, ok := f.resources[res.ID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return facts, nil
+}
+
+func (f *fakeFacts) SubjectUnits(userID string) ([]string, error) {
+	return f.units[userID], nil
+}
+
+func newFakeFacts() *fakeFacts {
+	return &fakeFacts{
+		resources: map[string]*Facts{
+			// prestasi mahasiswa u-student di prodi IF (departemen TI,
+			// fakultas FT), sedang di stage kepala departemen
+			"ach-1": {
+				OwnerUserID:          "u-student",
+				AdvisorUserID:        "u-advisor",
+				DepartmentHeadUserID: "u-head",
+				Units:                []string{"prog-if", "dept-ti", "fac-ft"},
+				Status:               "submitted",
+				Stages:               []string{"advisor", "head_of_department", "student_affairs"},
+				CurrentStage:         1,
+			},
+			// sama, tetapi departemennya belum punya kepala
+			"ach-2": {
+				OwnerUserID:   "u-student",
+				AdvisorUserID: "u-advisor",
+				Units:         []string{"prog-el", "dept-te", "fac-ft"},
+				Status:        "submitted",
+				Stages:        []string{"advisor", "head_of_department"},
+				CurrentStage:  1,
+			},
+			// masih di stage advisor
+			"ach-3": {
+				OwnerUserID:   "u-student",
+				AdvisorUserID: "u-advisor",
+				Units:         []string{"prog-if", "dept-ti", "fac-ft"},
+				Status:        "submitted",
+				Stages:        []string{"advisor", "student_affairs"},
+				CurrentStage:  0,
+			},
+			// stage terakhir student_affairs
+			"ach-4": {
+				OwnerUserID:   "u-student",
+				AdvisorUserID: "u-advisor",
+				Units:         []string{"prog-if", "dept-ti", "fac-ft"},
+				Status:        "submitted",
+				Stages:        []string{"advisor", "student_affairs"},
+				CurrentStage:  1,
+			},
+		},
+		units: map[string][]string{
+			"u-fac-admin":   {"fac-ft"},
+			"u-other-admin": {"fac-fe"},
+		},
+	}
+}
+
+// tabel kecil supaya setiap relasi diuji terpisah dari Rules produksi
+var testRules = Table{
+	"open":     {public},
+	"login":    {authenticated},
+	"perm":     {permission("things:read")},
+	"role":     {{Roles: []string{"lecturer"}}},
+	"owner":    {relation(RelOwner)},
+	"owned":    {owned("things:update")},
+	"advisor":  {relation(RelAdvisor)},
+	"head":     {relation(RelDepartmentHead)},
+	"unit":     {relation(RelUnitAdmin)},
+	"workflow": {relation(RelWorkflowApprover)},
+	"decide":   {relation(RelCurrentApprover)},
+	"either":   {permission("things:read"), relation(RelUnitAdmin)},
+}
+
+func subject(userID string, role string, perms ...string) Subject {
+	return Subject{UserID: userID, Role: role, Permissions: perms}
+}
+
+var anonymous = Subject{}
+
+func TestEngineCheck(t *testing.T) {
+	ach := func(id string) Resource { return Resource{Kind: KindAchievement, ID: id} }
+	collection := Resource{Kind: KindAchievement}
+
+	tests := []struct {
+		name    string
+		subject Subject
+		action  string
+		res     Resource
+		allowed bool
+	}{
+		// public vs authenticated
+		{"public anonymous", anonymous, "open", collection, true},
+		{"public logged in", subject("u-student", "student"), "open", collection, true},
+		{"authenticated anonymous", anonymous, "login", collection, false},
+		{"authenticated logged in", subject("u-student", "student"), "login", collection, true},
+		{"permission anonymous", Subject{Permissions: []string{"things:read"}}, "perm", collection, false},
+
+		// permission & role
+		{"permission held", subject("u-x", "custom", "things:read"), "perm", collection, true},
+		{"permission missing", subject("u-x", "admin", "things:write"), "perm", collection, false},
+		{"role match", subject("u-x", "lecturer"), "role", collection, true},
+		{"role mismatch", subject("u-x", "student"), "role", collection, false},
+
+		// undeclared action
+		{"undeclared", subject("u-x", "admin", "things:read"), "nope", collection, false},
+
+		// owner
+		{"owner", subject("u-student", "student"), "owner", ach("ach-1"), true},
+		{"not owner", subject("u-other", "student"), "owner", ach("ach-1"), false},
+		{"owner needs resource", subject("u-student", "student"), "owner", collection, false},
+		{"owned with permission", subject("u-student", "student", "things:update"), "owned", ach("ach-1"), true},
+		{"owned without permission", subject("u-student", "student"), "owned", ach("ach-1"), false},
+		{"permission without ownership", subject("u-other", "student", "things:update"), "owned", ach("ach-1"), false},
+
+		// advisor & department head
+		{"advisor", subject("u-advisor", "lecturer"), "advisor", ach("ach-1"), true},
+		{"not advisor", subject("u-head", "lecturer"), "advisor", ach("ach-1"), false},
+		{"department head", subject("u-head", "lecturer"), "head", ach("ach-1"), true},
+		{"not department head", subject("u-advisor", "lecturer"), "head", ach("ach-1"), false},
+		{"department without head", subject("u-head", "lecturer"), "head", ach("ach-2"), false},
+
+		// unit admin: koleksi cukup memegang unit, resource harus di unitnya
+		{"unit admin collection", subject("u-fac-admin", "staff"), "unit", collection, true},
+		{"unit admin resource inside", subject("u-fac-admin", "staff"), "unit", ach("ach-1"), true},
+		{"unit admin resource outside", subject("u-other-admin", "staff"), "unit", ach("ach-1"), false},
+		{"no units collection", subject("u-student", "student"), "unit", collection, false},
+
+		// workflow approver: role ada di stage non-advisor
+		{"workflow approver", subject("u-x", "student_affairs"), "workflow", ach("ach-1"), true},
+		{"workflow advisor stage is not a role", subject("u-x", "advisor"), "workflow", ach("ach-1"), false},
+		{"workflow role not in chain", subject("u-x", "student_affairs"), "workflow", ach("ach-2"), false},
+
+		// current approver
+		{"current advisor stage", subject("u-advisor", "lecturer"), "decide", ach("ach-3"), true},
+		{"advisor after advisor stage", subject("u-advisor", "lecturer"), "decide", ach("ach-1"), false},
+		{"current role stage", subject("u-x", "student_affairs"), "decide", ach("ach-4"), true},
+		{"role not current stage", subject("u-x", "student_affairs"), "decide", ach("ach-1"), false},
+		// departemen yang punya kepala hanya diputuskan kepalanya
+		{"head of department override", subject("u-head", "lecturer"), "decide", ach("ach-1"), true},
+		{"head role without being head", subject("u-x", "head_of_department"), "decide", ach("ach-1"), false},
+		{"head role when department has no head", subject("u-x", "head_of_department"), "decide", ach("ach-2"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEngine(testRules, newFakeFacts())
+			d, err := e.Check(tt.subject, tt.action, tt.res)
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if d.Allowed != tt.allowed {
+				t.Fatalf("allowed = %v, want %v (reason %q)", d.Allowed, tt.allowed, d.Reason)
+			}
+		})
+	}
+}
+
+func TestEngineCheckScope(t *testing.T) {
+	e := NewEngine(testRules, newFakeFacts())
+
+	// admin unit: Decision membawa scope untuk handler
+	d, err := e.Check(subject("u-fac-admin", "staff"), "either", Resource{Kind: KindStudent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Allowed || d.Relation != RelUnitAdmin || len(d.Scope) != 1 || d.Scope[0] != "fac-ft" {
+		t.Fatalf("unit admin decision = %+v", d)
+	}
+
+	// permission lebih dulu: tidak dibatasi unit walau juga admin unit
+	d, err = e.Check(subject("u-fac-admin", "staff", "things:read"), "either", Resource{Kind: KindStudent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.Allowed || d.Scope != nil {
+		t.Fatalf("permission decision = %+v", d)
+	}
+}
+
+func TestEngineCheckFacts(t *testing.T) {
+	facts := newFakeFacts()
+	e := NewEngine(testRules, facts)
+
+	// grant tanpa relasi tidak memuat facts
+	if _, err := e.Check(subject("u-x", "custom", "things:read"), "perm", Resource{Kind: KindAchievement, ID: "ach-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if facts.loads != 0 {
+		t.Fatalf("facts loaded %d times for permission grant", facts.loads)
+	}
+
+	// resource yang tidak ada diteruskan sebagai ErrNotFound
+	_, err := e.Check(subject("u-student", "student"), "owner", Resource{Kind: KindAchievement, ID: "missing"})
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+	if e.Allowed(subject("u-student", "student"), "owner", Resource{Kind: KindAchievement, ID: "missing"}) {
+		t.Fatal("Allowed must deny on error")
+	}
+}
+
+func TestEnginePublicDeclared(t *testing.T) {
+	e := NewEngine(testRules, newFakeFacts())
+	if !e.Public("open") || e.Public("login") || e.Public("nope") {
+		t.Fatal("Public mismatch")
+	}
+	if !e.Declared("login") || e.Declared("nope") {
+		t.Fatal("Declared mismatch")
+	}
+}
+
+// Rules produksi: kemampuan mengikuti permission, bukan nama role
+func TestRulesPermissionBased(t *testing.T) {
+	e := NewEngine(Rules, newFakeFacts())
+	ach := Resource{Kind: KindAchievement, ID: "ach-1"}
+
+	tests := []struct {
+		name    string
+		subject Subject
+		action  string
+		res     Resource
+		allowed bool
+	}{
+		{"custom role creates achievement", subject("u-x", "alumni", "achievements:create"), "achievement:create", Resource{}, true},
+		{"student role without permission", subject("u-x", "student"), "achievement:create", Resource{}, false},
+		{"custom role lists students", subject("u-x", "student_affairs", "students:read"), "student:list", Resource{}, true},
+		{"custom role reads all achievements", subject("u-x", "auditor", "achievements:read-all"), "achievement:read", ach, true},
+		{"admin role without permission", subject("u-x", "admin"), "achievement:read", ach, false},
+		{"owner updates with permission", subject("u-student", "student", "achievements:update"), "achievement:update", ach, true},
+		{"owner without permission", subject("u-student", "student"), "achievement:update", ach, false},
+		{"verify only at own stage", subject("u-head", "lecturer", "achievements:verify"), "achievement:decide", ach, true},
+		{"verify at other stage", subject("u-advisor", "lecturer", "achievements:verify"), "achievement:decide", ach, false},
+		{"current approver without verify", subject("u-head", "lecturer"), "achievement:decide", ach, false},
+		{"manage decides any stage", subject("u-x", "ops", "achievements:manage"), "achievement:decide", ach, true},
+		{"search needs a scoped capability", subject("u-x", "guest"), "achievement:search", Resource{}, false},
+		{"unit admin searches", subject("u-fac-admin", "staff"), "achievement:search", Resource{}, true},
+		{"student searches", subject("u-student", "student", "achievements:create"), "achievement:search", Resource{}, true},
+		{"stats", subject("u-x", "dean", "achievements:stats"), "report:statistics", Resource{}, true},
+		{"login is public", anonymous, "auth:login", Resource{}, true},
+		{"profile needs login", anonymous, "auth:profile", Resource{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := e.Check(tt.subject, tt.action, tt.res)
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if d.Allowed != tt.allowed {
+				t.Fatalf("allowed = %v, want %v (reason %q)", d.Allowed, tt.allowed, d.Reason)
+			}
+		})
+	}
+}
+
+// permission yang dirujuk Rules dilindungi dari penghapusan lewat API RBAC
+func TestTablePermissions(t *testing.T) {
+	got := testRules.Permissions()
+	if len(got) != 2 || got[0] != "things:read" || got[1] != "things:update" {
+		t.Fatalf("testRules.Permissions() = %v", got)
+	}
+
+	found := false
+	for _, p := range Rules.Permissions() {
+		found = found || p == "roles:manage"
+	}
+	if !found {
+		t.Fatal("Rules.Permissions() misses roles:manage")
+	}
+}
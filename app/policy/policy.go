@@ -0,0 +1,148 @@
+// Package policy adalah lapisan otorisasi terpusat: setiap route
+// mendeklarasikan action, dan Engine memutuskan (subject, action, resource)
+// berdasarkan tabel Rules. Grant bisa mensyaratkan role, permission dan/atau
+// relasi subject terhadap resource (owner, advisor, department_head, ...).
+package policy
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Default dipakai middleware.Authorize dan service
+var Default *Engine
+
+// ErrNotFound: resource yang dirujuk path tidak ada
+var ErrNotFound = errors.New("resource not found")
+
+// Jenis resource
+const (
+	KindAchievement = "achievement"
+	KindStudent     = "student"
+	KindLecturer    = "lecturer"
+	KindUser        = "user"
+	KindDepartment  = "department"
+	KindProgram     = "study_program"
+)
+
+// Relasi subject terhadap resource
+const (
+	// subject adalah pemilik (mahasiswa pemilik prestasi / user itu sendiri)
+	RelOwner = "owner"
+	// subject adalah dosen wali mahasiswa pemilik resource
+	RelAdvisor = "advisor"
+	// subject adalah kepala departemen (departments.head_user_id) resource
+	RelDepartmentHead = "department_head"
+	// subject admin unit (fakultas / departemen / prodi) tempat resource
+	// berada; untuk action koleksi cukup memegang minimal satu unit dan
+	// handler membatasi hasil ke Decision.Scope
+	RelUnitAdmin = "unit_admin"
+	// role subject ada di rantai workflow prestasi (stage non-advisor)
+	RelWorkflowApprover = "workflow_approver"
+	// subject adalah approver stage yang sedang berjalan (status prestasi
+	// tetap dicek handler lewat aturan workflow)
+	RelCurrentApprover = "current_approver"
+)
+
+// Grant terpenuhi bila semua syarat yang diisi cocok. Grant kosong =
+// semua user yang login; Public = juga tanpa login.
+type Grant struct {
+	Public     bool
+	Roles      []string
+	Permission string
+	Relation   string
+}
+
+// Table: action → grant (cukup salah satu)
+type Table map[string][]Grant
+
+// Permissions mengembalikan semua permission yang dirujuk tabel; permission
+// ini tidak boleh dihapus lewat API RBAC
+func (t Table) Permissions() []string {
+	seen := map[string]bool{}
+	list := []string{}
+	for _, grants := range t {
+		for _, g := range grants {
+			if g.Permission != "" && !seen[g.Permission] {
+				seen[g.Permission] = true
+				list = append(list, g.Permission)
+			}
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Subject adalah user yang sedang request (dari AuthMiddleware)
+type Subject struct {
+	UserID      string
+	Role        string
+	Permissions []string
+}
+
+func (s Subject) Authenticated() bool {
+	return s.UserID != ""
+}
+
+func (s Subject) HasPermission(p string) bool {
+	for _, x := range s.Permissions {
+		if x == p {
+			return true
+		}
+	}
+	return false
+}
+
+// SubjectFrom membaca subject dari locals yang diisi AuthMiddleware
+func SubjectFrom(c *fiber.Ctx) Subject {
+	s := Subject{}
+	s.UserID, _ = c.Locals("user_id").(string)
+	s.Role, _ = c.Locals("role").(string)
+	s.Permissions, _ = c.Locals("permissions").([]string)
+	return s
+}
+
+// Resource yang diakses; ID kosong untuk action level koleksi
+type Resource struct {
+	Kind string
+	ID   string
+}
+
+// Facts adalah atribut resource yang dibutuhkan untuk menghitung relasi
+type Facts struct {
+	OwnerUserID   string
+	AdvisorUserID string
+	// kepala departemen tempat resource berada ("" bila belum ditetapkan)
+	DepartmentHeadUserID string
+	// ID program studi, departemen dan fakultas resource
+	Units        []string
+	Status       string
+	Stages       []string
+	CurrentStage int
+}
+
+// FactSource memuat Facts dari database; diganti fake saat pengujian
+type FactSource interface {
+	Facts(res Resource) (*Facts, error)
+	// SubjectUnits: unit yang didelegasikan ke user (unit_admins)
+	SubjectUnits(userID string) ([]string, error)
+}
+
+// Decision menjelaskan hasil evaluasi untuk respons 403 / log. Relation
+// adalah relasi grant yang meloloskan; bila RelUnitAdmin, Scope berisi
+// unit yang boleh dilihat subject.
+type Decision struct {
+	Allowed  bool
+	Reason   string
+	Relation string
+	Scope    []string
+}
+
+// ScopeFrom membaca scope unit yang disimpan middleware.Authorize
+// (nil = tidak dibatasi unit)
+func ScopeFrom(c *fiber.Ctx) []string {
+	scope, _ := c.Locals("unit_scope").([]string)
+	return scope
+}
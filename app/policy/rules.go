@@ -0,0 +1,134 @@
+package policy
+
+// Grant yang sering dipakai
+var (
+	public        = Grant{Public: true}
+	authenticated = Grant{}
+)
+
+func permission(p string) Grant { return Grant{Permission: p} }
+func relation(rel string) Grant { return Grant{Relation: rel} }
+
+// owned: subject harus pemilik resource dan memegang permission p
+func owned(p string) Grant { return Grant{Permission: p, Relation: RelOwner} }
+
+// yang boleh melihat prestasi & buktinya: pemegang achievements:read-all,
+// pemilik, dosen wali, kepala departemen mahasiswa, approver di rantai
+// workflow dan admin unit
+var readAchievement = []Grant{
+	permission("achievements:read-all"),
+	relation(RelOwner),
+	relation(RelAdvisor),
+	relation(RelDepartmentHead),
+	relation(RelWorkflowApprover),
+	relation(RelUnitAdmin),
+}
+
+// data mahasiswa (profil, prestasi, laporan): pemegang
+// achievements:read-all, mahasiswa itu sendiri, dosen wali, kepala
+// departemen dan admin unit
+var readStudent = []Grant{
+	permission("achievements:read-all"),
+	relation(RelOwner),
+	relation(RelAdvisor),
+	relation(RelDepartmentHead),
+	relation(RelUnitAdmin),
+}
+
+// Rules adalah tabel policy seluruh route. Setiap action yang dipakai
+// middleware.Authorize wajib terdaftar di sini; action yang tidak
+// terdaftar selalu ditolak. Kemampuan diberikan lewat permission (bisa
+// di-assign ke role apa pun lewat API role), relasi hanya dipakai untuk
+// kepemilikan / keterkaitan dengan resource.
+var Rules = Table{
+	// AUTH & SESSION
+	"auth:login":     {public},
+	"auth:refresh":   {public},
+	"auth:logout":    {authenticated},
+	"auth:profile":   {authenticated},
+	"session:own":    {authenticated},
+	"session:read":   {permission("users:read")},
+	"session:revoke": {permission("users:update")},
+
+	// USERS
+	"user:list":        {permission("users:read")},
+	"user:read":        {permission("users:read")},
+	"user:create":      {permission("users:create")},
+	"user:update":      {permission("users:update")},
+	"user:delete":      {permission("users:delete")},
+	"user:update-role": {permission("users:update-role")},
+	"user:import":      {permission("users:create")},
+
+	// AUDIT
+	"audit:read": {permission("audit:read")},
+
+	// ROLES & PERMISSIONS
+	"role:read":   {permission("roles:read")},
+	"role:manage": {permission("roles:manage")},
+
+	// ACHIEVEMENTS
+	"achievement:list":   {permission("achievements:read-all"), relation(RelUnitAdmin)},
+	"achievement:create": {permission("achievements:create")},
+	// hasil dibatasi handler sesuai grant yang meloloskan
+	"achievement:search": {
+		permission("achievements:read-all"),
+		relation(RelUnitAdmin),
+		permission("students:advisees"),
+		permission("achievements:create"),
+	},
+	"achievement:pending": {authenticated},
+	"achievement:read":    readAchievement,
+	"achievement:history": readAchievement,
+	"achievement:update":  {owned("achievements:update")},
+	"achievement:delete":  {owned("achievements:delete")},
+	"achievement:submit":  {owned("achievements:submit")},
+	// verify / reject / request-revision pada stage yang sedang berjalan;
+	// achievements:manage boleh memutuskan stage mana pun
+	"achievement:decide": {
+		permission("achievements:manage"),
+		{Permission: "achievements:verify", Relation: RelCurrentApprover},
+	},
+
+	// ATTACHMENTS (signed URL memverifikasi tanda tangan di handler)
+	"attachment:read":     readAchievement,
+	"attachment:write":    {permission("achievements:manage"), owned("achievements:update")},
+	"attachment:download": {public},
+
+	// WORKFLOWS & ADMIN
+	"workflow:manage": {permission("workflows:manage")},
+	"integrity:check": {permission("achievements:manage")},
+
+	// NOTIFICATIONS (selalu milik user yang login)
+	"notification:own": {authenticated},
+
+	// STUDENTS
+	"student:list":            {permission("students:read"), relation(RelUnitAdmin)},
+	"student:profile":         {permission("students:read-self")},
+	"student:read":            readStudent,
+	"student:achievements":    readStudent,
+	"student:assign-advisor":  {permission("students:assign-advisor"), relation(RelUnitAdmin)},
+	"student:advisor-history": readStudent,
+	"student:link-program":    {permission("org:manage"), relation(RelUnitAdmin)},
+	// bulk & auto assign: mahasiswa / departemen dicek di handler
+	"student:bulk-assign-advisor": {permission("students:assign-advisor"), relation(RelUnitAdmin)},
+
+	// LECTURERS
+	"lecturer:list":            {authenticated},
+	"lecturer:profile":         {permission("lecturers:read-self")},
+	"lecturer:advisees":        {permission("achievements:read-all"), owned("students:advisees")},
+	"lecturer:link-department": {permission("org:manage"), relation(RelUnitAdmin)},
+	// departemen dicek di handler
+	"lecturer:advisor-load": {permission("students:assign-advisor"), relation(RelUnitAdmin)},
+
+	// ORGANISASI (fakultas → departemen → program studi)
+	"org:read":          {authenticated},
+	"org:manage":        {permission("org:manage")},
+	"unit-admin:manage": {permission("org:manage")},
+	// menautkan mahasiswa / dosen ke unit tujuan (dicek di handler)
+	"unit:assign": {permission("org:manage"), relation(RelUnitAdmin)},
+
+	// REPORTS
+	"report:student":    readStudent,
+	"report:statistics": {permission("achievements:stats"), relation(RelUnitAdmin)},
+	"report:units":      {permission("achievements:stats"), relation(RelUnitAdmin)},
+}
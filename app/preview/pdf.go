@@ -0,0 +1,95 @@
+package preview
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"regexp"
+	"strconv"
+)
+
+// Merender PDF secara utuh butuh interpreter PostScript-like + font engine.
+// Sebagai gantinya kita memanfaatkan fakta bahwa sertifikat hasil scan
+// hampir selalu berupa satu gambar JPEG (filter DCTDecode) per halaman:
+// image XObject pertama di file diambil sebagai pratinjau halaman depan.
+// Bila tidak ada, dibuat placeholder berisi jumlah halaman.
+
+var (
+	streamRe    = regexp.MustCompile(`>>\s*stream\r?\n`)
+	lengthRe    = regexp.MustCompile(`/Length\s+(\d+)(\s+\d+\s+R)?`)
+	pageCountRe = regexp.MustCompile(`/Type\s*/Page[^s]`)
+	imageRe     = regexp.MustCompile(`/Subtype\s*/Image`)
+	// hanya DCTDecode tunggal; kombinasi seperti [/FlateDecode /DCTDecode]
+	// butuh dekompresi dulu dan dilewati
+	plainDCTRe = regexp.MustCompile(`/Filter\s*(/DCTDecode|\[\s*/DCTDecode\s*\])`)
+)
+
+func pdfPreview(data []byte) (image.Image, error) {
+	img, err := firstDCTImage(data)
+	if err != nil {
+		return nil, err
+	}
+	if img != nil {
+		return img, nil
+	}
+
+	pages := len(pageCountRe.FindAllIndex(data, -1))
+	label := ""
+	if pages > 0 {
+		label = fmt.Sprintf("%d page(s)", pages)
+	}
+	return placeholder(label), nil
+}
+
+// firstDCTImage mencari stream /Subtype /Image dengan /Filter /DCTDecode
+// (tanpa filter tambahan) lalu mendekodenya sebagai JPEG. Gambar yang
+// melebihi MaxPixels menggagalkan pratinjau (ErrTooLarge).
+func firstDCTImage(data []byte) (image.Image, error) {
+	for _, m := range streamRe.FindAllIndex(data, -1) {
+		// dictionary stream = dari "obj" terdekat sebelum kata "stream"
+		objStart := bytes.LastIndex(data[:m[0]], []byte("obj"))
+		if objStart < 0 {
+			continue
+		}
+		dict := data[objStart:m[0]]
+		if !imageRe.Match(dict) || !plainDCTRe.Match(dict) {
+			continue
+		}
+
+		start := m[1]
+		end := streamEnd(data, dict, start)
+		if end <= start {
+			continue
+		}
+
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(data[start:end]))
+		if err != nil {
+			continue
+		}
+		if !withinLimit(cfg) {
+			return nil, ErrTooLarge
+		}
+		img, err := jpeg.Decode(bytes.NewReader(data[start:end]))
+		if err == nil {
+			return img, nil
+		}
+	}
+	return nil, nil
+}
+
+// streamEnd memakai /Length langsung bila ada; bila /Length berupa
+// referensi objek, cari "endstream".
+func streamEnd(data []byte, dict []byte, start int) int {
+	if m := lengthRe.FindSubmatch(dict); m != nil && len(m[2]) == 0 {
+		if n, err := strconv.Atoi(string(m[1])); err == nil && start+n <= len(data) {
+			return start + n
+		}
+	}
+
+	idx := bytes.Index(data[start:], []byte("endstream"))
+	if idx < 0 {
+		return -1
+	}
+	return start + idx
+}
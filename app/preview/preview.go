@@ -0,0 +1,140 @@
+// Package preview membuat thumbnail lampiran (PNG/JPEG) dan pratinjau
+// halaman depan PDF tanpa dependensi native (pure Go).
+package preview
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	// MaxSide adalah sisi terpanjang thumbnail (px)
+	MaxSide = 480
+	// ContentType hasil pratinjau
+	ContentType = "image/jpeg"
+
+	maxInput = 50 << 20
+	// MaxPixels membatasi ukuran gambar yang didekode (lebar × tinggi).
+	// Header PNG/JPEG bisa mengklaim dimensi raksasa dalam file beberapa
+	// KB; tanpa batas ini decoder mengalokasikan bergiga-giga byte.
+	MaxPixels = 40_000_000
+)
+
+var ErrUnsupported = errors.New("preview not supported for this content type")
+
+// ErrTooLarge: dimensi gambar melebihi MaxPixels (tidak dicoba ulang)
+var ErrTooLarge = fmt.Errorf("%w: image exceeds %d pixels", ErrUnsupported, MaxPixels)
+
+// Generate membaca file lampiran dan mengembalikan thumbnail JPEG
+func Generate(r io.Reader, contentType string) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxInput))
+	if err != nil {
+		return nil, err
+	}
+
+	var img image.Image
+	switch contentType {
+	case "image/png", "image/jpeg":
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		if !withinLimit(cfg) {
+			return nil, ErrTooLarge
+		}
+		img, _, err = image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+	case "application/pdf":
+		img, err = pdfPreview(data)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnsupported
+	}
+
+	return encode(thumbnail(img, MaxSide))
+}
+
+// withinLimit mengecek dimensi dari header sebelum gambar didekode
+func withinLimit(cfg image.Config) bool {
+	return cfg.Width > 0 && cfg.Height > 0 &&
+		int64(cfg.Width)*int64(cfg.Height) <= MaxPixels
+}
+
+// thumbnail memperkecil gambar dengan menjaga rasio (tidak memperbesar)
+func thumbnail(src image.Image, maxSide int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSide && h <= maxSide {
+		return src
+	}
+
+	if w >= h {
+		h = h * maxSide / w
+		w = maxSide
+	} else {
+		w = w * maxSide / h
+		h = maxSide
+	}
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+	return dst
+}
+
+func encode(img image.Image) ([]byte, error) {
+	// JPEG tidak punya alpha → ratakan di atas latar putih
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// placeholder dipakai bila halaman PDF tidak bisa dirender (mis. sertifikat
+// berbasis teks/vektor): kartu A4 sederhana berlabel "PDF".
+func placeholder(label string) image.Image {
+	w, h := 340, MaxSide
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{245, 245, 245, 255}), image.Point{}, draw.Src)
+
+	// pita merah di atas seperti ikon dokumen
+	draw.Draw(img, image.Rect(0, 0, w, 56), image.NewUniform(color.RGBA{200, 40, 40, 255}), image.Point{}, draw.Src)
+
+	face := basicfont.Face7x13
+	d := &font.Drawer{Dst: img, Src: image.NewUniform(color.White), Face: face}
+	d.Dot = fixed.P((w-d.MeasureString("PDF").Round())/2, 34)
+	d.DrawString("PDF")
+
+	if label != "" {
+		d.Src = image.NewUniform(color.RGBA{90, 90, 90, 255})
+		d.Dot = fixed.P((w-d.MeasureString(label).Round())/2, h/2)
+		d.DrawString(label)
+	}
+
+	return img
+}
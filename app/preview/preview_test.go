@@ -0,0 +1,107 @@
+package preview
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func smallImage() image.Image {
+	return image.NewRGBA(image.Rect(0, 0, 4, 3))
+}
+
+// hugePNG: PNG valid 4×3 yang header IHDR-nya diubah menjadi w×h
+func hugePNG(t *testing.T, w, h uint32) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, smallImage()); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	// signature (8) + length (4) + "IHDR" (4) → width, height
+	binary.BigEndian.PutUint32(data[16:], w)
+	binary.BigEndian.PutUint32(data[20:], h)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+// hugeJPEG: JPEG 4×3 yang dimensi SOF0-nya diubah menjadi w×h
+func hugeJPEG(t *testing.T, w, h uint16) []byte {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, smallImage(), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	sof := bytes.Index(data, []byte{0xFF, 0xC0})
+	if sof < 0 {
+		t.Fatal("no SOF0 marker")
+	}
+	// marker (2) + length (2) + precision (1) → height, width
+	binary.BigEndian.PutUint16(data[sof+5:], h)
+	binary.BigEndian.PutUint16(data[sof+7:], w)
+	return data
+}
+
+func pdfWithImage(img []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n1 0 obj\n<< /Type /Page >>\nendobj\n")
+	buf.WriteString("2 0 obj\n<< /Type /XObject /Subtype /Image /Filter /DCTDecode >>\nstream\n")
+	buf.Write(img)
+	buf.WriteString("\nendstream\nendobj\n%%EOF\n")
+	return buf.Bytes()
+}
+
+func TestGenerateRejectsHugeDimensions(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+	}{
+		{"png", hugePNG(t, 50000, 50000), "image/png"},
+		{"jpeg", hugeJPEG(t, 60000, 60000), "image/jpeg"},
+		{"pdf", pdfWithImage(hugeJPEG(t, 60000, 60000)), "application/pdf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Generate(bytes.NewReader(tt.data), tt.contentType)
+			if !errors.Is(err, ErrTooLarge) || !errors.Is(err, ErrUnsupported) {
+				t.Fatalf("err = %v, want ErrTooLarge", err)
+			}
+		})
+	}
+}
+
+func TestGenerateSmallImages(t *testing.T) {
+	var jpg bytes.Buffer
+	if err := jpeg.Encode(&jpg, smallImage(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+	}{
+		{"png", hugePNG(t, 4, 3), "image/png"},
+		{"jpeg", jpg.Bytes(), "image/jpeg"},
+		{"pdf image", pdfWithImage(jpg.Bytes()), "application/pdf"},
+		{"pdf placeholder", []byte("%PDF-1.4\n1 0 obj\n<< /Type /Page >>\nendobj\n"), "application/pdf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Generate(bytes.NewReader(tt.data), tt.contentType)
+			if err != nil {
+				t.Fatalf("Generate: %v", err)
+			}
+			if _, err := jpeg.DecodeConfig(bytes.NewReader(out)); err != nil {
+				t.Fatalf("output is not a JPEG: %v", err)
+			}
+		})
+	}
+}
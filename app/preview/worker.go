@@ -0,0 +1,115 @@
+package preview
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"project_uas/app/model"
+	"project_uas/app/repository"
+	"project_uas/app/storage"
+)
+
+const (
+	maxAttempts = 5
+	baseBackoff = 30 * time.Second
+	jobTimeout  = 2 * time.Minute
+)
+
+// Worker membuat pratinjau untuk lampiran yang sudah lolos scan
+type Worker struct {
+	Repo    *repository.AttachmentPreviewRepo
+	Storage storage.Storage
+}
+
+func NewWorker(repo *repository.AttachmentPreviewRepo, store storage.Storage) *Worker {
+	return &Worker{Repo: repo, Storage: store}
+}
+
+// Key pratinjau diletakkan di samping blob aslinya, jadi ikut
+// ter-deduplikasi bersama file yang sama.
+func Key(storageKey string) string {
+	return storageKey + ".preview.jpg"
+}
+
+// Run memproses antrian pratinjau secara berkala sampai ctx dibatalkan
+func (w *Worker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		w.processBatch(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) processBatch(ctx context.Context) {
+	list, err := w.Repo.Claim(10, jobTimeout+time.Minute)
+	if err != nil {
+		log.Println("preview: claim attachments:", err)
+		return
+	}
+
+	for _, att := range list {
+		key, err := w.process(ctx, att)
+		if err == nil {
+			if err := w.Repo.MarkReady(att.ID, key); err != nil {
+				log.Println("preview: mark ready:", err)
+			}
+			continue
+		}
+
+		final := ""
+		switch {
+		case errors.Is(err, ErrUnsupported):
+			final = model.PreviewUnsupported
+		case att.PreviewAttempts >= maxAttempts:
+			final = model.PreviewFailed
+		}
+
+		log.Printf("preview: attachment %s failed (attempt %d): %v", att.ID, att.PreviewAttempts, err)
+		next := time.Now().Add(baseBackoff * time.Duration(att.PreviewAttempts))
+		if err := w.Repo.MarkRetry(att.ID, err.Error(), next, final); err != nil {
+			log.Println("preview: mark retry:", err)
+		}
+	}
+}
+
+func (w *Worker) process(ctx context.Context, att model.AchievementAttachment) (string, error) {
+	jobCtx, cancel := context.WithTimeout(ctx, jobTimeout)
+	defer cancel()
+
+	key := Key(att.StorageKey)
+
+	// blob yang sama mungkin sudah punya pratinjau dari lampiran lain
+	exists, err := w.Storage.Exists(jobCtx, key)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return key, nil
+	}
+
+	body, err := w.Storage.Get(jobCtx, att.StorageKey)
+	if err != nil {
+		return "", err
+	}
+	thumb, err := Generate(body, att.ContentType)
+	body.Close()
+	if err != nil {
+		return "", err
+	}
+
+	if err := w.Storage.Put(jobCtx, key, bytes.NewReader(thumb), int64(len(thumb)), ContentType); err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
@@ -0,0 +1,146 @@
+// Package rbac menyimpan cache role user dan permission per role yang
+// dibaca dari database setiap request, bukan dari klaim JWT. Perubahan
+// lewat endpoint RBAC dikirim dengan pg_notify sehingga semua instance
+// membuang cache-nya dan perubahan berlaku tanpa login ulang.
+package rbac
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+
+	"project_uas/app/repository"
+)
+
+// Default dipakai middleware; nil berarti fallback ke klaim token
+var Default *Cache
+
+type entry struct {
+	value   interface{}
+	expires time.Time
+}
+
+type Cache struct {
+	Repo *repository.RBACRepo
+	// batas umur entry, jaga-jaga bila notifikasi terlewat
+	TTL time.Duration
+
+	mu    sync.RWMutex
+	roles map[string]entry // nama role → []string
+	users map[string]entry // user id → nama role
+}
+
+func NewCache(repo *repository.RBACRepo, ttl time.Duration) *Cache {
+	return &Cache{
+		Repo:  repo,
+		TTL:   ttl,
+		roles: map[string]entry{},
+		users: map[string]entry{},
+	}
+}
+
+func (c *Cache) get(m map[string]entry, key string) (interface{}, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	e, ok := m[key]
+	if !ok || time.Now().After(e.expires) {
+		return nil, false
+	}
+	return e.value, true
+}
+
+func (c *Cache) put(m map[string]entry, key string, v interface{}) {
+	c.mu.Lock()
+	m[key] = entry{value: v, expires: time.Now().Add(c.TTL)}
+	c.mu.Unlock()
+}
+
+// Access mengembalikan role terkini user beserta permission role tersebut
+func (c *Cache) Access(userID string) (string, []string, error) {
+	role, err := c.UserRole(userID)
+	if err != nil {
+		return "", nil, err
+	}
+	perms, err := c.Permissions(role)
+	return role, perms, err
+}
+
+func (c *Cache) UserRole(userID string) (string, error) {
+	if v, ok := c.get(c.users, userID); ok {
+		return v.(string), nil
+	}
+	role, err := c.Repo.RoleOfUser(userID)
+	if err != nil {
+		return "", err
+	}
+	c.put(c.users, userID, role)
+	return role, nil
+}
+
+func (c *Cache) Permissions(role string) ([]string, error) {
+	if v, ok := c.get(c.roles, role); ok {
+		return v.([]string), nil
+	}
+	perms, err := c.Repo.PermissionsForRole(role)
+	if err != nil {
+		return nil, err
+	}
+	c.put(c.roles, role, perms)
+	return perms, nil
+}
+
+// Invalidate membuang entry sesuai notifikasi
+func (c *Cache) Invalidate(ch repository.RBACChange) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch ch.Scope {
+	case "role":
+		delete(c.roles, ch.Key)
+	case "user":
+		delete(c.users, ch.Key)
+	default:
+		c.roles = map[string]entry{}
+		c.users = map[string]entry{}
+	}
+}
+
+// Listen men-LISTEN channel RBAC dan meng-invalidate cache. Saat koneksi
+// tersambung ulang seluruh cache dibuang karena notifikasi bisa terlewat.
+func Listen(dsn string, cache *Cache) {
+	onEvent := func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Println("rbac: listener:", err)
+		}
+	}
+
+	listener := pq.NewListener(dsn, 2*time.Second, time.Minute, onEvent)
+	if err := listener.Listen(repository.RBACChannel); err != nil {
+		log.Println("rbac: LISTEN failed:", err)
+		return
+	}
+
+	for {
+		select {
+		case n := <-listener.Notify:
+			if n == nil {
+				cache.Invalidate(repository.RBACChange{Scope: "all"})
+				continue
+			}
+
+			var ch repository.RBACChange
+			if err := json.Unmarshal([]byte(n.Extra), &ch); err != nil {
+				log.Println("rbac: bad payload:", err)
+				continue
+			}
+			cache.Invalidate(ch)
+
+		case <-time.After(90 * time.Second):
+			go listener.Ping()
+		}
+	}
+}
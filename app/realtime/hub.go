@@ -0,0 +1,84 @@
+// Package realtime menyalurkan event (notifikasi baru, perubahan status
+// prestasi) ke client yang terhubung lewat Server-Sent Events.
+//
+// Semua event dikirim lewat Postgres NOTIFY pada channel "app_events" dan
+// setiap instance API men-LISTEN channel tersebut, sehingga client yang
+// terhubung ke instance mana pun tetap menerima event.
+package realtime
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+const Channel = "app_events"
+
+// Jenis event
+const (
+	EventNotification      = "notification"
+	EventAchievementStatus = "achievement_status"
+)
+
+// Event adalah payload yang dikirim lewat NOTIFY. UserIDs menentukan
+// penerima; Data diteruskan apa adanya ke client.
+type Event struct {
+	Type    string          `json:"type"`
+	UserIDs []string        `json:"user_ids"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// Subscriber adalah satu koneksi stream milik seorang user
+type Subscriber struct {
+	UserID string
+	C      chan Event
+}
+
+// Hub menyimpan subscriber lokal instance ini
+type Hub struct {
+	mu   sync.RWMutex
+	subs map[string]map[*Subscriber]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: map[string]map[*Subscriber]struct{}{}}
+}
+
+func (h *Hub) Subscribe(userID string) *Subscriber {
+	sub := &Subscriber{UserID: userID, C: make(chan Event, 32)}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.subs[userID] == nil {
+		h.subs[userID] = map[*Subscriber]struct{}{}
+	}
+	h.subs[userID][sub] = struct{}{}
+
+	return sub
+}
+
+func (h *Hub) Unsubscribe(sub *Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.subs[sub.UserID], sub)
+	if len(h.subs[sub.UserID]) == 0 {
+		delete(h.subs, sub.UserID)
+	}
+}
+
+// Dispatch mengirim event ke subscriber lokal. Client yang lambat
+// (buffer penuh) dilewati supaya tidak menahan client lain.
+func (h *Hub) Dispatch(ev Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, userID := range ev.UserIDs {
+		for sub := range h.subs[userID] {
+			select {
+			case sub.C <- ev:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package realtime
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Publisher mengirim event ke semua instance lewat pg_notify
+type Publisher struct {
+	DB *sqlx.DB
+}
+
+func NewPublisher(db *sqlx.DB) *Publisher {
+	return &Publisher{DB: db}
+}
+
+// Publish mengirim event ke user tertentu. Error hanya di-log karena
+// push realtime bersifat best-effort (data tetap ada di tabel).
+func (p *Publisher) Publish(eventType string, data interface{}, userIDs ...string) {
+	if p == nil || len(userIDs) == 0 {
+		return
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		log.Println("realtime: marshal event:", err)
+		return
+	}
+
+	payload, err := json.Marshal(Event{Type: eventType, UserIDs: userIDs, Data: raw})
+	if err != nil {
+		log.Println("realtime: marshal event:", err)
+		return
+	}
+
+	if _, err := p.DB.Exec(`SELECT pg_notify($1, $2)`, Channel, string(payload)); err != nil {
+		log.Println("realtime: pg_notify:", err)
+	}
+}
+
+// Listen men-LISTEN channel event dan meneruskannya ke hub.
+// Berjalan terus sampai proses berhenti; reconnect ditangani pq.Listener.
+func Listen(dsn string, hub *Hub) {
+	onEvent := func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Println("realtime: listener:", err)
+		}
+	}
+
+	listener := pq.NewListener(dsn, 2*time.Second, time.Minute, onEvent)
+	if err := listener.Listen(Channel); err != nil {
+		log.Println("realtime: LISTEN failed:", err)
+		return
+	}
+
+	log.Println("realtime: listening on channel", Channel)
+
+	for {
+		select {
+		case n := <-listener.Notify:
+			// nil = koneksi baru tersambung ulang
+			if n == nil {
+				continue
+			}
+
+			var ev Event
+			if err := json.Unmarshal([]byte(n.Extra), &ev); err != nil {
+				log.Println("realtime: bad payload:", err)
+				continue
+			}
+			hub.Dispatch(ev)
+
+		case <-time.After(90 * time.Second):
+			go listener.Ping()
+		}
+	}
+}
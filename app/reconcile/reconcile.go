@@ -0,0 +1,245 @@
+// Package reconcile mengaudit integritas antara achievement_references
+// (Postgres), koleksi achievements (Mongo) dan file lampiran di storage.
+// Mode fix hanya melakukan perbaikan yang bisa dibalik: soft delete /
+// restore dokumen Mongo dan penjadwalan ulang preview. Data yang sudah
+// hilang (dokumen atau file) hanya dilaporkan.
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"project_uas/app/model"
+	"project_uas/app/repository"
+	"project_uas/app/storage"
+)
+
+// dokumen yang lebih muda dari ini mungkin masih dalam proses create
+const orphanGrace = 10 * time.Minute
+
+type Reconciler struct {
+	Repo         *repository.ReconcileRepo
+	Achievements *repository.AchievementRepo
+	Storage      storage.Storage
+}
+
+func New(repo *repository.ReconcileRepo, achievements *repository.AchievementRepo, store storage.Storage) *Reconciler {
+	return &Reconciler{Repo: repo, Achievements: achievements, Storage: store}
+}
+
+// Run menjalankan semua pemeriksaan; fix=true juga memperbaiki yang aman
+func (r *Reconciler) Run(ctx context.Context, fix bool) (*model.ReconcileReport, error) {
+	report := &model.ReconcileReport{
+		StartedAt: time.Now(),
+		Fix:       fix,
+		Counts:    map[string]int{},
+		Issues:    []model.ReconcileIssue{},
+	}
+
+	if err := r.checkDocuments(ctx, report); err != nil {
+		return nil, err
+	}
+	if err := r.checkAttachments(ctx, report); err != nil {
+		return nil, err
+	}
+	if err := r.checkSagas(report); err != nil {
+		return nil, err
+	}
+
+	if fix {
+		for i := range report.Issues {
+			r.repair(&report.Issues[i])
+		}
+	}
+
+	for _, is := range report.Issues {
+		report.Counts[is.Kind]++
+	}
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// =====================
+// CHECKS
+// =====================
+
+func (r *Reconciler) checkDocuments(ctx context.Context, report *model.ReconcileReport) error {
+	refs, err := r.Repo.References()
+	if err != nil {
+		return fmt.Errorf("load references: %w", err)
+	}
+	docs, err := r.Repo.Documents(ctx)
+	if err != nil {
+		return fmt.Errorf("load mongo documents: %w", err)
+	}
+	busy, err := r.Repo.OpenSagaDocuments()
+	if err != nil {
+		return fmt.Errorf("load open sagas: %w", err)
+	}
+
+	report.References = len(refs)
+	report.Documents = len(docs)
+
+	referenced := map[string]bool{}
+	for _, ref := range refs {
+		referenced[ref.MongoAchievementID] = true
+
+		doc, ok := docs[ref.MongoAchievementID]
+		if !ok {
+			report.Issues = append(report.Issues, model.ReconcileIssue{
+				Kind:    model.IssueMissingDocument,
+				RefID:   ref.ID,
+				MongoID: ref.MongoAchievementID,
+				Detail:  "reference (status " + ref.Status + ") points to a missing mongo document",
+			})
+			continue
+		}
+
+		deletedRef := ref.Status == "deleted"
+		if doc.Deleted == deletedRef {
+			continue
+		}
+
+		issue := model.ReconcileIssue{
+			Kind:    model.IssueDeleteMismatch,
+			RefID:   ref.ID,
+			MongoID: ref.MongoAchievementID,
+			Fixable: !busy[ref.MongoAchievementID],
+		}
+		if deletedRef {
+			issue.Detail = "reference is deleted but mongo document is not soft-deleted"
+		} else {
+			issue.Detail = "mongo document is soft-deleted but reference status is " + ref.Status
+		}
+		if busy[ref.MongoAchievementID] {
+			issue.Detail += " (saga in progress)"
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+
+	for id, doc := range docs {
+		if referenced[id] {
+			continue
+		}
+
+		issue := model.ReconcileIssue{
+			Kind:    model.IssueOrphanDocument,
+			MongoID: id,
+			Detail:  "mongo document has no reference",
+		}
+		switch {
+		case doc.Deleted:
+			issue.Detail += " (already soft-deleted)"
+		case busy[id] || time.Since(doc.CreatedAt) < orphanGrace:
+			issue.Detail += " (may still be in progress)"
+		default:
+			issue.Fixable = true
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+
+	return nil
+}
+
+func (r *Reconciler) checkAttachments(ctx context.Context, report *model.ReconcileReport) error {
+	atts, err := r.Repo.Attachments()
+	if err != nil {
+		return fmt.Errorf("load attachments: %w", err)
+	}
+	report.Attachments = len(atts)
+
+	for _, att := range atts {
+		ok, err := r.Storage.Exists(ctx, att.StorageKey)
+		if err != nil {
+			return fmt.Errorf("check attachment %s: %w", att.ID, err)
+		}
+		if !ok {
+			report.Issues = append(report.Issues, model.ReconcileIssue{
+				Kind:         model.IssueMissingFile,
+				RefID:        att.AchievementRefID,
+				AttachmentID: att.ID,
+				Detail:       "file " + att.StorageKey + " (scan " + att.ScanStatus + ") not found in storage",
+			})
+			continue
+		}
+
+		if att.PreviewStatus != model.PreviewReady || att.PreviewKey == nil {
+			continue
+		}
+		ok, err = r.Storage.Exists(ctx, *att.PreviewKey)
+		if err != nil {
+			return fmt.Errorf("check preview %s: %w", att.ID, err)
+		}
+		if !ok {
+			report.Issues = append(report.Issues, model.ReconcileIssue{
+				Kind:         model.IssueMissingPreview,
+				RefID:        att.AchievementRefID,
+				AttachmentID: att.ID,
+				Detail:       "preview " + *att.PreviewKey + " not found in storage",
+				Fixable:      true,
+			})
+		}
+	}
+
+	return nil
+}
+
+func (r *Reconciler) checkSagas(report *model.ReconcileReport) error {
+	sagas, err := r.Repo.FailedSagas()
+	if err != nil {
+		return fmt.Errorf("load failed sagas: %w", err)
+	}
+
+	for _, s := range sagas {
+		detail := s.Kind + " saga " + s.ID + " gave up compensating"
+		if s.LastError != nil {
+			detail += ": " + *s.LastError
+		}
+		report.Issues = append(report.Issues, model.ReconcileIssue{
+			Kind:    model.IssueFailedSaga,
+			RefID:   s.RefID,
+			MongoID: s.MongoID,
+			Detail:  detail,
+		})
+	}
+
+	return nil
+}
+
+// =====================
+// REPAIR
+// =====================
+
+// repair: Postgres adalah sumber kebenaran status, Mongo mengikutinya
+func (r *Reconciler) repair(is *model.ReconcileIssue) {
+	if !is.Fixable {
+		return
+	}
+
+	var err error
+	switch is.Kind {
+	case model.IssueOrphanDocument:
+		err = r.Achievements.SoftDeleteMongo(is.MongoID)
+	case model.IssueDeleteMismatch:
+		var status string
+		status, err = r.Achievements.ReferenceStatus(is.RefID)
+		if err == nil {
+			if status == "deleted" {
+				err = r.Achievements.SoftDeleteMongo(is.MongoID)
+			} else {
+				err = r.Achievements.RestoreMongo(is.MongoID)
+			}
+		}
+	case model.IssueMissingPreview:
+		err = r.Repo.ResetPreview(is.AttachmentID)
+	default:
+		return
+	}
+
+	if err != nil {
+		is.FixError = err.Error()
+		return
+	}
+	is.Fixed = true
+}
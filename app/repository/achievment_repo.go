@@ -2,16 +2,21 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"time"
 	"fmt"
 
+	"project_uas/app/listing"
 	"project_uas/app/model"
+	"project_uas/app/workflow"
 	"project_uas/database"
 
 	"github.com/jmoiron/sqlx"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	 "github.com/lib/pq"
 )
 
@@ -125,21 +130,49 @@ func (r *AchievementRepo) PushFileToAchievement(hexID string, files []string) er
 	return err
 }
 
+// Remove a file URL from the "files" array
+func (r *AchievementRepo) PullFileFromAchievement(hexID string, file string) error {
+	r.EnsureDBs()
+
+	collection := r.Mongo.Collection("achievements")
+
+	oid, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil {
+		return err
+	}
+
+	pull := bson.M{
+		"$pull": bson.M{"files": file},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}
+
+	_, err = collection.UpdateOne(context.Background(), bson.M{"_id": oid}, pull)
+	return err
+}
+
 /* ============================================================
    POSTGRES METHODS (achievement references, history, notifications)
 ============================================================ */
 
 // Create new reference entry linking Student ↔ Mongo Achievement
-func (r *AchievementRepo) CreateReference(ref model.AchievementReference) error {
+// sagaID (opsional) ditandai completed dalam transaksi yang sama, jadi
+// reference hanya tersimpan bila saga belum dikompensasi recoverer.
+func (r *AchievementRepo) CreateReference(ref model.AchievementReference, sagaID string) error {
 	r.EnsureDBs()
 
+	tx, err := r.Psql.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
 		INSERT INTO achievement_references
 		(id, student_id, mongo_achievement_id, status, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6)
 	`
 
-	_, err := r.Psql.Exec(query,
+	_, err = tx.Exec(query,
 		ref.ID,
 		ref.StudentID,
 		ref.MongoAchievementID,
@@ -147,7 +180,29 @@ func (r *AchievementRepo) CreateReference(ref model.AchievementReference) error
 		ref.CreatedAt,
 		ref.UpdatedAt,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if sagaID != "" {
+		if err := completeSaga(tx, sagaID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ReferenceStatus mengembalikan status reference, "" bila tidak ada
+func (r *AchievementRepo) ReferenceStatus(id string) (string, error) {
+	r.EnsureDBs()
+
+	var status string
+	err := r.Psql.Get(&status, `SELECT status FROM achievement_references WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return status, err
 }
 
 // Get single reference by UUID (primary key)
@@ -174,6 +229,7 @@ func (r *AchievementRepo) GetReferenceByID(id string) (*model.AchievementReferen
     query := `
         SELECT id, student_id, mongo_achievement_id, status,
                submitted_at, verified_at, verified_by, rejection_note,
+               workflow_stages, current_stage,
                created_at, updated_at
         FROM achievement_references
         WHERE id = $1
@@ -200,6 +256,7 @@ func (r *AchievementRepo) GetReferenceByMongoID(mongoHex string) (*model.Achieve
 	query := `
 		SELECT id, student_id, mongo_achievement_id, status,
 			   submitted_at, verified_at, verified_by, rejection_note,
+			   workflow_stages, current_stage,
 			   created_at, updated_at
 		FROM achievement_references
 		WHERE mongo_achievement_id = $1
@@ -221,6 +278,7 @@ func (r *AchievementRepo) GetAllForStudent(studentID string) ([]model.Achievemen
 	query := `
 		SELECT id, student_id, mongo_achievement_id, status,
 			   submitted_at, verified_at, verified_by, rejection_note,
+			   workflow_stages, current_stage,
 			   created_at, updated_at
 		FROM achievement_references
 		WHERE student_id = $1
@@ -231,103 +289,265 @@ func (r *AchievementRepo) GetAllForStudent(studentID string) ([]model.Achievemen
 	return list, err
 }
 
-// Generic status update (submitted / verified / rejected / custom)
-func (r *AchievementRepo) UpdateReferenceStatus(refID string, status string, by *string) error {
+// ApplyTransition menyimpan satu transisi workflow: update reference,
+// catatan achievement_history dan notifikasinya dalam satu transaksi. Update hanya berhasil
+// bila status & stage di DB masih sama dengan yang divalidasi (optimistic).
+func (r *AchievementRepo) ApplyTransition(ch model.StatusChange) error {
 	r.EnsureDBs()
 
-	now := time.Now()
+	tx, err := r.Psql.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var stages interface{}
+	if ch.Stages != nil {
+		stages = pq.Array(ch.Stages)
+	}
 
-	switch status {
-	case "submitted":
-		_, err := r.Psql.Exec(`
-			UPDATE achievement_references 
-			SET status=$1, submitted_at=$2, updated_at=$3
-			WHERE id=$4
-		`, status, now, now, refID)
+	var note *string
+	if ch.ToStatus == "rejected" {
+		note = &ch.Note
+	}
+
+	res, err := tx.Exec(`
+		UPDATE achievement_references
+		SET status = $1,
+		    current_stage = $2,
+		    workflow_stages = COALESCE($3, workflow_stages),
+		    submitted_at = CASE WHEN $1 = 'submitted' AND $5 <> 'submitted' THEN NOW() ELSE submitted_at END,
+		    verified_at = CASE WHEN $1 IN ('verified', 'rejected') THEN NOW() ELSE verified_at END,
+		    verified_by = CASE WHEN $1 IN ('verified', 'rejected') THEN $4 ELSE verified_by END,
+		    rejection_note = COALESCE($7, rejection_note),
+		    updated_at = NOW()
+		WHERE id = $6 AND status = $5 AND current_stage = $8
+	`, ch.ToStatus, ch.ToStage, stages, ch.ChangedBy, ch.FromStatus, ch.RefID, note, ch.FromStage)
+	if err != nil {
 		return err
+	}
 
-	case "verified", "rejected":
-		if by == nil {
-			return nil
-		}
-		_, err := r.Psql.Exec(`
-			UPDATE achievement_references 
-			SET status=$1, verified_at=$2, verified_by=$3, updated_at=$4
-			WHERE id=$5
-		`, status, now, *by, now, refID)
+	rows, err := res.RowsAffected()
+	if err != nil {
 		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("achievement status changed concurrently, please reload")
+	}
 
-	default:
-		_, err := r.Psql.Exec(`
-			UPDATE achievement_references 
-			SET status=$1, updated_at=$2 
-			WHERE id=$3
-		`, status, now, refID)
+	var stage *string
+	if ch.StageName != "" {
+		stage = &ch.StageName
+	}
+
+	var historyID string
+	err = tx.Get(&historyID, `
+		INSERT INTO achievement_history
+		(id, achievement_ref_id, old_status, new_status, stage, changed_by, note, changed_at)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, NOW())
+		RETURNING id
+	`, ch.RefID, ch.FromStatus, ch.ToStatus, stage, ch.ChangedBy, ch.Note)
+	if err != nil {
 		return err
 	}
-}
 
-// Add to achievement history table
-func (r *AchievementRepo) AddHistory(refID, oldStatus, newStatus, changedBy, note string) error {
-	r.EnsureDBs()
+	if ch.SagaID != "" {
+		if err := completeSaga(tx, ch.SagaID); err != nil {
+			return err
+		}
+	}
 
-	_, err := r.Psql.Exec(`
-		INSERT INTO achievement_history 
-		(id, achievement_ref_id, old_status, new_status, changed_by, note, changed_at)
-		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6)
-	`, refID, oldStatus, newStatus, changedBy, note, time.Now())
+	for _, cm := range ch.Comments {
+		_, err = tx.Exec(`
+			INSERT INTO achievement_revision_comments
+			(id, history_id, achievement_ref_id, field, comment, created_at)
+			VALUES (gen_random_uuid(), $1, $2, $3, $4, NOW())
+		`, historyID, ch.RefID, cm.Field, cm.Comment)
+		if err != nil {
+			return err
+		}
+	}
 
-	return err
+	// notifikasi & outbox ikut transaksi: transisi yang ter-commit selalu
+	// punya notifikasinya, transisi yang gagal tidak mengirim apa pun
+	for _, pn := range ch.Notifications {
+		if err := insertNotificationTx(tx, &pn.Notification, pn.Outbox); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
 // Save attachment record in Postgres
-func (r *AchievementRepo) AddAttachment(refID, fileURL, fileType, uploadedBy string) error {
+func (r *AchievementRepo) AddAttachment(att model.AchievementAttachment) error {
 	r.EnsureDBs()
 
 	_, err := r.Psql.Exec(`
 		INSERT INTO achievement_attachments 
-		(id, achievement_ref_id, file_url, file_type, uploaded_by, uploaded_at)
-		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5)
-	`, refID, fileURL, fileType, uploadedBy, time.Now())
+		(id, achievement_ref_id, file_name, file_url, file_type, content_type,
+		 storage_key, checksum, file_size, scan_status, scan_next_at, uploaded_by, uploaded_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), $11, $12)
+	`, att.ID, att.AchievementRefID, att.FileName, att.FileURL, att.FileType, att.ContentType,
+		att.StorageKey, att.Checksum, att.FileSize, att.ScanStatus, att.UploadedBy, time.Now())
 
 	return err
 }
 
-// Simple "submit" wrapper (rarely used — service layer uses UpdateReferenceStatus)
-func (r *AchievementRepo) Submit(id string) error {
-    r.EnsureDBs()
+const attachmentColumns = `
+	id, achievement_ref_id, COALESCE(file_name, '') AS file_name, file_url, file_type,
+	COALESCE(content_type, '') AS content_type, COALESCE(storage_key, '') AS storage_key,
+	COALESCE(checksum, '') AS checksum, COALESCE(file_size, 0) AS file_size,
+	scan_status, scan_signature, scan_attempts, scanned_at,
+	preview_status, preview_key, preview_attempts,
+	uploaded_by, uploaded_at`
 
-    res, err := r.Psql.Exec(`
-        UPDATE achievement_references
-        SET status = 'submitted', submitted_at = NOW(), updated_at = NOW()
-        WHERE id = $1 AND status = 'draft'
-    `, id)
+func (r *AchievementRepo) GetAttachments(refID string) ([]model.AchievementAttachment, error) {
+	r.EnsureDBs()
 
-    if err != nil {
-        return err
-    }
+	var rows []model.AchievementAttachment
+	err := r.Psql.Select(&rows, `
+		SELECT `+attachmentColumns+`
+		FROM achievement_attachments
+		WHERE achievement_ref_id = $1
+		ORDER BY uploaded_at ASC
+	`, refID)
 
-    rows, _ := res.RowsAffected()
-    if rows == 0 {
-        return fmt.Errorf("no rows updated (invalid id or not draft)")
-    }
+	return rows, err
+}
+
+// GetAttachmentsByRefIDs mengambil lampiran banyak prestasi sekaligus,
+// dikelompokkan per achievement_ref_id
+func (r *AchievementRepo) GetAttachmentsByRefIDs(refIDs []string) (map[string][]model.AchievementAttachment, error) {
+	r.EnsureDBs()
 
-    return nil
+	grouped := map[string][]model.AchievementAttachment{}
+	if len(refIDs) == 0 {
+		return grouped, nil
+	}
+
+	var rows []model.AchievementAttachment
+	err := r.Psql.Select(&rows, `
+		SELECT `+attachmentColumns+`
+		FROM achievement_attachments
+		WHERE achievement_ref_id = ANY($1)
+		ORDER BY uploaded_at ASC
+	`, pq.Array(refIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, att := range rows {
+		grouped[att.AchievementRefID] = append(grouped[att.AchievementRefID], att)
+	}
+	return grouped, nil
 }
 
+func (r *AchievementRepo) GetAttachment(refID string, id string) (*model.AchievementAttachment, error) {
+	r.EnsureDBs()
 
-// Create notification record for advisor
-func (r *AchievementRepo) CreateNotification(userID, title, message string) error {
+	var att model.AchievementAttachment
+	err := r.Psql.Get(&att, `
+		SELECT `+attachmentColumns+`
+		FROM achievement_attachments
+		WHERE id = $1 AND achievement_ref_id = $2
+	`, id, refID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &att, nil
+}
+
+// DeleteAttachment menghapus record. Blob content-addressed bisa dibagi
+// beberapa lampiran, jadi release(storageKey, previewKey) hanya dipanggil
+// bila blob tidak dipakai lagi, di dalam transaksi selagi kunci blob
+// dipegang (lihat LockBlobTx) sehingga scan yang mempromosikan isi yang
+// sama menunggu sampai blob benar-benar terhapus.
+func (r *AchievementRepo) DeleteAttachment(id string, release func(storageKey string, previewKey string)) error {
 	r.EnsureDBs()
 
-	_, err := r.Psql.Exec(`
-		INSERT INTO notifications (user_id, title, message, is_read, created_at)
-		VALUES ($1, $2, $3, false, NOW())
-	`, userID, title, message)
+	tx, err := r.Psql.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// kunci blob diambil sebelum baris dihapus supaya urutan kunci sama
+	// dengan scanner (blob → baris) dan tidak saling deadlock
+	var current string
+	err = tx.Get(&current, `SELECT COALESCE(storage_key, '') FROM achievement_attachments WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if current != "" {
+		if err := LockBlobTx(tx, current); err != nil {
+			return err
+		}
+	}
+
+	var storageKey, previewKey string
+	err = tx.QueryRowx(`
+		DELETE FROM achievement_attachments
+		WHERE id = $1
+		RETURNING COALESCE(storage_key, ''), COALESCE(preview_key, '')
+	`, id).Scan(&storageKey, &previewKey)
+	if err != nil {
+		return err
+	}
+
+	if storageKey == "" {
+		return tx.Commit()
+	}
+	// scan selesai di antara SELECT dan DELETE: key sudah pindah ke blob
+	if storageKey != current {
+		if err := LockBlobTx(tx, storageKey); err != nil {
+			return err
+		}
+	}
+
+	inUse, err := BlobInUseTx(tx, storageKey)
+	if err != nil {
+		return err
+	}
+	if !inUse {
+		release(storageKey, previewKey)
+	}
+	return tx.Commit()
+}
+
+// DeleteAchievementMongo menghapus dokumen permanen (kompensasi create)
+func (r *AchievementRepo) DeleteAchievementMongo(hexID string) error {
+	r.EnsureDBs()
+
+	oid, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil {
+		return err
+	}
 
+	_, err = r.Mongo.Collection("achievements").DeleteOne(context.Background(), bson.M{"_id": oid})
 	return err
 }
 
+// RestoreMongo membatalkan soft delete (kompensasi delete)
+func (r *AchievementRepo) RestoreMongo(hexID string) error {
+	r.EnsureDBs()
+
+	oid, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.Mongo.Collection("achievements").UpdateOne(
+		context.Background(),
+		bson.M{"_id": oid},
+		bson.M{
+			"$unset": bson.M{"deleted_at": ""},
+			"$set":   bson.M{"updated_at": time.Now()},
+		},
+	)
+	return err
+}
 
 func (r *AchievementRepo) SoftDeleteMongo(hexID string) error {
     r.EnsureDBs()
@@ -351,152 +571,167 @@ func (r *AchievementRepo) SoftDeleteMongo(hexID string) error {
     return err
 }
 
-func (r *AchievementRepo) SoftDeleteReference(refID string) error {
-    r.EnsureDBs()
-
-    _, err := r.Psql.Exec(`
-        UPDATE achievement_references
-        SET status = 'deleted', updated_at = NOW()
-        WHERE id = $1
-    `, refID)
-
-    return err
-}
-
-// Get references by student IDs (pagination)
-func (r *AchievementRepo) GetReferencesByStudentIDs(studentIDs []string,limit int,offset int,) ([]model.AchievementReference, error) {
+// GetAchievementMongoDetails mengambil banyak dokumen dengan satu query $in,
+// dipetakan per hex ID. ID yang tidak valid / tidak ditemukan tidak ada di map;
+// pemanggil yang memutuskan cara melaporkannya.
+func (r *AchievementRepo) GetAchievementMongoDetails(hexIDs []string) (map[string]bson.M, error) {
+	r.EnsureDBs()
 
-	var refs []model.AchievementReference
+	result := make(map[string]bson.M, len(hexIDs))
 
-	query := `
-		SELECT id, student_id, mongo_achievement_id, status,
-		       created_at, updated_at
-		FROM achievement_references
-		WHERE student_id = ANY($1)
-		  AND status = 'submitted'
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
-	`
-
-	err := r.Psql.Select(&refs, query, pq.Array(studentIDs), limit, offset)
-	return refs, err
-}
+	oids := make([]primitive.ObjectID, 0, len(hexIDs))
+	for _, id := range hexIDs {
+		if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+			oids = append(oids, oid)
+		}
+	}
+	if len(oids) == 0 {
+		return result, nil
+	}
 
-func (r *AchievementRepo) GetAchievementMongoDetail(id string) (bson.M, error) {
-	objID, err := primitive.ObjectIDFromHex(id)
+	ctx := context.Background()
+	cursor, err := r.Mongo.Collection("achievements").Find(ctx, bson.M{"_id": bson.M{"$in": oids}})
 	if err != nil {
 		return nil, err
 	}
+	defer cursor.Close(ctx)
 
-	var result bson.M
-	err = r.Mongo.Collection("achievements").
-		FindOne(context.Background(), bson.M{"_id": objID}).
-		Decode(&result)
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		if oid, ok := doc["_id"].(primitive.ObjectID); ok {
+			result[oid.Hex()] = doc
+		}
+	}
 
-	return result, err
+	return result, cursor.Err()
 }
 
 
-func (r *AchievementRepo) VerifyReference(refID string,lecturerID string,) error {
+// UpdateDraftAchievement mengembalikan dokumen sebelum diubah (jejak audit)
+func (r *AchievementRepo) UpdateDraftAchievement(refID string,userID string,update map[string]interface{},) (bson.M, error) {
 
 	r.EnsureDBs()
 
-	_, err := r.Psql.Exec(`
-		UPDATE achievement_references
-		SET
-			status = 'verified',
-			verified_by = $2,
-			verified_at = NOW(),
-			updated_at = NOW()
-		WHERE
-			id = $1
-			AND status = 'submitted'
-	`, refID, lecturerID)
-
-	return err
-}
+	// 1. Ambil reference
+	ref, err := r.GetReferenceByID(refID)
+	if err != nil {
+		return nil, fmt.Errorf("achievement not found")
+	}
 
+	// 2. Validasi status
+	if !workflow.Allowed(ref.Status, workflow.ActionEdit) {
+		return nil, fmt.Errorf("only draft or revision-requested achievements can be updated")
+	}
 
-func (r *AchievementRepo) RejectReference(
-	refID string,
-	rejectedBy string,
-	note string,
-) error {
+	// 3. Update Mongo
+	oid, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+	if err != nil {
+		return nil, err
+	}
 
-	r.EnsureDBs()
+	update["updated_at"] = time.Now()
 
-	_, err := r.Psql.Exec(`
-		UPDATE achievement_references
-		SET status = 'rejected',
-		    rejection_note = $1,
-		    verified_by = $2,
-		    verified_at = NOW(),
-		    updated_at = NOW()
-		WHERE id = $3
-	`, note, rejectedBy, refID)
+	var before bson.M
+	err = r.Mongo.Collection("achievements").FindOneAndUpdate(
+		context.Background(),
+		bson.M{"_id": oid},
+		bson.M{"$set": update},
+		options.FindOneAndUpdate().SetReturnDocument(options.Before),
+	).Decode(&before)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("achievement document not found")
+	}
 
-	return err
+	return before, err
 }
 
-func (r *AchievementRepo) GetAllReferencesWithDetail() ([]map[string]interface{}, error) {
-	r.EnsureDBs()
 
-	query := `
-		SELECT id, student_id, mongo_achievement_id, status, created_at
-		FROM achievement_references
-		ORDER BY created_at DESC
-	`
-
-	var refs []model.AchievementReference
-	if err := r.Psql.Select(&refs, query); err != nil {
-		return nil, err
-	}
+// ReferenceListSpec: sort / filter untuk semua list prestasi berbasis
+// achievement_references (admin, pending, bimbingan, per mahasiswa)
+var ReferenceListSpec = listing.Spec{
+	Sorts: map[string]listing.Field{
+		"created_at": {Column: "ar.created_at", Key: "created_at"},
+		"updated_at": {Column: "ar.updated_at", Key: "updated_at"},
+	},
+	DefaultSort: "-created_at",
+	IDColumn:    "ar.id",
+	Filters: map[string]listing.Filter{
+		"status":        {Column: "ar.status", Op: listing.OpIn},
+		"student_id":    {Column: "ar.student_id", Op: listing.OpEq},
+		"created_from":  {Column: "ar.created_at", Op: listing.OpFrom},
+		"created_until": {Column: "ar.created_at", Op: listing.OpUntil},
+	},
+}
 
-	results := []map[string]interface{}{}
+// ListReferences menerapkan parameter list pada achievement_references.
+// where/args adalah scope tetap dari pemanggil (placeholder "?").
+func (r *AchievementRepo) ListReferences(p *listing.Params, where []string, args ...interface{}) (*listing.Page[model.AchievementReference], error) {
+	r.EnsureDBs()
 
-	for _, ref := range refs {
-		detail, err := r.GetAchievementMongoDetail(ref.MongoAchievementID)
-		if err != nil {
-			continue // mongo miss → skip
-		}
+	return listing.Fetch[model.AchievementReference](r.Psql, ReferenceListSpec, p, listing.Query{
+		Select: `ar.id, ar.student_id, ar.mongo_achievement_id, ar.status,
+		         ar.submitted_at, ar.verified_at, ar.verified_by, ar.rejection_note,
+		         ar.workflow_stages, ar.current_stage, ar.created_at, ar.updated_at`,
+		From:  "achievement_references ar",
+		Where: where,
+		Args:  args,
+	})
+}
 
-		results = append(results, map[string]interface{}{
-			"reference": ref,
-			"detail":    detail,
-		})
+// PendingCondition: submitted dan sedang menunggu salah satu stage
+// (nil = semua). Reference lama tanpa snapshot stage dianggap menunggu advisor.
+func PendingCondition(stages []string) ([]string, []interface{}) {
+	where := []string{"ar.status = 'submitted'"}
+	if stages == nil {
+		return where, nil
 	}
-
-	return results, nil
+	return append(where, "COALESCE(ar.workflow_stages[ar.current_stage + 1], 'advisor') = ANY(?)"),
+		[]interface{}{pq.Array(stages)}
 }
 
-func (r *AchievementRepo) UpdateDraftAchievement(refID string,userID string,update map[string]interface{},) error {
-
+// Get full history (setiap ronde revisi) beserta komentar per-field
+func (r *AchievementRepo) GetHistory(refID string) ([]model.AchievementHistory, error) {
 	r.EnsureDBs()
 
-	// 1. Ambil reference
-	ref, err := r.GetReferenceByID(refID)
+	rows := []model.AchievementHistory{}
+	err := r.Psql.Select(&rows, `
+		SELECT id, achievement_ref_id, old_status, new_status, stage, changed_by, note, changed_at
+		FROM achievement_history
+		WHERE achievement_ref_id = $1
+		ORDER BY changed_at ASC
+	`, refID)
 	if err != nil {
-		return fmt.Errorf("achievement not found")
+		return nil, err
 	}
 
-	// 2. Validasi status
-	if ref.Status != "draft" {
-		return fmt.Errorf("only draft can be updated")
+	comments, err := r.GetRevisionComments(refID)
+	if err != nil {
+		return nil, err
 	}
 
-	// 3. Update Mongo
-	oid, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
-	if err != nil {
-		return err
+	byHistory := map[string][]model.RevisionComment{}
+	for _, cm := range comments {
+		byHistory[cm.HistoryID] = append(byHistory[cm.HistoryID], cm)
+	}
+	for i := range rows {
+		rows[i].Comments = byHistory[rows[i].ID]
 	}
 
-	update["updated_at"] = time.Now()
+	return rows, nil
+}
 
-	_, err = r.Mongo.Collection("achievements").UpdateOne(
-		context.Background(),
-		bson.M{"_id": oid},
-		bson.M{"$set": update},
-	)
+func (r *AchievementRepo) GetRevisionComments(refID string) ([]model.RevisionComment, error) {
+	r.EnsureDBs()
 
-	return err
+	comments := []model.RevisionComment{}
+	err := r.Psql.Select(&comments, `
+		SELECT id, history_id, achievement_ref_id, field, comment, created_at
+		FROM achievement_revision_comments
+		WHERE achievement_ref_id = $1
+		ORDER BY created_at ASC
+	`, refID)
+	return comments, err
 }
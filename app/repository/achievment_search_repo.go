@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"project_uas/app/model"
+
+	"github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/* ============================================================
+   SEARCH (Postgres scope + Mongo full-text)
+============================================================ */
+
+// searchBatchSize: jumlah hasil Mongo yang dicocokkan ke Postgres per query
+const searchBatchSize = 500
+
+// SearchCandidates mengembalikan prestasi dalam scope role beserta
+// atribut Postgres yang difilter / di-facet (status, prodi, angkatan).
+// Filternya diterapkan pemanggil supaya facet bisa dihitung tanpa filter
+// sendiri. mongoIDs (bila tidak nil) membatasi ke satu batch hasil Mongo;
+// limit > 0 membatasi jumlah baris.
+func (r *AchievementRepo) SearchCandidates(scope model.SearchScope, mongoIDs []string, limit int) ([]model.SearchCandidate, error) {
+	r.EnsureDBs()
+
+	where := []string{"ar.status <> 'deleted'"}
+	args := []interface{}{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if !scope.IncludeDrafts {
+		where = append(where, "ar.status <> 'draft'")
+	}
+	if scope.StudentIDs != nil {
+		where = append(where, "ar.student_id = ANY("+arg(pq.Array(scope.StudentIDs))+")")
+	}
+	if scope.Units != nil {
+		cond, unitArgs := UnitScopeCondition(scope.Units, "ar.student_id")
+		for _, v := range unitArgs {
+			cond = strings.Replace(cond, "?", arg(v), 1)
+		}
+		where = append(where, cond)
+	}
+	if mongoIDs != nil {
+		where = append(where, "ar.mongo_achievement_id = ANY("+arg(pq.Array(mongoIDs))+")")
+	}
+
+	query := `
+		SELECT ar.id AS ref_id, ar.mongo_achievement_id, ar.status,
+		       s.id AS student_id, s.student_id AS student_number,
+		       COALESCE(u.full_name, '') AS student_name,
+		       COALESCE(s.program_study, '') AS program_study,
+		       COALESCE(s.academic_year, '') AS academic_year
+		FROM achievement_references ar
+		JOIN students s ON s.id = ar.student_id
+		LEFT JOIN users u ON u.id = s.user_id
+		WHERE ` + strings.Join(where, " AND ")
+	if limit > 0 {
+		query += " LIMIT " + arg(limit)
+	}
+
+	list := []model.SearchCandidate{}
+	err := r.Psql.Select(&list, query, args...)
+	return list, err
+}
+
+// SearchMongo mengalirkan dokumen yang cocok dengan teks & rentang tanggal
+// sesuai urutan sort, per batch searchBatchSize. Hanya field yang
+// di-facet yang diambil; filter kategori/tingkat diterapkan pemanggil
+// supaya facet-nya bisa dihitung tanpa filter sendiri. onlyIDs (bila
+// tidak nil) membatasi ke _id tersebut.
+func (r *AchievementRepo) SearchMongo(ctx context.Context, q model.AchievementSearch, onlyIDs []string, fn func(hits []model.SearchHit) error) error {
+	r.EnsureDBs()
+
+	match := bson.M{"deleted_at": bson.M{"$exists": false}}
+	if onlyIDs != nil {
+		oids := make([]primitive.ObjectID, 0, len(onlyIDs))
+		for _, id := range onlyIDs {
+			if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+				oids = append(oids, oid)
+			}
+		}
+		match["_id"] = bson.M{"$in": oids}
+	}
+	if q.Query != "" {
+		match["$text"] = bson.M{"$search": q.Query}
+	}
+	if q.From != nil || q.To != nil {
+		date := bson.M{}
+		if q.From != nil {
+			date["$gte"] = *q.From
+		}
+		if q.To != nil {
+			date["$lt"] = *q.To
+		}
+		match["event_date"] = date
+	}
+
+	var sort bson.D
+	switch {
+	case q.Sort == "event_date":
+		sort = bson.D{{Key: "event_date", Value: -1}, {Key: "_id", Value: -1}}
+	case q.Sort == "relevance" && q.Query != "", q.Sort == "" && q.Query != "":
+		sort = bson.D{{Key: "score", Value: -1}, {Key: "_id", Value: -1}}
+	default:
+		sort = bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+	}
+	if q.Query != "" {
+		pipeline = append(pipeline, bson.D{{Key: "$addFields", Value: bson.M{"score": bson.M{"$meta": "textScore"}}}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: sort}},
+		bson.D{{Key: "$project", Value: bson.M{"category": 1, "level": 1, "event_date": 1}}},
+	)
+
+	opts := options.Aggregate().SetAllowDiskUse(true).SetBatchSize(searchBatchSize)
+	cursor, err := r.Mongo.Collection("achievements").Aggregate(ctx, pipeline, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	batch := make([]model.SearchHit, 0, searchBatchSize)
+	for cursor.Next(ctx) {
+		var hit model.SearchHit
+		if err := cursor.Decode(&hit); err != nil {
+			return err
+		}
+		batch = append(batch, hit)
+		if len(batch) == searchBatchSize {
+			if err := fn(batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		return fn(batch)
+	}
+	return nil
+}
+
+// SearchDocuments mengambil dokumen lengkap untuk satu halaman hasil
+func (r *AchievementRepo) SearchDocuments(ctx context.Context, hexIDs []string) (map[string]model.Achievement, error) {
+	r.EnsureDBs()
+
+	oids := make([]primitive.ObjectID, 0, len(hexIDs))
+	for _, id := range hexIDs {
+		if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+			oids = append(oids, oid)
+		}
+	}
+
+	docs := make(map[string]model.Achievement, len(oids))
+	if len(oids) == 0 {
+		return docs, nil
+	}
+
+	cursor, err := r.Mongo.Collection("achievements").Find(ctx, bson.M{"_id": bson.M{"$in": oids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc model.Achievement
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		docs[doc.ID.Hex()] = doc
+	}
+	return docs, cursor.Err()
+}
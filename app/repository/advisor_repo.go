@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"project_uas/app/model"
+)
+
+var ErrStudentNotFound = errors.New("student not found")
+
+// AdvisorRepo mengelola penetapan dosen wali beserta riwayatnya
+// (advisor_assignments). students.advisor_id tetap advisor aktif.
+type AdvisorRepo struct {
+	DB *sqlx.DB
+}
+
+func NewAdvisorRepo(db *sqlx.DB) *AdvisorRepo {
+	return &AdvisorRepo{DB: db}
+}
+
+// =====================
+// PENETAPAN
+// =====================
+
+// Assign menerapkan semua perubahan dalam satu transaksi (semua atau
+// tidak sama sekali). Perubahan ke advisor yang sama ditandai
+// Changed=false dan tidak menambah riwayat.
+func (r *AdvisorRepo) Assign(changes []model.AdvisorChange, method string, assignedBy *string) error {
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// urutan kunci baris tetap supaya dua bulk assign tidak saling deadlock
+	order := make([]int, len(changes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return changes[order[a]].StudentID < changes[order[b]].StudentID
+	})
+
+	for _, i := range order {
+		if err := AssignAdvisorTx(tx, &changes[i], method, assignedBy); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// AssignAdvisorTx mengganti advisor satu mahasiswa di tx dan mencatat
+// riwayatnya; ch.StudentNIM, PreviousAdvisorID & Changed diisi
+func AssignAdvisorTx(tx *sqlx.Tx, ch *model.AdvisorChange, method string, assignedBy *string) error {
+	var cur struct {
+		UserID    string  `db:"user_id"`
+		StudentID string  `db:"student_id"`
+		AdvisorID *string `db:"advisor_id"`
+	}
+	err := tx.Get(&cur, `
+		SELECT user_id, student_id, advisor_id FROM students WHERE id = $1 FOR UPDATE
+	`, ch.StudentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrStudentNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	ch.Changed = false
+	ch.StudentUserID = cur.UserID
+	ch.StudentNIM = cur.StudentID
+	ch.PreviousAdvisorID = cur.AdvisorID
+	if cur.AdvisorID != nil && *cur.AdvisorID == ch.AdvisorID {
+		return nil
+	}
+
+	if _, err := tx.Exec(`UPDATE students SET advisor_id = $1 WHERE id = $2`, ch.AdvisorID, ch.StudentID); err != nil {
+		return err
+	}
+	if err := RecordAdvisorTx(tx, ch.StudentID, ch.AdvisorID, cur.AdvisorID, method, assignedBy); err != nil {
+		return err
+	}
+	ch.Changed = true
+	return nil
+}
+
+// RecordAdvisorTx menutup periode advisor yang aktif dan membuka periode
+// baru. Dipanggil juga saat mahasiswa dibuat dengan advisor (impor).
+func RecordAdvisorTx(tx *sqlx.Tx, studentID string, advisorID string, previous *string, method string, assignedBy *string) error {
+	_, err := tx.Exec(`
+		UPDATE advisor_assignments SET effective_to = NOW()
+		WHERE student_id = $1 AND effective_to IS NULL
+	`, studentID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO advisor_assignments
+		(id, student_id, advisor_id, previous_advisor_id, method, assigned_by, effective_from)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, NOW())
+	`, studentID, advisorID, previous, method, assignedBy)
+	return err
+}
+
+// =====================
+// VALIDASI
+// =====================
+
+// UnknownStudents mengembalikan id yang tidak ada di tabel students
+func (r *AdvisorRepo) UnknownStudents(ids []string) ([]string, error) {
+	missing := []string{}
+	err := r.DB.Select(&missing, `
+		SELECT u.sid FROM unnest($1::text[]) AS u(sid)
+		WHERE NOT EXISTS (SELECT 1 FROM students s WHERE s.id::text = u.sid)
+	`, pq.Array(ids))
+	return missing, err
+}
+
+// InactiveLecturers mengembalikan id yang bukan dosen dengan akun aktif
+func (r *AdvisorRepo) InactiveLecturers(ids []string) ([]string, error) {
+	missing := []string{}
+	err := r.DB.Select(&missing, `
+		SELECT u.lid FROM unnest($1::text[]) AS u(lid)
+		WHERE NOT EXISTS (
+			SELECT 1 FROM lecturers l
+			JOIN users us ON us.id = l.user_id
+			WHERE l.id::text = u.lid AND us.is_active
+		)
+	`, pq.Array(ids))
+	return missing, err
+}
+
+// StudentsOutsideScope mengembalikan id mahasiswa di luar unit scope
+func (r *AdvisorRepo) StudentsOutsideScope(ids []string, scope []string) ([]string, error) {
+	cond, args := UnitScopeCondition(scope, "u.sid::uuid")
+	outside := []string{}
+	err := r.DB.Select(&outside, r.DB.Rebind(`
+		SELECT u.sid FROM unnest(?::text[]) AS u(sid)
+		WHERE NOT (`+cond+`)
+	`), append([]interface{}{pq.Array(ids)}, args...)...)
+	return outside, err
+}
+
+// StudentsOutsideDepartment mengembalikan id mahasiswa yang tidak berada
+// di departemen (lihat view student_units)
+func (r *AdvisorRepo) StudentsOutsideDepartment(ids []string, departmentID string) ([]string, error) {
+	outside := []string{}
+	err := r.DB.Select(&outside, `
+		SELECT u.sid FROM unnest($1::text[]) AS u(sid)
+		WHERE NOT EXISTS (
+			SELECT 1 FROM student_units su
+			WHERE su.student_id::text = u.sid AND su.department_id = $2
+		)
+	`, pq.Array(ids), departmentID)
+	return outside, err
+}
+
+// =====================
+// BEBAN BIMBINGAN
+// =====================
+
+// Loads: dosen aktif di departemen beserta jumlah bimbingan aktifnya,
+// beban terkecil lebih dulu
+func (r *AdvisorRepo) Loads(departmentID string) ([]model.AdvisorLoad, error) {
+	rows := []model.AdvisorLoad{}
+	err := r.DB.Select(&rows, `
+		SELECT l.id, l.user_id, l.lecturer_id, u.full_name, l.department_id,
+		       (SELECT COUNT(*) FROM students s WHERE s.advisor_id = l.id) AS advisees
+		FROM lecturers l
+		JOIN users u ON u.id = l.user_id
+		WHERE l.department_id = $1 AND u.is_active
+		ORDER BY advisees, l.lecturer_id
+	`, departmentID)
+	return rows, err
+}
+
+// AdvisorCandidate adalah mahasiswa yang akan dibagi oleh auto-assign
+type AdvisorCandidate struct {
+	ID        string  `db:"id"`
+	StudentID string  `db:"student_id"`
+	AdvisorID *string `db:"advisor_id"`
+}
+
+// Candidates: mahasiswa tertentu (ids), atau bila kosong semua mahasiswa
+// departemen yang belum punya dosen wali; urut NIM supaya hasil stabil
+func (r *AdvisorRepo) Candidates(departmentID string, ids []string) ([]AdvisorCandidate, error) {
+	rows := []AdvisorCandidate{}
+	if len(ids) > 0 {
+		err := r.DB.Select(&rows, `
+			SELECT id, student_id, advisor_id FROM students
+			WHERE id::text = ANY($1::text[])
+			ORDER BY student_id
+		`, pq.Array(ids))
+		return rows, err
+	}
+
+	err := r.DB.Select(&rows, `
+		SELECT s.id, s.student_id, s.advisor_id
+		FROM students s
+		JOIN student_units su ON su.student_id = s.id
+		WHERE su.department_id = $1 AND s.advisor_id IS NULL
+		ORDER BY s.student_id
+	`, departmentID)
+	return rows, err
+}
+
+// =====================
+// RIWAYAT
+// =====================
+func (r *AdvisorRepo) History(studentID string) ([]model.AdvisorAssignment, error) {
+	rows := []model.AdvisorAssignment{}
+	err := r.DB.Select(&rows, `
+		SELECT a.id, a.student_id, a.advisor_id, l.lecturer_id AS advisor_lecturer_id,
+		       u.full_name AS advisor_name, a.previous_advisor_id, a.method,
+		       a.assigned_by, a.effective_from, a.effective_to
+		FROM advisor_assignments a
+		LEFT JOIN lecturers l ON l.id = a.advisor_id
+		LEFT JOIN users u ON u.id = l.user_id
+		WHERE a.student_id = $1
+		ORDER BY a.effective_from DESC, a.effective_to DESC NULLS FIRST
+	`, studentID)
+	return rows, err
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"time"
+
+	"project_uas/app/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type AttachmentPreviewRepo struct {
+	DB *sqlx.DB
+}
+
+func NewAttachmentPreviewRepo(db *sqlx.DB) *AttachmentPreviewRepo {
+	return &AttachmentPreviewRepo{DB: db}
+}
+
+// Claim mengambil lampiran bersih yang belum punya pratinjau. File yang
+// belum lolos scan tidak pernah diproses decoder gambar/PDF.
+func (r *AttachmentPreviewRepo) Claim(limit int, lockFor time.Duration) ([]model.AchievementAttachment, error) {
+	list := []model.AchievementAttachment{}
+	err := r.DB.Select(&list, `
+		UPDATE achievement_attachments
+		SET preview_attempts = preview_attempts + 1,
+		    preview_next_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM achievement_attachments
+			WHERE preview_status = 'pending'
+			  AND scan_status = 'clean'
+			  AND preview_next_at <= NOW()
+			ORDER BY preview_next_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+attachmentColumns+`
+	`, limit, lockFor.Seconds())
+	return list, err
+}
+
+func (r *AttachmentPreviewRepo) MarkReady(id string, previewKey string) error {
+	_, err := r.DB.Exec(`
+		UPDATE achievement_attachments
+		SET preview_status = 'ready', preview_key = $2, preview_error = NULL
+		WHERE id = $1
+	`, id, previewKey)
+	return err
+}
+
+// MarkRetry menjadwalkan ulang, atau menutup dengan status final
+// (unsupported / failed) bila tidak perlu dicoba lagi.
+func (r *AttachmentPreviewRepo) MarkRetry(id string, errMsg string, next time.Time, finalStatus string) error {
+	status := model.PreviewPending
+	if finalStatus != "" {
+		status = finalStatus
+	}
+
+	_, err := r.DB.Exec(`
+		UPDATE achievement_attachments
+		SET preview_status = $2, preview_error = $3, preview_next_at = $4
+		WHERE id = $1
+	`, id, status, errMsg, next)
+	return err
+}
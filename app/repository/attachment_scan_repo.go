@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"time"
+
+	"project_uas/app/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type AttachmentScanRepo struct {
+	DB *sqlx.DB
+}
+
+func NewAttachmentScanRepo(db *sqlx.DB) *AttachmentScanRepo {
+	return &AttachmentScanRepo{DB: db}
+}
+
+// Claim mengambil lampiran yang menunggu scan. Seperti outbox, scan_next_at
+// dimajukan sebesar lockFor supaya instance lain tidak mengambil file yang
+// sama; bila worker mati, file diambil lagi setelah lock habis.
+func (r *AttachmentScanRepo) Claim(limit int, lockFor time.Duration) ([]model.AchievementAttachment, error) {
+	list := []model.AchievementAttachment{}
+	err := r.DB.Select(&list, `
+		UPDATE achievement_attachments
+		SET scan_attempts = scan_attempts + 1,
+		    scan_next_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM achievement_attachments
+			WHERE scan_status = 'pending_scan'
+			  AND storage_key IS NOT NULL
+			  AND scan_next_at <= NOW()
+			ORDER BY scan_next_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+attachmentColumns+`
+	`, limit, lockFor.Seconds())
+	return list, err
+}
+
+// LockBlobTx mengunci storage key sampai tx selesai. Cek "blob masih
+// dipakai?" + hapus blob (DeleteAttachment) dan promosi + MarkClean
+// (scanner) memegang kunci yang sama, sehingga lampiran baru tidak bisa
+// menautkan blob yang sedang dihapus.
+func LockBlobTx(tx *sqlx.Tx, storageKey string) error {
+	_, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, storageKey)
+	return err
+}
+
+// BlobInUseTx melaporkan apakah masih ada lampiran yang menunjuk blob
+func BlobInUseTx(tx *sqlx.Tx, storageKey string) (bool, error) {
+	var inUse bool
+	err := tx.Get(&inUse, `SELECT EXISTS (SELECT 1 FROM achievement_attachments WHERE storage_key = $1)`, storageKey)
+	return inUse, err
+}
+
+// WithBlobLock menjalankan fn dalam transaksi yang memegang kunci blob
+func (r *AttachmentScanRepo) WithBlobLock(storageKey string, fn func(tx *sqlx.Tx) error) error {
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := LockBlobTx(tx, storageKey); err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MarkCleanTx menunjuk ke blob hasil promosi. false bila lampiran sudah
+// dihapus selama scan berlangsung.
+func MarkCleanTx(tx *sqlx.Tx, id string, storageKey string) (bool, error) {
+	res, err := tx.Exec(`
+		UPDATE achievement_attachments
+		SET scan_status = 'clean', storage_key = $2, scan_error = NULL, scanned_at = NOW()
+		WHERE id = $1 AND scan_status = 'pending_scan'
+	`, id, storageKey)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// MarkInfected mencatat signature; file karantina sudah dibuang
+func (r *AttachmentScanRepo) MarkInfected(id string, signature string) error {
+	_, err := r.DB.Exec(`
+		UPDATE achievement_attachments
+		SET scan_status = 'infected', scan_signature = $2, storage_key = NULL,
+		    scan_error = NULL, scanned_at = NOW()
+		WHERE id = $1
+	`, id, signature)
+	return err
+}
+
+// MarkRetry menjadwalkan scan ulang (mis. daemon tidak bisa dihubungi),
+// atau menutup dengan scan_failed bila final. Keduanya tidak pernah
+// melepas file tanpa scan; file karantina tetap ada sampai lampiran dihapus.
+func (r *AttachmentScanRepo) MarkRetry(id string, errMsg string, next time.Time, final bool) error {
+	status := model.ScanPending
+	if final {
+		status = model.ScanFailed
+	}
+
+	_, err := r.DB.Exec(`
+		UPDATE achievement_attachments
+		SET scan_status = $2, scan_error = $3, scan_next_at = $4
+		WHERE id = $1 AND scan_status = 'pending_scan'
+	`, id, status, errMsg, next)
+	return err
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"github.com/jmoiron/sqlx"
+
+	"project_uas/app/listing"
+	"project_uas/app/model"
+)
+
+// AuditRepo menulis & membaca audit_logs. Tabel append-only (dijaga
+// trigger), sehingga repo ini hanya punya INSERT dan SELECT.
+type AuditRepo struct {
+	DB *sqlx.DB
+}
+
+func NewAuditRepo(db *sqlx.DB) *AuditRepo {
+	return &AuditRepo{DB: db}
+}
+
+// baris per INSERT: 16 kolom × 1000 masih di bawah batas 65535 parameter
+const auditInsertBatch = 1000
+
+// Insert menyimpan semua baris satu request dalam satu transaksi (impor
+// massal bisa menghasilkan ribuan baris, jadi dipecah per batch)
+func (r *AuditRepo) Insert(entries []model.AuditLog) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for start := 0; start < len(entries); start += auditInsertBatch {
+		end := start + auditInsertBatch
+		if end > len(entries) {
+			end = len(entries)
+		}
+		_, err := tx.NamedExec(`
+			INSERT INTO audit_logs
+			(id, occurred_at, actor_user_id, actor_role, action, resource_type, resource_id,
+			 method, path, status, ip, user_agent, request_id, before, after, diff)
+			VALUES
+			(gen_random_uuid(), NOW(), :actor_user_id, :actor_role, :action, :resource_type, :resource_id,
+			 :method, :path, :status, :ip, :user_agent, :request_id, :before, :after, :diff)
+		`, entries[start:end])
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// AuditListSpec: sort / filter untuk GET /audit-logs
+var AuditListSpec = listing.Spec{
+	Sorts: map[string]listing.Field{
+		"occurred_at": {Column: "occurred_at", Key: "occurred_at"},
+	},
+	DefaultSort: "-occurred_at",
+	IDColumn:    "id",
+	Filters: map[string]listing.Filter{
+		"actor_user_id": {Column: "actor_user_id::text", Op: listing.OpEq},
+		"action":        {Column: "action", Op: listing.OpIn},
+		"resource_type": {Column: "resource_type", Op: listing.OpIn},
+		"resource_id":   {Column: "resource_id", Op: listing.OpEq},
+		"request_id":    {Column: "request_id", Op: listing.OpEq},
+		"method":        {Column: "method", Op: listing.OpIn},
+		"failed":        {Column: "(status >= 400)", Op: listing.OpBool},
+		"from":          {Column: "occurred_at", Op: listing.OpFrom},
+		"until":         {Column: "occurred_at", Op: listing.OpUntil},
+	},
+	Search: []string{"path", "action", "resource_id"},
+}
+
+const auditColumns = `id, occurred_at, actor_user_id, actor_role, action, resource_type, resource_id,
+	method, path, status, ip, user_agent, request_id, before, after, diff`
+
+func (r *AuditRepo) List(p *listing.Params) (*listing.Page[model.AuditLog], error) {
+	return listing.Fetch[model.AuditLog](r.DB, AuditListSpec, p, listing.Query{
+		Select: auditColumns,
+		From:   "audit_logs",
+	})
+}
+
+func (r *AuditRepo) GetByID(id string) (*model.AuditLog, error) {
+	var row model.AuditLog
+	err := r.DB.Get(&row, `SELECT `+auditColumns+` FROM audit_logs WHERE id::text = $1`, id)
+	return &row, err
+}
@@ -39,6 +39,30 @@ func (r *AuthRepo) FindByUsername(username string) (*model.User, error) {
 	return &user, err
 }
 
+// =====================
+//    FIND USER BY ID
+// =====================
+func (r *AuthRepo) FindByID(userID string) (*model.User, error) {
+	var user model.User
+
+	err := r.DB.Get(&user, `
+		SELECT 
+			id, 
+			username, 
+			email, 
+			password_hash, 
+			full_name, 
+			role_id,
+			is_active, 
+			created_at, 
+			updated_at
+		FROM users
+		WHERE id = $1
+	`, userID)
+
+	return &user, err
+}
+
 // =====================
 //   GET ROLE NAME
 // =====================
@@ -86,4 +110,63 @@ func (r *AuthRepo) IsTokenRevoked(token string) (bool, error) {
 		)
 	`, token)
 	return exists, err
-}
\ No newline at end of file
+}
+
+// =====================
+//    REFRESH TOKENS
+// =====================
+func (r *AuthRepo) SaveRefreshToken(t *model.RefreshToken) error {
+	_, err := r.DB.Exec(`
+		INSERT INTO refresh_tokens (id, family_id, user_id, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+	`, t.ID, t.FamilyID, t.UserID, t.ExpiresAt)
+	return err
+}
+
+func (r *AuthRepo) FindRefreshToken(id string) (*model.RefreshToken, error) {
+	var t model.RefreshToken
+	err := r.DB.Get(&t, `
+		SELECT id, family_id, user_id, expires_at, used_at, replaced_by, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE id = $1
+	`, id)
+	return &t, err
+}
+
+// RotateRefreshToken menandai token lama sebagai terpakai dan menyimpan
+// penggantinya dalam satu transaksi. Mengembalikan false bila token lama
+// sudah terpakai / dicabut lebih dulu (misalnya request paralel).
+func (r *AuthRepo) RotateRefreshToken(oldID string, next *model.RefreshToken) (bool, error) {
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE refresh_tokens
+		SET used_at = NOW(), replaced_by = $2
+		WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL
+	`, oldID, next.ID)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rows == 0 {
+		return false, nil
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO refresh_tokens (id, family_id, user_id, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+	`, next.ID, next.FamilyID, next.UserID, next.ExpiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
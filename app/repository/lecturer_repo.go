@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"project_uas/app/listing"
 	"project_uas/app/model"
 	"github.com/jmoiron/sqlx"
 )
@@ -16,7 +17,7 @@ func NewLecturerRepo(db *sqlx.DB) *LecturerRepo {
 func (r *LecturerRepo) GetByUserID(userID string) (*model.Lecturer, error) {
 	var lec model.Lecturer
 	q := `
-		SELECT id, user_id, lecturer_id, department, created_at
+		SELECT id, user_id, lecturer_id, department, department_id, created_at
 		FROM lecturers
 		WHERE user_id = $1
 	`
@@ -32,13 +33,41 @@ func (r *LecturerRepo) GetByID(id string) (*model.Lecturer, error) {
 	return &lec, err
 }
 
-func (r *LecturerRepo) GetAll() ([]model.Lecturer, error) {
-	var data []model.Lecturer
-	q := `
-		SELECT id, user_id, lecturer_id, department, created_at
-		FROM lecturers
-	`
-	err := r.DB.Select(&data, q)
-	return data, err
+// LecturerListSpec: sort / filter yang diizinkan untuk GET /lecturers
+var LecturerListSpec = listing.Spec{
+	Sorts: map[string]listing.Field{
+		"created_at":  {Column: "l.created_at", Key: "created_at"},
+		"lecturer_id": {Column: "l.lecturer_id", Key: "lecturer_id"},
+	},
+	DefaultSort: "-created_at",
+	IDColumn:    "l.id",
+	Filters: map[string]listing.Filter{
+		"department":    {Column: "l.department", Op: listing.OpIn},
+		"department_id": {Column: "l.department_id", Op: listing.OpEq},
+		"faculty_id":    {Column: "d.faculty_id", Op: listing.OpEq},
+	},
+	Search: []string{"l.lecturer_id", "u.full_name"},
+}
+
+func (r *LecturerRepo) List(p *listing.Params) (*listing.Page[model.Lecturer], error) {
+	return listing.Fetch[model.Lecturer](r.DB, LecturerListSpec, p, listing.Query{
+		Select: "l.id, l.user_id, l.lecturer_id, l.department, l.department_id, l.created_at",
+		From:   "lecturers l LEFT JOIN users u ON u.id = l.user_id LEFT JOIN departments d ON d.id = l.department_id",
+	})
+}
+
+// UpdateDepartment menautkan dosen ke departemen (kolom teks ikut diisi)
+func (r *LecturerRepo) UpdateDepartment(lecturerID string, departmentID string) (bool, error) {
+	res, err := r.DB.Exec(`
+		UPDATE lecturers l
+		SET department_id = d.id, department = d.name
+		FROM departments d
+		WHERE l.id = $1 AND d.id = $2
+	`, lecturerID, departmentID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
 }
 
@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"project_uas/app/listing"
+	"project_uas/app/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type NotificationRepo struct {
+	DB *sqlx.DB
+}
+
+func NewNotificationRepo(db *sqlx.DB) *NotificationRepo {
+	return &NotificationRepo{DB: db}
+}
+
+// =====================
+// CREATE
+// =====================
+
+// Create menyimpan notifikasi in-app beserta pesan outbox untuk channel
+// eksternal dalam satu transaksi (transactional outbox).
+func (r *NotificationRepo) Create(n *model.Notification, outbox []model.OutboxMessage) error {
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertNotificationTx(tx, n, outbox); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertNotificationTx dipakai Create dan ApplyTransition, yang menyimpan
+// notifikasi di transaksi transisi workflow
+func insertNotificationTx(tx *sqlx.Tx, n *model.Notification, outbox []model.OutboxMessage) error {
+	err := tx.Get(n, `
+		INSERT INTO notifications (user_id, type, title, message, reference_id, is_read, created_at)
+		VALUES ($1, $2, $3, $4, $5, false, NOW())
+		RETURNING id, user_id, type, title, message, reference_id, is_read, read_at, created_at
+	`, n.UserID, n.Type, n.Title, n.Message, n.ReferenceID)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range outbox {
+		_, err = tx.Exec(`
+			INSERT INTO notification_outbox
+			(id, notification_id, channel, recipient, subject, body, status, attempts, next_attempt_at, created_at)
+			VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, 'pending', 0, NOW(), NOW())
+		`, n.ID, m.Channel, m.Recipient, m.Subject, m.Body)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// =====================
+// READ
+// =====================
+
+// NotificationListSpec: ?unread=true, ?type=, terbaru lebih dulu
+var NotificationListSpec = listing.Spec{
+	Sorts: map[string]listing.Field{
+		"created_at": {Column: "created_at", Key: "created_at"},
+	},
+	DefaultSort: "-created_at",
+	IDColumn:    "id",
+	Filters: map[string]listing.Filter{
+		"unread": {Column: "(NOT is_read)", Op: listing.OpBool},
+		"type":   {Column: "type", Op: listing.OpIn},
+	},
+}
+
+func (r *NotificationRepo) List(userID string, p *listing.Params) (*listing.Page[model.Notification], error) {
+	return listing.Fetch[model.Notification](r.DB, NotificationListSpec, p, listing.Query{
+		Select: "id, user_id, type, title, message, reference_id, is_read, read_at, created_at",
+		From:   "notifications",
+		Where:  []string{"user_id = ?"},
+		Args:   []interface{}{userID},
+	})
+}
+
+func (r *NotificationRepo) CountUnread(userID string) (int, error) {
+	var total int
+	err := r.DB.Get(&total, `
+		SELECT COUNT(*) FROM notifications
+		WHERE user_id = $1 AND is_read = false
+	`, userID)
+	return total, err
+}
+
+// =====================
+// UPDATE
+// =====================
+func (r *NotificationRepo) MarkRead(id string, userID string) (bool, error) {
+	res, err := r.DB.Exec(`
+		UPDATE notifications
+		SET is_read = true, read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND user_id = $2
+	`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+func (r *NotificationRepo) MarkAllRead(userID string) (int64, error) {
+	res, err := r.DB.Exec(`
+		UPDATE notifications
+		SET is_read = true, read_at = NOW()
+		WHERE user_id = $1 AND is_read = false
+	`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// =====================
+// DELETE
+// =====================
+func (r *NotificationRepo) Delete(id string, userID string) (bool, error) {
+	res, err := r.DB.Exec(`
+		DELETE FROM notifications WHERE id = $1 AND user_id = $2
+	`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// =====================
+// RECIPIENTS
+// =====================
+func (r *NotificationRepo) GetLecturerUserID(lecturerID string) (string, error) {
+	var userID string
+	err := r.DB.Get(&userID, `SELECT user_id FROM lecturers WHERE id = $1`, lecturerID)
+	return userID, err
+}
+
+func (r *NotificationRepo) GetActiveUserIDsByRole(role string) ([]string, error) {
+	ids := []string{}
+	err := r.DB.Select(&ids, `
+		SELECT u.id
+		FROM users u
+		JOIN roles r ON r.id = u.role_id
+		WHERE r.name = $1 AND u.is_active = true
+	`, role)
+	return ids, err
+}
+
+func (r *NotificationRepo) GetRecipient(userID string) (*model.Recipient, error) {
+	var rc model.Recipient
+	err := r.DB.Get(&rc, `
+		SELECT id, email, full_name, COALESCE(locale, '') AS locale
+		FROM users
+		WHERE id = $1
+	`, userID)
+	return &rc, err
+}
+
+// =====================
+// PREFERENCES
+// =====================
+func (r *NotificationRepo) GetPreferences(userID string) ([]model.NotificationPreference, error) {
+	prefs := []model.NotificationPreference{}
+	err := r.DB.Select(&prefs, `
+		SELECT channel, notification_type, enabled
+		FROM notification_preferences
+		WHERE user_id = $1
+		ORDER BY channel, notification_type
+	`, userID)
+	return prefs, err
+}
+
+// IsChannelEnabled: preferensi spesifik jenis > preferensi "*" > default aktif
+func (r *NotificationRepo) IsChannelEnabled(userID string, channel string, notifType string) (bool, error) {
+	var enabled []bool
+	err := r.DB.Select(&enabled, `
+		SELECT enabled
+		FROM notification_preferences
+		WHERE user_id = $1 AND channel = $2 AND notification_type IN ($3, '*')
+		ORDER BY (notification_type = '*')
+		LIMIT 1
+	`, userID, channel, notifType)
+	if err != nil {
+		return false, err
+	}
+	if len(enabled) == 0 {
+		return true, nil
+	}
+	return enabled[0], nil
+}
+
+func (r *NotificationRepo) SavePreferences(userID string, prefs []model.NotificationPreference) error {
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, p := range prefs {
+		_, err := tx.Exec(`
+			INSERT INTO notification_preferences (user_id, channel, notification_type, enabled, updated_at)
+			VALUES ($1, $2, $3, $4, NOW())
+			ON CONFLICT (user_id, channel, notification_type)
+			DO UPDATE SET enabled = EXCLUDED.enabled, updated_at = NOW()
+		`, userID, p.Channel, p.NotificationType, p.Enabled)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *NotificationRepo) SetLocale(userID string, locale string) error {
+	_, err := r.DB.Exec(`UPDATE users SET locale = $1, updated_at = NOW() WHERE id = $2`, locale, userID)
+	return err
+}
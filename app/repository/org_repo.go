@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"project_uas/app/model"
+)
+
+// ErrUnitInUse: unit masih punya turunan (departemen / program studi)
+var ErrUnitInUse = errors.New("unit still has child units")
+
+type OrgRepo struct {
+	DB *sqlx.DB
+}
+
+func NewOrgRepo(db *sqlx.DB) *OrgRepo {
+	return &OrgRepo{DB: db}
+}
+
+// UnitScopeCondition membatasi kolom ID mahasiswa ke unit-unit scope
+// (fakultas, departemen atau program studi; placeholder "?")
+func UnitScopeCondition(scope []string, studentColumn string) (string, []interface{}) {
+	return studentColumn + ` IN (
+		SELECT su.student_id FROM student_units su
+		WHERE ARRAY[su.study_program_id, su.department_id, su.faculty_id] && ?::uuid[]
+	)`, []interface{}{pq.Array(scope)}
+}
+
+// =====================
+// FACULTIES
+// =====================
+func (r *OrgRepo) ListFaculties() ([]model.Faculty, error) {
+	rows := []model.Faculty{}
+	err := r.DB.Select(&rows, `
+		SELECT id, code, name, created_at, updated_at
+		FROM faculties
+		ORDER BY code
+	`)
+	return rows, err
+}
+
+// GetFaculty beserta departemen & program studinya
+func (r *OrgRepo) GetFaculty(id string) (*model.Faculty, error) {
+	var f model.Faculty
+	err := r.DB.Get(&f, `
+		SELECT id, code, name, created_at, updated_at
+		FROM faculties
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	f.Departments, err = r.ListDepartments(id)
+	if err != nil {
+		return nil, err
+	}
+	for i := range f.Departments {
+		f.Departments[i].StudyPrograms, err = r.ListStudyPrograms(f.Departments[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &f, nil
+}
+
+func (r *OrgRepo) CreateFaculty(f *model.Faculty) error {
+	return r.DB.Get(f, `
+		INSERT INTO faculties (id, code, name, created_at, updated_at)
+		VALUES (gen_random_uuid(), $1, $2, NOW(), NOW())
+		RETURNING id, code, name, created_at, updated_at
+	`, f.Code, f.Name)
+}
+
+func (r *OrgRepo) UpdateFaculty(f *model.Faculty) error {
+	return r.DB.Get(f, `
+		UPDATE faculties
+		SET code = $1, name = $2, updated_at = NOW()
+		WHERE id = $3
+		RETURNING id, code, name, created_at, updated_at
+	`, f.Code, f.Name, f.ID)
+}
+
+func (r *OrgRepo) DeleteFaculty(id string) (bool, error) {
+	return r.deleteUnit("faculties", id)
+}
+
+// =====================
+// DEPARTMENTS
+// =====================
+const departmentColumns = `id, faculty_id, code, name, head_user_id, created_at, updated_at`
+
+// ListDepartments: facultyID kosong = semua fakultas
+func (r *OrgRepo) ListDepartments(facultyID string) ([]model.Department, error) {
+	rows := []model.Department{}
+	err := r.DB.Select(&rows, `
+		SELECT `+departmentColumns+`
+		FROM departments
+		WHERE $1 = '' OR faculty_id::text = $1
+		ORDER BY code
+	`, facultyID)
+	return rows, err
+}
+
+func (r *OrgRepo) GetDepartment(id string) (*model.Department, error) {
+	var d model.Department
+	err := r.DB.Get(&d, `SELECT `+departmentColumns+` FROM departments WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	d.StudyPrograms, err = r.ListStudyPrograms(id)
+	return &d, err
+}
+
+func (r *OrgRepo) CreateDepartment(d *model.Department) error {
+	return r.DB.Get(d, `
+		INSERT INTO departments (id, faculty_id, code, name, head_user_id, created_at, updated_at)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, NOW(), NOW())
+		RETURNING `+departmentColumns,
+		d.FacultyID, d.Code, d.Name, d.HeadUserID)
+}
+
+func (r *OrgRepo) UpdateDepartment(d *model.Department) error {
+	return r.DB.Get(d, `
+		UPDATE departments
+		SET faculty_id = $1, code = $2, name = $3, head_user_id = $4, updated_at = NOW()
+		WHERE id = $5
+		RETURNING `+departmentColumns,
+		d.FacultyID, d.Code, d.Name, d.HeadUserID, d.ID)
+}
+
+func (r *OrgRepo) DeleteDepartment(id string) (bool, error) {
+	return r.deleteUnit("departments", id)
+}
+
+// =====================
+// STUDY PROGRAMS
+// =====================
+const studyProgramColumns = `id, department_id, code, name, degree, created_at, updated_at`
+
+// ListStudyPrograms: departmentID kosong = semua departemen
+func (r *OrgRepo) ListStudyPrograms(departmentID string) ([]model.StudyProgram, error) {
+	rows := []model.StudyProgram{}
+	err := r.DB.Select(&rows, `
+		SELECT `+studyProgramColumns+`
+		FROM study_programs
+		WHERE $1 = '' OR department_id::text = $1
+		ORDER BY code
+	`, departmentID)
+	return rows, err
+}
+
+func (r *OrgRepo) GetStudyProgram(id string) (*model.StudyProgram, error) {
+	var sp model.StudyProgram
+	err := r.DB.Get(&sp, `SELECT `+studyProgramColumns+` FROM study_programs WHERE id = $1`, id)
+	return &sp, err
+}
+
+func (r *OrgRepo) CreateStudyProgram(sp *model.StudyProgram) error {
+	return r.DB.Get(sp, `
+		INSERT INTO study_programs (id, department_id, code, name, degree, created_at, updated_at)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, NOW(), NOW())
+		RETURNING `+studyProgramColumns,
+		sp.DepartmentID, sp.Code, sp.Name, sp.Degree)
+}
+
+func (r *OrgRepo) UpdateStudyProgram(sp *model.StudyProgram) error {
+	return r.DB.Get(sp, `
+		UPDATE study_programs
+		SET department_id = $1, code = $2, name = $3, degree = $4, updated_at = NOW()
+		WHERE id = $5
+		RETURNING `+studyProgramColumns,
+		sp.DepartmentID, sp.Code, sp.Name, sp.Degree, sp.ID)
+}
+
+func (r *OrgRepo) DeleteStudyProgram(id string) (bool, error) {
+	return r.deleteUnit("study_programs", id)
+}
+
+// deleteUnit menghapus unit beserta delegasi admin-nya. Turunan memakai
+// ON DELETE RESTRICT, jadi unit yang masih punya turunan → ErrUnitInUse.
+func (r *OrgRepo) deleteUnit(table string, id string) (bool, error) {
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM unit_admins WHERE unit_id = $1`, id); err != nil {
+		return false, err
+	}
+
+	res, err := tx.Exec(`DELETE FROM `+table+` WHERE id = $1`, id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return false, ErrUnitInUse
+		}
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return false, nil
+	}
+	return true, tx.Commit()
+}
+
+// =====================
+// UNIT ADMINS
+// =====================
+
+// UnitType mencari jenis unit dari ID ("" bila tidak ada)
+func (r *OrgRepo) UnitType(unitID string) (string, error) {
+	types := []string{}
+	err := r.DB.Select(&types, `
+		SELECT 'faculty' FROM faculties WHERE id = $1
+		UNION ALL SELECT 'department' FROM departments WHERE id = $1
+		UNION ALL SELECT 'study_program' FROM study_programs WHERE id = $1
+	`, unitID)
+	if err != nil || len(types) == 0 {
+		return "", err
+	}
+	return types[0], nil
+}
+
+// ListUnitAdmins: userID kosong = semua delegasi
+func (r *OrgRepo) ListUnitAdmins(userID string) ([]model.UnitAdmin, error) {
+	rows := []model.UnitAdmin{}
+	err := r.DB.Select(&rows, `
+		SELECT ua.user_id, u.username, ua.unit_type, ua.unit_id,
+		       COALESCE(f.name, d.name, sp.name, '') AS unit_name,
+		       ua.created_by, ua.created_at
+		FROM unit_admins ua
+		JOIN users u ON u.id = ua.user_id
+		LEFT JOIN faculties f ON f.id = ua.unit_id
+		LEFT JOIN departments d ON d.id = ua.unit_id
+		LEFT JOIN study_programs sp ON sp.id = ua.unit_id
+		WHERE $1 = '' OR ua.user_id::text = $1
+		ORDER BY u.username, ua.unit_type
+	`, userID)
+	return rows, err
+}
+
+func (r *OrgRepo) AddUnitAdmin(ua *model.UnitAdmin) error {
+	_, err := r.DB.Exec(`
+		INSERT INTO unit_admins (user_id, unit_type, unit_id, created_by, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (user_id, unit_id) DO NOTHING
+	`, ua.UserID, ua.UnitType, ua.UnitID, ua.CreatedBy)
+	return err
+}
+
+func (r *OrgRepo) RemoveUnitAdmin(userID string, unitID string) (bool, error) {
+	res, err := r.DB.Exec(`DELETE FROM unit_admins WHERE user_id = $1 AND unit_id = $2`, userID, unitID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
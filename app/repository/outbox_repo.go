@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"time"
+
+	"project_uas/app/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type OutboxRepo struct {
+	DB *sqlx.DB
+}
+
+func NewOutboxRepo(db *sqlx.DB) *OutboxRepo {
+	return &OutboxRepo{DB: db}
+}
+
+// Claim mengambil pesan yang siap dikirim dan menandainya "sending".
+// SKIP LOCKED membuat beberapa instance worker aman berjalan bersamaan;
+// pesan "sending" yang macet (worker mati) diambil lagi setelah lockFor.
+func (r *OutboxRepo) Claim(limit int, lockFor time.Duration) ([]model.OutboxMessage, error) {
+	list := []model.OutboxMessage{}
+	err := r.DB.Select(&list, `
+		UPDATE notification_outbox
+		SET status = 'sending',
+		    attempts = attempts + 1,
+		    next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM notification_outbox
+			WHERE status IN ('pending', 'sending')
+			  AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, notification_id, channel, recipient, subject, body, status,
+		          attempts, last_error, next_attempt_at, created_at, sent_at
+	`, limit, lockFor.Seconds())
+	return list, err
+}
+
+func (r *OutboxRepo) MarkSent(id string) error {
+	_, err := r.DB.Exec(`
+		UPDATE notification_outbox
+		SET status = 'sent', sent_at = NOW(), last_error = NULL
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+// MarkRetry menjadwalkan ulang pesan gagal, atau menandainya "failed"
+// bila sudah mencapai batas percobaan.
+func (r *OutboxRepo) MarkRetry(id string, errMsg string, nextAttempt time.Time, final bool) error {
+	status := model.OutboxPending
+	if final {
+		status = model.OutboxFailed
+	}
+
+	_, err := r.DB.Exec(`
+		UPDATE notification_outbox
+		SET status = $2, last_error = $3, next_attempt_at = $4
+		WHERE id = $1
+	`, id, status, errMsg, nextAttempt)
+	return err
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"project_uas/app/policy"
+)
+
+// PolicyRepo memuat atribut resource untuk policy.Engine
+type PolicyRepo struct {
+	DB *sqlx.DB
+}
+
+func NewPolicyRepo(db *sqlx.DB) *PolicyRepo {
+	return &PolicyRepo{DB: db}
+}
+
+type policyFacts struct {
+	OwnerUserID   sql.NullString `db:"owner_user_id"`
+	AdvisorUserID sql.NullString `db:"advisor_user_id"`
+	HeadUserID    sql.NullString `db:"head_user_id"`
+	Units         pq.StringArray `db:"units"`
+	Status        string         `db:"status"`
+	Stages        pq.StringArray `db:"workflow_stages"`
+	CurrentStage  int            `db:"current_stage"`
+}
+
+// Unit mahasiswa & prestasi diambil dari view student_units; unit dosen
+// dari departemennya.
+var policyFactQueries = map[string]string{
+	policy.KindAchievement: `
+		SELECT s.user_id AS owner_user_id, l.user_id AS advisor_user_id, su.head_user_id,
+		       ARRAY_REMOVE(ARRAY[su.study_program_id, su.department_id, su.faculty_id]::text[], NULL) AS units,
+		       ar.status, COALESCE(ar.workflow_stages, '{}') AS workflow_stages, ar.current_stage
+		FROM achievement_references ar
+		JOIN students s ON s.id = ar.student_id
+		JOIN student_units su ON su.student_id = s.id
+		LEFT JOIN lecturers l ON l.id = s.advisor_id
+		WHERE ar.id = $1`,
+	policy.KindStudent: `
+		SELECT s.user_id AS owner_user_id, l.user_id AS advisor_user_id, su.head_user_id,
+		       ARRAY_REMOVE(ARRAY[su.study_program_id, su.department_id, su.faculty_id]::text[], NULL) AS units,
+		       '' AS status, '{}'::text[] AS workflow_stages, 0 AS current_stage
+		FROM students s
+		JOIN student_units su ON su.student_id = s.id
+		LEFT JOIN lecturers l ON l.id = s.advisor_id
+		WHERE s.id = $1`,
+	policy.KindLecturer: `
+		SELECT l.user_id AS owner_user_id, NULL AS advisor_user_id, d.head_user_id,
+		       ARRAY_REMOVE(ARRAY[d.id, d.faculty_id]::text[], NULL) AS units,
+		       '' AS status, '{}'::text[] AS workflow_stages, 0 AS current_stage
+		FROM lecturers l
+		LEFT JOIN departments d ON d.id = l.department_id
+		WHERE l.id = $1`,
+	policy.KindUser: `
+		SELECT u.id AS owner_user_id, NULL AS advisor_user_id, NULL AS head_user_id,
+		       '{}'::text[] AS units,
+		       '' AS status, '{}'::text[] AS workflow_stages, 0 AS current_stage
+		FROM users u
+		WHERE u.id = $1`,
+	policy.KindDepartment: `
+		SELECT NULL AS owner_user_id, NULL AS advisor_user_id, d.head_user_id,
+		       ARRAY[d.id, d.faculty_id]::text[] AS units,
+		       '' AS status, '{}'::text[] AS workflow_stages, 0 AS current_stage
+		FROM departments d
+		WHERE d.id = $1`,
+	policy.KindProgram: `
+		SELECT NULL AS owner_user_id, NULL AS advisor_user_id, d.head_user_id,
+		       ARRAY[sp.id, d.id, d.faculty_id]::text[] AS units,
+		       '' AS status, '{}'::text[] AS workflow_stages, 0 AS current_stage
+		FROM study_programs sp
+		JOIN departments d ON d.id = sp.department_id
+		WHERE sp.id = $1`,
+}
+
+func (r *PolicyRepo) Facts(res policy.Resource) (*policy.Facts, error) {
+	query, ok := policyFactQueries[res.Kind]
+	if !ok {
+		return nil, fmt.Errorf("policy: unknown resource kind %q", res.Kind)
+	}
+
+	// id bukan UUID pasti tidak ada (hindari error cast di Postgres)
+	if _, err := uuid.Parse(res.ID); err != nil {
+		return nil, policy.ErrNotFound
+	}
+
+	var f policyFacts
+	if err := r.DB.Get(&f, query, res.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, policy.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &policy.Facts{
+		OwnerUserID:          f.OwnerUserID.String,
+		AdvisorUserID:        f.AdvisorUserID.String,
+		DepartmentHeadUserID: f.HeadUserID.String,
+		Units:                f.Units,
+		Status:               f.Status,
+		Stages:               f.Stages,
+		CurrentStage:         f.CurrentStage,
+	}, nil
+}
+
+// SubjectUnits: ID unit yang didelegasikan ke user
+func (r *PolicyRepo) SubjectUnits(userID string) ([]string, error) {
+	units := []string{}
+	err := r.DB.Select(&units, `SELECT unit_id FROM unit_admins WHERE user_id = $1`, userID)
+	return units, err
+}
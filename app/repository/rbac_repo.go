@@ -0,0 +1,372 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"project_uas/app/model"
+)
+
+// RBACChannel: setiap perubahan role / permission / role user dikirim lewat
+// pg_notify di transaksi yang sama, supaya cache permission semua instance
+// langsung dibuang saat commit (lihat package rbac)
+const RBACChannel = "rbac_changed"
+
+var (
+	ErrSystemRole = errors.New("system role cannot be renamed or deleted")
+	ErrRoleInUse  = errors.New("role is still assigned to users")
+	// tanpa pemegang roles:manage tidak ada yang bisa memperbaiki RBAC lagi
+	ErrLastRoleManager = errors.New("at least one role must keep roles:manage")
+	ErrPermissionInUse = errors.New("permission is used by policy rules or system roles")
+)
+
+// RBACChange adalah payload notifikasi; Scope "role" (Key = nama role),
+// "user" (Key = user id) atau "all"
+type RBACChange struct {
+	Scope string `json:"scope"`
+	Key   string `json:"key,omitempty"`
+}
+
+func notifyRBAC(q sqlx.Execer, scope string, key string) error {
+	payload, _ := json.Marshal(RBACChange{Scope: scope, Key: key})
+	_, err := q.Exec(`SELECT pg_notify($1, $2)`, RBACChannel, string(payload))
+	return err
+}
+
+type RBACRepo struct {
+	DB *sqlx.DB
+}
+
+func NewRBACRepo(db *sqlx.DB) *RBACRepo {
+	return &RBACRepo{DB: db}
+}
+
+// =====================
+// LOOKUP (dipakai cache)
+// =====================
+
+// PermissionsForRole mengembalikan "resource:action" milik role (by name)
+func (r *RBACRepo) PermissionsForRole(role string) ([]string, error) {
+	perms := []string{}
+	err := r.DB.Select(&perms, `
+		SELECT p.resource || ':' || p.action
+		FROM permissions p
+		JOIN role_permissions rp ON rp.permission_id = p.id
+		JOIN roles ro ON ro.id = rp.role_id
+		WHERE ro.name = $1
+		ORDER BY 1
+	`, role)
+	return perms, err
+}
+
+func (r *RBACRepo) RoleOfUser(userID string) (string, error) {
+	var role string
+	err := r.DB.Get(&role, `
+		SELECT ro.name
+		FROM users u
+		JOIN roles ro ON ro.id = u.role_id
+		WHERE u.id = $1
+	`, userID)
+	return role, err
+}
+
+// =====================
+// ROLES
+// =====================
+const roleColumns = `ro.id, ro.name, ro.description, ro.is_system, ro.created_at,
+	(SELECT COUNT(*) FROM users u WHERE u.role_id = ro.id) AS user_count`
+
+func (r *RBACRepo) ListRoles() ([]model.Role, error) {
+	roles := []model.Role{}
+	if err := r.DB.Select(&roles, `SELECT `+roleColumns+` FROM roles ro ORDER BY ro.name`); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(roles))
+	for i, ro := range roles {
+		ids[i] = ro.ID
+	}
+
+	rows := []struct {
+		RoleID string `db:"role_id"`
+		model.Permission
+	}{}
+	err := r.DB.Select(&rows, `
+		SELECT rp.role_id, p.id, p.resource, p.action, p.description
+		FROM role_permissions rp
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE rp.role_id = ANY($1)
+		ORDER BY p.resource, p.action
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	byRole := map[string][]model.Permission{}
+	for _, row := range rows {
+		byRole[row.RoleID] = append(byRole[row.RoleID], row.Permission)
+	}
+	for i := range roles {
+		roles[i].Permissions = byRole[roles[i].ID]
+		if roles[i].Permissions == nil {
+			roles[i].Permissions = []model.Permission{}
+		}
+	}
+	return roles, nil
+}
+
+func (r *RBACRepo) GetRole(id string) (*model.Role, error) {
+	var ro model.Role
+	if err := r.DB.Get(&ro, `SELECT `+roleColumns+` FROM roles ro WHERE ro.id = $1`, id); err != nil {
+		return nil, err
+	}
+
+	ro.Permissions = []model.Permission{}
+	err := r.DB.Select(&ro.Permissions, `
+		SELECT p.id, p.resource, p.action, p.description
+		FROM role_permissions rp
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE rp.role_id = $1
+		ORDER BY p.resource, p.action
+	`, id)
+	return &ro, err
+}
+
+func (r *RBACRepo) CreateRole(ro *model.Role) error {
+	return r.DB.Get(ro, `
+		INSERT INTO roles (id, name, description, is_system, created_at)
+		VALUES (gen_random_uuid(), $1, $2, false, NOW())
+		RETURNING id, name, description, is_system, created_at, 0 AS user_count
+	`, ro.Name, ro.Description)
+}
+
+// UpdateRole mengganti nama / deskripsi. Ganti nama mengubah nilai role di
+// token, jadi cache role lama dan semua user dibuang.
+func (r *RBACRepo) UpdateRole(id string, name string, description *string) error {
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var old model.Role
+	if err := tx.Get(&old, `SELECT id, name, is_system FROM roles WHERE id = $1 FOR UPDATE`, id); err != nil {
+		return err
+	}
+	if old.IsSystem && name != old.Name {
+		return ErrSystemRole
+	}
+
+	if _, err := tx.Exec(`UPDATE roles SET name = $2, description = $3 WHERE id = $1`, id, name, description); err != nil {
+		return err
+	}
+	if name != old.Name {
+		if err := notifyRBAC(tx, "all", ""); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *RBACRepo) DeleteRole(id string) error {
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var ro model.Role
+	if err := tx.Get(&ro, `SELECT `+roleColumns+` FROM roles ro WHERE ro.id = $1 FOR UPDATE`, id); err != nil {
+		return err
+	}
+	if ro.IsSystem {
+		return ErrSystemRole
+	}
+	if ro.UserCount > 0 {
+		return ErrRoleInUse
+	}
+
+	if _, err := tx.Exec(`DELETE FROM roles WHERE id = $1`, id); err != nil {
+		return err
+	}
+	if err := ensureRoleManager(tx); err != nil {
+		return err
+	}
+	if err := notifyRBAC(tx, "role", ro.Name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// =====================
+// PERMISSIONS
+// =====================
+func (r *RBACRepo) ListPermissions() ([]model.Permission, error) {
+	list := []model.Permission{}
+	err := r.DB.Select(&list, `
+		SELECT id, resource, action, description
+		FROM permissions
+		ORDER BY resource, action
+	`)
+	return list, err
+}
+
+func (r *RBACRepo) CreatePermission(p *model.Permission) error {
+	return r.DB.Get(p, `
+		INSERT INTO permissions (id, resource, action, description)
+		VALUES (gen_random_uuid(), $1, $2, $3)
+		RETURNING id, resource, action, description
+	`, p.Resource, p.Action, p.Description)
+}
+
+// DeletePermission juga mencabutnya dari semua role (FK cascade).
+// Permission yang ada di protected (dirujuk policy.Rules) atau dipegang
+// role system ditolak dengan ErrPermissionInUse.
+func (r *RBACRepo) DeletePermission(id string, protected []string) (bool, error) {
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var p struct {
+		Name   string `db:"name"`
+		System bool   `db:"system"`
+	}
+	err = tx.Get(&p, `
+		SELECT p.resource || ':' || p.action AS name,
+		       EXISTS (
+		           SELECT 1 FROM role_permissions rp
+		           JOIN roles ro ON ro.id = rp.role_id
+		           WHERE rp.permission_id = p.id AND ro.is_system
+		       ) AS system
+		FROM permissions p
+		WHERE p.id = $1
+		FOR UPDATE OF p
+	`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if p.System {
+		return false, ErrPermissionInUse
+	}
+	for _, name := range protected {
+		if name == p.Name {
+			return false, ErrPermissionInUse
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM permissions WHERE id = $1`, id); err != nil {
+		return false, err
+	}
+	if err := notifyRBAC(tx, "all", ""); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// =====================
+// ASSIGNMENTS
+// =====================
+
+// SetRolePermissions mengganti seluruh permission role
+func (r *RBACRepo) SetRolePermissions(roleID string, permissionIDs []string) error {
+	return r.changeAssignments(roleID, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM role_permissions WHERE role_id = $1`, roleID); err != nil {
+			return err
+		}
+		return insertAssignments(tx, roleID, permissionIDs)
+	})
+}
+
+func (r *RBACRepo) AddRolePermissions(roleID string, permissionIDs []string) error {
+	return r.changeAssignments(roleID, func(tx *sqlx.Tx) error {
+		return insertAssignments(tx, roleID, permissionIDs)
+	})
+}
+
+func (r *RBACRepo) RemoveRolePermission(roleID string, permissionID string) error {
+	return r.changeAssignments(roleID, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`DELETE FROM role_permissions WHERE role_id = $1 AND permission_id = $2`, roleID, permissionID)
+		return err
+	})
+}
+
+func insertAssignments(tx *sqlx.Tx, roleID string, permissionIDs []string) error {
+	if len(permissionIDs) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(`
+		INSERT INTO role_permissions (id, role_id, permission_id)
+		SELECT gen_random_uuid(), $1, p.id
+		FROM permissions p
+		WHERE p.id = ANY($2)
+		ON CONFLICT (role_id, permission_id) DO NOTHING
+	`, roleID, pq.Array(permissionIDs))
+	return err
+}
+
+func (r *RBACRepo) changeAssignments(roleID string, fn func(tx *sqlx.Tx) error) error {
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var name string
+	if err := tx.Get(&name, `SELECT name FROM roles WHERE id = $1 FOR UPDATE`, roleID); err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := ensureRoleManager(tx); err != nil {
+		return err
+	}
+	if err := notifyRBAC(tx, "role", name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UnknownPermissions mengembalikan id yang tidak ada di tabel permissions
+func (r *RBACRepo) UnknownPermissions(ids []string) ([]string, error) {
+	missing := []string{}
+	err := r.DB.Select(&missing, `
+		SELECT u.pid FROM unnest($1::text[]) AS u(pid)
+		WHERE NOT EXISTS (SELECT 1 FROM permissions p WHERE p.id::text = u.pid)
+	`, pq.Array(ids))
+	return missing, err
+}
+
+// ensureRoleManager menolak perubahan yang menyisakan nol role pemegang
+// roles:manage. Baris permission dikunci dulu supaya dua perubahan paralel
+// (mis. mencabut dari dua role berbeda) tidak sama-sama lolos pengecekan.
+func ensureRoleManager(tx *sqlx.Tx) error {
+	var ids []string
+	err := tx.Select(&ids, `
+		SELECT id FROM permissions
+		WHERE resource = 'roles' AND action = 'manage'
+		FOR UPDATE
+	`)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	var held bool
+	err = tx.Get(&held, `SELECT EXISTS (SELECT 1 FROM role_permissions WHERE permission_id = ANY($1))`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	if !held {
+		return ErrLastRoleManager
+	}
+	return nil
+}
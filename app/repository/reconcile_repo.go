@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"project_uas/app/model"
+)
+
+// ReconcileRepo membaca kedua store secara utuh untuk audit integritas
+type ReconcileRepo struct {
+	Psql  *sqlx.DB
+	Mongo *mongo.Database
+}
+
+func NewReconcileRepo(psql *sqlx.DB, mongoDB *mongo.Database) *ReconcileRepo {
+	return &ReconcileRepo{Psql: psql, Mongo: mongoDB}
+}
+
+func (r *ReconcileRepo) References() ([]model.ReferenceState, error) {
+	list := []model.ReferenceState{}
+	err := r.Psql.Select(&list, `
+		SELECT id, COALESCE(mongo_achievement_id, '') AS mongo_achievement_id, status
+		FROM achievement_references
+		ORDER BY created_at
+	`)
+	return list, err
+}
+
+// Documents mengembalikan status soft-delete semua dokumen, key = hex ID
+func (r *ReconcileRepo) Documents(ctx context.Context) (map[string]model.DocumentState, error) {
+	opts := options.Find().SetProjection(bson.M{"_id": 1, "deleted_at": 1, "created_at": 1})
+	cur, err := r.Mongo.Collection("achievements").Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	docs := map[string]model.DocumentState{}
+	for cur.Next(ctx) {
+		var row struct {
+			ID        primitive.ObjectID `bson:"_id"`
+			DeletedAt *time.Time         `bson:"deleted_at"`
+			CreatedAt time.Time          `bson:"created_at"`
+		}
+		if err := cur.Decode(&row); err != nil {
+			return nil, err
+		}
+		docs[row.ID.Hex()] = model.DocumentState{
+			ID:        row.ID.Hex(),
+			Deleted:   row.DeletedAt != nil,
+			CreatedAt: row.CreatedAt,
+		}
+	}
+	return docs, cur.Err()
+}
+
+// OpenSagaDocuments: dokumen yang sedang diproses saga tidak boleh disentuh
+func (r *ReconcileRepo) OpenSagaDocuments() (map[string]bool, error) {
+	ids := []string{}
+	err := r.Psql.Select(&ids, `
+		SELECT mongo_id FROM achievement_sagas
+		WHERE state IN ('started', 'compensating')
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	set := map[string]bool{}
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set, nil
+}
+
+func (r *ReconcileRepo) FailedSagas() ([]model.Saga, error) {
+	list := []model.Saga{}
+	err := r.Psql.Select(&list, `
+		SELECT `+sagaColumns+`
+		FROM achievement_sagas
+		WHERE state = 'failed'
+		ORDER BY created_at
+	`)
+	return list, err
+}
+
+// Attachments: lampiran yang seharusnya punya file (bukan yang infected)
+func (r *ReconcileRepo) Attachments() ([]model.AchievementAttachment, error) {
+	list := []model.AchievementAttachment{}
+	err := r.Psql.Select(&list, `
+		SELECT `+attachmentColumns+`
+		FROM achievement_attachments
+		WHERE storage_key IS NOT NULL
+		ORDER BY uploaded_at
+	`)
+	return list, err
+}
+
+// ResetPreview menjadwalkan ulang pembuatan preview yang file-nya hilang
+func (r *ReconcileRepo) ResetPreview(id string) error {
+	_, err := r.Psql.Exec(`
+		UPDATE achievement_attachments
+		SET preview_status = 'pending', preview_key = NULL, preview_error = NULL,
+		    preview_attempts = 0, preview_next_at = NOW()
+		WHERE id = $1 AND preview_status = 'ready'
+	`, id)
+	return err
+}
@@ -1,7 +1,12 @@
 package repository
 
 import (
+	"fmt"
+
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"project_uas/app/model"
 )
 
 type ReportRepo struct {
@@ -12,12 +17,25 @@ func NewReportRepo(db *sqlx.DB) *ReportRepo {
 	return &ReportRepo{DB: db}
 }
 
-func (r *ReportRepo) CountAchievementsByStatus() (map[string]int, error) {
-	rows, err := r.DB.Queryx(`
+// CountAchievementsByStatus: scope = ID unit admin (nil = semua)
+func (r *ReportRepo) CountAchievementsByStatus(scope []string) (map[string]int, error) {
+	query, args := `
 		SELECT status, COUNT(*) 
 		FROM achievement_references
 		GROUP BY status
-	`)
+	`, []interface{}{}
+	if scope != nil {
+		cond, scopeArgs := UnitScopeCondition(scope, "student_id")
+		query = `
+		SELECT status, COUNT(*)
+		FROM achievement_references
+		WHERE ` + cond + `
+		GROUP BY status
+	`
+		args = scopeArgs
+	}
+
+	rows, err := r.DB.Queryx(r.DB.Rebind(query), args...)
 	if err != nil {
 		return nil, err
 	}
@@ -55,3 +73,68 @@ func (r *ReportRepo) GetStudentAchievementReport(studentID string) (map[string]i
 
 	return result, nil
 }
+
+// unitLevels: kolom student_units & tabel nama untuk tiap level laporan
+var unitLevels = map[string][2]string{
+	model.UnitFaculty:      {"faculty_id", "faculties"},
+	model.UnitDepartment:   {"department_id", "departments"},
+	model.UnitStudyProgram: {"study_program_id", "study_programs"},
+}
+
+// CountAchievementsByUnit: jumlah prestasi per status untuk tiap unit di
+// level tertentu; prestasi mahasiswa yang belum punya unit tidak dihitung.
+// scope = ID unit admin (nil = semua unit, termasuk yang belum punya
+// mahasiswa dengan total 0).
+func (r *ReportRepo) CountAchievementsByUnit(level string, scope []string) ([]model.UnitReport, error) {
+	cols, ok := unitLevels[level]
+	if !ok {
+		return nil, fmt.Errorf("unknown unit level %q", level)
+	}
+	column, table := cols[0], cols[1]
+
+	var scopeArg interface{}
+	if scope != nil {
+		scopeArg = pq.Array(scope)
+	}
+
+	rows := []struct {
+		UnitID string  `db:"unit_id"`
+		Code   string  `db:"code"`
+		Name   string  `db:"name"`
+		Status *string `db:"status"`
+		Total  int     `db:"total"`
+	}{}
+	err := r.DB.Select(&rows, `
+		SELECT u.id AS unit_id, u.code, u.name, ar.status, COUNT(ar.id) AS total
+		FROM `+table+` u
+		LEFT JOIN student_units su ON su.`+column+` = u.id
+		LEFT JOIN achievement_references ar ON ar.student_id = su.student_id AND ar.status <> 'deleted'
+		WHERE $1::uuid[] IS NULL OR su.student_id IN (
+			SELECT x.student_id FROM student_units x
+			WHERE ARRAY[x.study_program_id, x.department_id, x.faculty_id] && $1::uuid[]
+		)
+		GROUP BY u.id, u.code, u.name, ar.status
+		ORDER BY u.code
+	`, scopeArg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []model.UnitReport{}
+	index := map[string]int{}
+	for _, row := range rows {
+		i, seen := index[row.UnitID]
+		if !seen {
+			i = len(result)
+			index[row.UnitID] = i
+			result = append(result, model.UnitReport{
+				UnitID: row.UnitID, Code: row.Code, Name: row.Name, Statuses: map[string]int{},
+			})
+		}
+		if row.Status != nil {
+			result[i].Statuses[*row.Status] += row.Total
+			result[i].Total += row.Total
+		}
+	}
+	return result, nil
+}
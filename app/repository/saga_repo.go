@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"project_uas/app/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrSagaClosed: saga sudah diambil alih (dikompensasi) sebelum sempat
+// diselesaikan, jadi langkah maju harus dibatalkan
+var ErrSagaClosed = errors.New("saga is no longer running")
+
+type SagaRepo struct {
+	DB *sqlx.DB
+}
+
+func NewSagaRepo(db *sqlx.DB) *SagaRepo {
+	return &SagaRepo{DB: db}
+}
+
+const sagaColumns = `id, kind, ref_id, mongo_id, state, attempts, last_error,
+	next_attempt_at, created_at, updated_at`
+
+// Start mencatat saga; recoverer baru menyentuhnya setelah staleAfter
+func (r *SagaRepo) Start(s *model.Saga, staleAfter time.Duration) error {
+	return r.DB.Get(s, `
+		INSERT INTO achievement_sagas
+		(id, kind, ref_id, mongo_id, state, attempts, next_attempt_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, 'started', 0, NOW() + make_interval(secs => $5), NOW(), NOW())
+		RETURNING `+sagaColumns,
+		s.ID, s.Kind, s.RefID, s.MongoID, staleAfter.Seconds())
+}
+
+// completeSaga dipanggil di dalam transaksi langkah terakhir (Postgres).
+// Hanya berhasil bila saga masih "started".
+func completeSaga(tx *sqlx.Tx, id string) error {
+	res, err := tx.Exec(`
+		UPDATE achievement_sagas
+		SET state = 'completed', updated_at = NOW()
+		WHERE id = $1 AND state = 'started'
+	`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrSagaClosed
+	}
+	return nil
+}
+
+// MarkCompleted dipakai recoverer bila ternyata langkah maju sudah selesai
+func (r *SagaRepo) MarkCompleted(id string) error {
+	_, err := r.DB.Exec(`
+		UPDATE achievement_sagas
+		SET state = 'completed', updated_at = NOW()
+		WHERE id = $1 AND state = 'started'
+	`, id)
+	return err
+}
+
+// BeginCompensation memindahkan saga started → compensating.
+// false bila saga sudah selesai / ditangani proses lain.
+func (r *SagaRepo) BeginCompensation(id string, cause string) (bool, error) {
+	res, err := r.DB.Exec(`
+		UPDATE achievement_sagas
+		SET state = 'compensating', last_error = $2, updated_at = NOW()
+		WHERE id = $1 AND state = 'started'
+	`, id, cause)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+func (r *SagaRepo) MarkCompensated(id string) error {
+	_, err := r.DB.Exec(`
+		UPDATE achievement_sagas
+		SET state = 'compensated', updated_at = NOW()
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+// MarkRetry menjadwalkan ulang kompensasi, atau "failed" bila menyerah
+func (r *SagaRepo) MarkRetry(id string, errMsg string, next time.Time, final bool) error {
+	state := model.SagaCompensating
+	if final {
+		state = model.SagaFailed
+	}
+
+	_, err := r.DB.Exec(`
+		UPDATE achievement_sagas
+		SET state = $2, last_error = $3, next_attempt_at = $4, updated_at = NOW()
+		WHERE id = $1
+	`, id, state, errMsg, next)
+	return err
+}
+
+// Claim mengambil saga yang macet (started melewati batas waktu) atau
+// yang kompensasinya perlu dicoba lagi. Aman untuk banyak instance.
+func (r *SagaRepo) Claim(limit int, lockFor time.Duration) ([]model.Saga, error) {
+	list := []model.Saga{}
+	err := r.DB.Select(&list, `
+		UPDATE achievement_sagas
+		SET attempts = attempts + 1,
+		    next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM achievement_sagas
+			WHERE state IN ('started', 'compensating')
+			  AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+sagaColumns,
+		limit, lockFor.Seconds())
+	return list, err
+}
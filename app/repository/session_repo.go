@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"project_uas/app/listing"
+	"project_uas/app/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type SessionRepo struct {
+	DB *sqlx.DB
+}
+
+func NewSessionRepo(db *sqlx.DB) *SessionRepo {
+	return &SessionRepo{DB: db}
+}
+
+// =====================
+// CREATE
+// =====================
+func (r *SessionRepo) Create(s *model.Session) error {
+	_, err := r.DB.Exec(`
+		INSERT INTO sessions (id, user_id, device, ip_address, user_agent, created_at, last_seen_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+	`, s.ID, s.UserID, s.Device, s.IPAddress, s.UserAgent)
+	return err
+}
+
+// =====================
+// READ
+// =====================
+func (r *SessionRepo) GetByID(id string) (*model.Session, error) {
+	var s model.Session
+	err := r.DB.Get(&s, `
+		SELECT id, user_id, device, ip_address, user_agent, created_at, last_seen_at, revoked_at
+		FROM sessions
+		WHERE id = $1
+	`, id)
+	return &s, err
+}
+
+// SessionListSpec: session aktif, terakhir dipakai lebih dulu
+var SessionListSpec = listing.Spec{
+	Sorts: map[string]listing.Field{
+		"last_seen_at": {Column: "last_seen_at", Key: "last_seen_at"},
+		"created_at":   {Column: "created_at", Key: "created_at"},
+	},
+	DefaultSort: "-last_seen_at",
+	IDColumn:    "id",
+}
+
+func (r *SessionRepo) ListActiveByUser(userID string, p *listing.Params) (*listing.Page[model.Session], error) {
+	return listing.Fetch[model.Session](r.DB, SessionListSpec, p, listing.Query{
+		Select: "id, user_id, device, ip_address, user_agent, created_at, last_seen_at, revoked_at",
+		From:   "sessions",
+		Where:  []string{"user_id = ?", "revoked_at IS NULL"},
+		Args:   []interface{}{userID},
+	})
+}
+
+func (r *SessionRepo) IsActive(id string) (bool, error) {
+	var active bool
+	err := r.DB.Get(&active, `
+		SELECT EXISTS (
+			SELECT 1 FROM sessions WHERE id = $1 AND revoked_at IS NULL
+		)
+	`, id)
+	return active, err
+}
+
+// Touch memperbarui last_seen_at paling sering sekali per menit
+// supaya tidak menulis ke DB di setiap request.
+func (r *SessionRepo) Touch(id string, ip string) error {
+	_, err := r.DB.Exec(`
+		UPDATE sessions
+		SET last_seen_at = NOW(), ip_address = $2
+		WHERE id = $1
+		  AND revoked_at IS NULL
+		  AND last_seen_at < NOW() - INTERVAL '1 minute'
+	`, id, ip)
+	return err
+}
+
+// =====================
+// REVOKE
+// =====================
+
+// Revoke mencabut satu session beserta seluruh refresh token family-nya
+func (r *SessionRepo) Revoke(id string) error {
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE family_id = $1 AND revoked_at IS NULL
+	`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RevokeAllForUser mencabut semua session user kecuali exceptID (boleh kosong)
+func (r *SessionRepo) RevokeAllForUser(userID string, exceptID string) (int64, error) {
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE user_id = $1 AND id::text <> $2 AND revoked_at IS NULL
+	`, userID, exceptID)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE user_id = $1 AND family_id::text <> $2 AND revoked_at IS NULL
+	`, userID, exceptID); err != nil {
+		return 0, err
+	}
+
+	n, _ := res.RowsAffected()
+	return n, tx.Commit()
+}
@@ -1,6 +1,9 @@
 package repository
 
 import (
+    "database/sql"
+
+    "project_uas/app/listing"
     "project_uas/app/model"
     "github.com/jmoiron/sqlx"
 )
@@ -23,6 +26,7 @@ func (r *StudentRepo) FindByUserID(userID string) (*model.Student, error) {
             user_id,
             student_id,
             program_study,
+            study_program_id,
             academic_year,
             advisor_id,
             created_at
@@ -46,6 +50,7 @@ func (r *StudentRepo) GetByID(id string) (*model.Student, error) {
             user_id,
             student_id,
             program_study,
+            study_program_id,
             academic_year,
             advisor_id,
             created_at
@@ -60,22 +65,40 @@ func (r *StudentRepo) GetByID(id string) (*model.Student, error) {
     return &s, nil
 }
 
-func (r *StudentRepo) GetAll() ([]model.Student, error) {
-    var data []model.Student
-
-    err := r.DB.Select(&data, `
-        SELECT
-            id,
-            user_id,
-            student_id,
-            program_study,
-            academic_year,
-            advisor_id,
-            created_at
-        FROM students
-    `)
+// StudentListSpec: sort / filter yang diizinkan untuk GET /students
+var StudentListSpec = listing.Spec{
+	Sorts: map[string]listing.Field{
+		"created_at":    {Column: "s.created_at", Key: "created_at"},
+		"student_id":    {Column: "s.student_id", Key: "student_id"},
+		// academic_year boleh NULL; keyset butuh nilai NOT NULL
+		"academic_year": {Column: "COALESCE(s.academic_year, '')", Key: "academic_year"},
+	},
+	DefaultSort: "-created_at",
+	IDColumn:    "s.id",
+	Filters: map[string]listing.Filter{
+		"program_study": {Column: "s.program_study", Op: listing.OpIn},
+		"academic_year": {Column: "s.academic_year", Op: listing.OpIn},
+		"advisor_id":    {Column: "s.advisor_id", Op: listing.OpEq},
+		// unit organisasi (lihat view student_units)
+		"study_program_id": {Column: "su.study_program_id", Op: listing.OpEq},
+		"department_id":    {Column: "su.department_id", Op: listing.OpEq},
+		"faculty_id":       {Column: "su.faculty_id", Op: listing.OpEq},
+	},
+	Search: []string{"s.student_id", "u.full_name"},
+}
 
-    return data, err
+// List: scope berisi ID unit admin (nil = tanpa batas unit)
+func (r *StudentRepo) List(p *listing.Params, scope []string) (*listing.Page[model.Student], error) {
+	q := listing.Query{
+		Select: "s.id, s.user_id, s.student_id, s.program_study, s.study_program_id, COALESCE(s.academic_year, '') AS academic_year, s.advisor_id, s.created_at",
+		From:   "students s LEFT JOIN users u ON u.id = s.user_id JOIN student_units su ON su.student_id = s.id",
+	}
+	if scope != nil {
+		cond, args := UnitScopeCondition(scope, "s.id")
+		q.Where = append(q.Where, cond)
+		q.Args = append(q.Args, args...)
+	}
+	return listing.Fetch[model.Student](r.DB, StudentListSpec, p, q)
 }
 
 // GetStudentIDsByAdvisor
@@ -93,47 +116,42 @@ func (r *StudentRepo) GetStudentIDsByAdvisor(advisorID string) ([]string, error)
 	return ids, err
 }
 
-func (r *StudentRepo) GetLecturerIDByUserID(userID string) (string, error) {
-	var lecturerID string
-	err := r.DB.Get(&lecturerID, `
-		SELECT id FROM lecturers WHERE user_id = $1
-	`, userID)
-	return lecturerID, err
+// GetDepartmentHead: user kepala departemen mahasiswa ("" bila belum ada)
+func (r *StudentRepo) GetDepartmentHead(studentID string) (string, error) {
+	var head []sql.NullString
+	err := r.DB.Select(&head, `
+		SELECT head_user_id FROM student_units WHERE student_id = $1
+	`, studentID)
+	if err != nil || len(head) == 0 {
+		return "", err
+	}
+	return head[0].String, nil
 }
 
-
 // =====================
-// GET STUDENT ACHIEVEMENTS
+// UNIT ORGANISASI
 // =====================
-func (r *StudentRepo) GetAchievementsByStudentID(studentID string) ([]map[string]interface{}, error) {
-	var data []map[string]interface{}
 
-	query := `
-		SELECT
-			id,
-			title,
-			category,
-			level,
-			status,
-			created_at
-		FROM achievements
-		WHERE student_id = $1
-		ORDER BY created_at DESC
-	`
-
-	err := r.DB.Select(&data, query, studentID)
-	return data, err
+// UpdateStudyProgram menautkan mahasiswa ke program studi; kolom teks
+// program_study ikut diisi nama program supaya tampilan lama konsisten
+func (r *StudentRepo) UpdateStudyProgram(studentID string, programID string) (bool, error) {
+	res, err := r.DB.Exec(`
+		UPDATE students s
+		SET study_program_id = sp.id, program_study = sp.name
+		FROM study_programs sp
+		WHERE s.id = $1 AND sp.id = $2
+	`, studentID, programID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
 }
 
-// =====================
-// UPDATE ADVISOR
-// =====================
-func (r *StudentRepo) UpdateAdvisor(studentID string, advisorID string) error {
-	_, err := r.DB.Exec(`
-		UPDATE students
-		SET advisor_id = $1
-		WHERE id = $2
-	`, advisorID, studentID)
-
-	return err
+func (r *StudentRepo) GetLecturerIDByUserID(userID string) (string, error) {
+	var lecturerID string
+	err := r.DB.Get(&lecturerID, `
+		SELECT id FROM lecturers WHERE user_id = $1
+	`, userID)
+	return lecturerID, err
 }
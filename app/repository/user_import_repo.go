@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"project_uas/app/model"
+)
+
+type UserImportRepo struct {
+	DB *sqlx.DB
+}
+
+func NewUserImportRepo(db *sqlx.DB) *UserImportRepo {
+	return &UserImportRepo{DB: db}
+}
+
+// ImportLookups adalah data pembanding untuk validasi seluruh file,
+// dimuat sekali per impor (kunci dalam huruf kecil)
+type ImportLookups struct {
+	Usernames   map[string]bool
+	Emails      map[string]bool
+	StudentIDs  map[string]bool
+	LecturerIDs map[string]string // lecturer_id → lecturers.id
+	Programs    map[string]model.StudyProgram
+	Departments map[string]model.Department
+	Roles       map[string]string // nama role → id
+}
+
+// Lookups memuat nilai yang sudah ada di database untuk kunci-kunci file
+func (r *UserImportRepo) Lookups(usernames, emails, studentIDs, lecturerIDs []string) (*ImportLookups, error) {
+	l := &ImportLookups{
+		Usernames:   map[string]bool{},
+		Emails:      map[string]bool{},
+		StudentIDs:  map[string]bool{},
+		LecturerIDs: map[string]string{},
+		Programs:    map[string]model.StudyProgram{},
+		Departments: map[string]model.Department{},
+		Roles:       map[string]string{},
+	}
+
+	sets := []struct {
+		into  map[string]bool
+		query string
+		keys  []string
+	}{
+		{l.Usernames, `SELECT LOWER(username) FROM users WHERE LOWER(username) = ANY($1)`, usernames},
+		{l.Emails, `SELECT LOWER(email) FROM users WHERE LOWER(email) = ANY($1)`, emails},
+		{l.StudentIDs, `SELECT LOWER(student_id) FROM students WHERE LOWER(student_id) = ANY($1)`, studentIDs},
+	}
+	for _, set := range sets {
+		found := []string{}
+		if err := r.DB.Select(&found, set.query, pq.Array(lowerAll(set.keys))); err != nil {
+			return nil, err
+		}
+		for _, k := range found {
+			set.into[k] = true
+		}
+	}
+
+	lecturers := []struct {
+		ID         string `db:"id"`
+		LecturerID string `db:"lecturer_id"`
+	}{}
+	if err := r.DB.Select(&lecturers, `
+		SELECT id, lecturer_id FROM lecturers WHERE LOWER(lecturer_id) = ANY($1)
+	`, pq.Array(lowerAll(lecturerIDs))); err != nil {
+		return nil, err
+	}
+	for _, lec := range lecturers {
+		l.LecturerIDs[strings.ToLower(lec.LecturerID)] = lec.ID
+	}
+
+	programs := []model.StudyProgram{}
+	if err := r.DB.Select(&programs, `SELECT `+studyProgramColumns+` FROM study_programs`); err != nil {
+		return nil, err
+	}
+	for _, sp := range programs {
+		l.Programs[strings.ToLower(sp.Code)] = sp
+		l.Programs[strings.ToLower(sp.Name)] = sp
+	}
+
+	departments := []model.Department{}
+	if err := r.DB.Select(&departments, `SELECT `+departmentColumns+` FROM departments`); err != nil {
+		return nil, err
+	}
+	for _, d := range departments {
+		l.Departments[strings.ToLower(d.Code)] = d
+		l.Departments[strings.ToLower(d.Name)] = d
+	}
+
+	roles := []struct {
+		ID   string `db:"id"`
+		Name string `db:"name"`
+	}{}
+	if err := r.DB.Select(&roles, `SELECT id, name FROM roles`); err != nil {
+		return nil, err
+	}
+	for _, ro := range roles {
+		l.Roles[ro.Name] = ro.ID
+	}
+
+	return l, nil
+}
+
+func lowerAll(keys []string) []string {
+	out := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k != "" {
+			out = append(out, strings.ToLower(k))
+		}
+	}
+	return out
+}
+
+// ImportedUser adalah baris yang sudah divalidasi & siap di-insert
+type ImportedUser struct {
+	User         model.User
+	Role         string
+	StudentID    string
+	ProgramStudy string
+	ProgramID    *string
+	AcademicYear string
+	AdvisorID    *string
+	LecturerID   string
+	Department   string
+	DepartmentID *string
+	CreatedBy    *string // user yang menjalankan impor (riwayat advisor)
+}
+
+// InsertTx membuat user beserta baris mahasiswa / dosennya di tx
+func (r *UserImportRepo) InsertTx(tx *sqlx.Tx, u *ImportedUser) error {
+	_, err := tx.NamedExec(`
+		INSERT INTO users
+		(id, username, email, password_hash, full_name, role_id, is_active)
+		VALUES
+		(:id, :username, :email, :password_hash, :full_name, :role_id, :is_active)
+	`, u.User)
+	if err != nil {
+		return err
+	}
+
+	switch u.Role {
+	case "student":
+		var studentID string
+		err = tx.Get(&studentID, `
+			INSERT INTO students
+			(id, user_id, student_id, program_study, study_program_id, academic_year, advisor_id)
+			VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6)
+			RETURNING id
+		`, u.User.ID, u.StudentID, u.ProgramStudy, u.ProgramID, u.AcademicYear, u.AdvisorID)
+		if err == nil && u.AdvisorID != nil {
+			err = RecordAdvisorTx(tx, studentID, *u.AdvisorID, nil, model.AssignImport, u.CreatedBy)
+		}
+	case "lecturer":
+		_, err = tx.Exec(`
+			INSERT INTO lecturers
+			(id, user_id, lecturer_id, department, department_id)
+			VALUES (gen_random_uuid(), $1, $2, $3, $4)
+		`, u.User.ID, u.LecturerID, u.Department, u.DepartmentID)
+	}
+	return err
+}
+
+// =====================
+// RIWAYAT IMPOR
+// =====================
+const userImportColumns = `id, filename, dry_run, total, created, invalid, failed, created_by, created_at`
+
+func (r *UserImportRepo) Save(imp *model.UserImport) error {
+	rows, err := json.Marshal(imp.Rows)
+	if err != nil {
+		return err
+	}
+	return r.DB.Get(imp, `
+		INSERT INTO user_imports (id, filename, dry_run, total, created, invalid, failed, rows, created_by, created_at)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		RETURNING `+userImportColumns,
+		imp.Filename, imp.DryRun, imp.Total, imp.Created, imp.Invalid, imp.Failed, rows, imp.CreatedBy)
+}
+
+// Get beserta hasil per baris
+func (r *UserImportRepo) Get(id string) (*model.UserImport, error) {
+	var row struct {
+		model.UserImport
+		Rows []byte `db:"rows"`
+	}
+	err := r.DB.Get(&row, `SELECT `+userImportColumns+`, rows FROM user_imports WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := row.UserImport
+	if err := json.Unmarshal(row.Rows, &imp.Rows); err != nil {
+		return nil, err
+	}
+	return &imp, nil
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"project_uas/app/listing"
 	"project_uas/app/model"
 
 	"github.com/jmoiron/sqlx"
@@ -17,14 +18,27 @@ func NewUserRepo(db *sqlx.DB) *UserRepo {
 // =====================
 // READ
 // =====================
-func (r *UserRepo) GetAll() ([]model.User, error) {
-	var users []model.User
-	err := r.DB.Select(&users, `
-		SELECT id, username, email, full_name, role_id, is_active, created_at, updated_at
-		FROM users
-		ORDER BY created_at DESC
-	`)
-	return users, err
+// UserListSpec: sort / filter yang diizinkan untuk GET /users
+var UserListSpec = listing.Spec{
+	Sorts: map[string]listing.Field{
+		"created_at": {Column: "u.created_at", Key: "created_at"},
+		"username":   {Column: "u.username", Key: "username"},
+		"full_name":  {Column: "u.full_name", Key: "full_name"},
+	},
+	DefaultSort: "-created_at",
+	IDColumn:    "u.id",
+	Filters: map[string]listing.Filter{
+		"role":      {Column: "r.name", Op: listing.OpIn},
+		"is_active": {Column: "u.is_active", Op: listing.OpBool},
+	},
+	Search: []string{"u.username", "u.email", "u.full_name"},
+}
+
+func (r *UserRepo) List(p *listing.Params) (*listing.Page[model.User], error) {
+	return listing.Fetch[model.User](r.DB, UserListSpec, p, listing.Query{
+		Select: "u.id, u.username, u.email, u.full_name, u.role_id, u.is_active, u.created_at, u.updated_at",
+		From:   "users u LEFT JOIN roles r ON r.id = u.role_id",
+	})
 }
 
 func (r *UserRepo) GetByID(id string) (*model.User, error) {
@@ -84,16 +98,43 @@ func (r *UserRepo) Delete(id string) error {
 // =====================
 // UPDATE ROLE
 // =====================
-func (r *UserRepo) UpdateRole(id string, roleID string) error {
-	_, err := r.DB.Exec(`
+// UpdateRole juga memberi tahu cache RBAC supaya role baru berlaku
+// tanpa user harus login ulang
+func (r *UserRepo) UpdateRole(id string, roleID string) (bool, error) {
+	tx, err := r.DB.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
 		UPDATE users
 		SET role_id=$1, updated_at=NOW()
 		WHERE id=$2
 	`, roleID, id)
-	return err
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	if err := notifyRBAC(tx, "user", id); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
 }
 
 
+// GetRoleName: nama role user saat ini
+func (r *UserRepo) GetRoleName(userID string) (string, error) {
+	var name string
+	err := r.DB.Get(&name, `
+		SELECT r.name FROM users u JOIN roles r ON r.id = u.role_id WHERE u.id = $1
+	`, userID)
+	return name, err
+}
+
 func (r *UserRepo) GetRoleIDByName(name string) (string, error) {
 	var roleID string
 	err := r.DB.Get(&roleID, `
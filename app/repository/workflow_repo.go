@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"project_uas/app/listing"
+	"project_uas/app/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type WorkflowRepo struct {
+	DB *sqlx.DB
+}
+
+func NewWorkflowRepo(db *sqlx.DB) *WorkflowRepo {
+	return &WorkflowRepo{DB: db}
+}
+
+// =====================
+// READ
+// =====================
+func (r *WorkflowRepo) GetAll() ([]model.WorkflowRule, error) {
+	rules := []model.WorkflowRule{}
+	err := r.DB.Select(&rules, `
+		SELECT id, name, category, level, stages, created_at
+		FROM achievement_workflows
+		ORDER BY created_at ASC
+	`)
+	return rules, err
+}
+
+// WorkflowListSpec: GET /workflows (?category=, ?level=)
+var WorkflowListSpec = listing.Spec{
+	Sorts: map[string]listing.Field{
+		"created_at": {Column: "created_at", Key: "created_at"},
+		"name":       {Column: "name", Key: "name"},
+	},
+	DefaultSort: "created_at",
+	IDColumn:    "id",
+	Filters: map[string]listing.Filter{
+		"category": {Column: "category", Op: listing.OpIn},
+		"level":    {Column: "level", Op: listing.OpIn},
+	},
+	Search: []string{"name"},
+}
+
+func (r *WorkflowRepo) List(p *listing.Params) (*listing.Page[model.WorkflowRule], error) {
+	return listing.Fetch[model.WorkflowRule](r.DB, WorkflowListSpec, p, listing.Query{
+		Select: "id, name, category, level, stages, created_at",
+		From:   "achievement_workflows",
+	})
+}
+
+// =====================
+// CREATE
+// =====================
+func (r *WorkflowRepo) Create(rule *model.WorkflowRule) error {
+	return r.DB.Get(rule, `
+		INSERT INTO achievement_workflows (id, name, category, level, stages, created_at)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, NOW())
+		RETURNING id, name, category, level, stages, created_at
+	`, rule.Name, rule.Category, rule.Level, rule.Stages)
+}
+
+// =====================
+// DELETE
+// =====================
+func (r *WorkflowRepo) Delete(id string) (bool, error) {
+	res, err := r.DB.Exec(`DELETE FROM achievement_workflows WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+func (r *WorkflowRepo) RoleExists(name string) (bool, error) {
+	var exists bool
+	err := r.DB.Get(&exists, `SELECT EXISTS (SELECT 1 FROM roles WHERE name = $1)`, name)
+	return exists, err
+}
@@ -0,0 +1,231 @@
+// Package saga menjaga konsistensi antara achievement_references (Postgres)
+// dan dokumen achievements (Mongo). Setiap operasi dua-store dicatat di
+// achievement_sagas sebelum langkah pertama; langkah terakhir (Postgres)
+// menutup saga dalam transaksi yang sama. Bila langkah kedua gagal, langkah
+// pertama dikompensasi. Bila proses mati di tengah jalan, Run menyelesaikan
+// atau mengkompensasi saga yang macet.
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"project_uas/app/model"
+	"project_uas/app/repository"
+)
+
+const (
+	// saga "started" yang lebih tua dari ini dianggap macet
+	staleAfter  = 2 * time.Minute
+	maxAttempts = 10
+	baseBackoff = 30 * time.Second
+	maxBackoff  = time.Hour
+)
+
+type Coordinator struct {
+	Repo         *repository.SagaRepo
+	Achievements *repository.AchievementRepo
+}
+
+func NewCoordinator(repo *repository.SagaRepo, achievements *repository.AchievementRepo) *Coordinator {
+	return &Coordinator{Repo: repo, Achievements: achievements}
+}
+
+// =====================
+// CREATE: Mongo insert → Postgres reference
+// =====================
+
+// CreateAchievement menyimpan dokumen Mongo lalu reference Postgres.
+// ID dokumen dibuat di sini supaya saga bisa mencatatnya sebelum insert.
+func (c *Coordinator) CreateAchievement(doc model.Achievement, ref *model.AchievementReference) error {
+	doc.ID = primitive.NewObjectID()
+	ref.MongoAchievementID = doc.ID.Hex()
+
+	s := &model.Saga{
+		ID:      uuid.New().String(),
+		Kind:    model.SagaCreateAchievement,
+		RefID:   ref.ID,
+		MongoID: ref.MongoAchievementID,
+	}
+	if err := c.Repo.Start(s, staleAfter); err != nil {
+		return fmt.Errorf("start saga: %w", err)
+	}
+
+	if _, err := c.Achievements.CreateAchievementMongo(doc); err != nil {
+		c.compensate(*s, err)
+		return fmt.Errorf("insert achievement into mongo: %w", err)
+	}
+
+	if err := c.Achievements.CreateReference(*ref, s.ID); err != nil {
+		c.compensate(*s, err)
+		return fmt.Errorf("insert reference to postgres: %w", err)
+	}
+
+	return nil
+}
+
+// =====================
+// DELETE: Mongo soft delete → Postgres status
+// =====================
+
+// DeleteAchievement men-soft-delete dokumen Mongo lalu mencatat transisi
+// status di Postgres. change.SagaID diisi oleh coordinator.
+func (c *Coordinator) DeleteAchievement(ref *model.AchievementReference, change model.StatusChange) error {
+	s := &model.Saga{
+		ID:      uuid.New().String(),
+		Kind:    model.SagaDeleteAchievement,
+		RefID:   ref.ID,
+		MongoID: ref.MongoAchievementID,
+	}
+	if err := c.Repo.Start(s, staleAfter); err != nil {
+		return fmt.Errorf("start saga: %w", err)
+	}
+
+	if err := c.Achievements.SoftDeleteMongo(ref.MongoAchievementID); err != nil {
+		c.compensate(*s, err)
+		return fmt.Errorf("soft delete mongo: %w", err)
+	}
+
+	change.SagaID = s.ID
+	if err := c.Achievements.ApplyTransition(change); err != nil {
+		c.compensate(*s, err)
+		return err
+	}
+
+	return nil
+}
+
+// =====================
+// COMPENSATION
+// =====================
+
+// compensate membatalkan langkah Mongo. Kegagalan di sini tidak dikembalikan
+// ke pemanggil: saga tetap "compensating" dan dicoba ulang oleh Run.
+func (c *Coordinator) compensate(s model.Saga, cause error) {
+	ok, err := c.Repo.BeginCompensation(s.ID, cause.Error())
+	if err != nil {
+		log.Println("saga: begin compensation", s.ID, ":", err)
+		return
+	}
+	if !ok {
+		// sudah completed (commit berhasil walau driver melapor error)
+		// atau sudah ditangani recoverer
+		return
+	}
+
+	c.undo(s)
+}
+
+func (c *Coordinator) undo(s model.Saga) {
+	var err error
+	switch s.Kind {
+	case model.SagaCreateAchievement:
+		err = c.Achievements.DeleteAchievementMongo(s.MongoID)
+	case model.SagaDeleteAchievement:
+		err = c.Achievements.RestoreMongo(s.MongoID)
+	default:
+		err = errors.New("unknown saga kind: " + s.Kind)
+	}
+
+	if err != nil {
+		final := s.Attempts >= maxAttempts
+		if final {
+			log.Println("saga: giving up compensation", s.ID, ":", err)
+		}
+		if merr := c.Repo.MarkRetry(s.ID, err.Error(), time.Now().Add(backoff(s.Attempts)), final); merr != nil {
+			log.Println("saga: mark retry:", merr)
+		}
+		return
+	}
+
+	if err := c.Repo.MarkCompensated(s.ID); err != nil {
+		log.Println("saga: mark compensated:", err)
+	}
+}
+
+// =====================
+// RECOVERY
+// =====================
+
+// Run memproses saga macet secara berkala sampai ctx dibatalkan
+func (c *Coordinator) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		c.recoverBatch()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Coordinator) recoverBatch() {
+	sagas, err := c.Repo.Claim(20, 5*time.Minute)
+	if err != nil {
+		log.Println("saga: claim:", err)
+		return
+	}
+
+	for _, s := range sagas {
+		c.recover(s)
+	}
+}
+
+func (c *Coordinator) recover(s model.Saga) {
+	if s.State == model.SagaCompensating {
+		c.undo(s)
+		return
+	}
+
+	// started tapi macet: cek apakah langkah Postgres sebenarnya sudah terjadi
+	done, err := c.forwardDone(s)
+	if err != nil {
+		log.Println("saga: check", s.ID, ":", err)
+		return
+	}
+	if done {
+		if err := c.Repo.MarkCompleted(s.ID); err != nil {
+			log.Println("saga: mark completed:", err)
+		}
+		return
+	}
+
+	c.compensate(s, errors.New("saga abandoned before completion"))
+}
+
+func (c *Coordinator) forwardDone(s model.Saga) (bool, error) {
+	status, err := c.Achievements.ReferenceStatus(s.RefID)
+	if err != nil {
+		return false, err
+	}
+
+	switch s.Kind {
+	case model.SagaCreateAchievement:
+		return status != "", nil
+	case model.SagaDeleteAchievement:
+		return status == "deleted", nil
+	}
+	return false, nil
+}
+
+// backoff eksponensial: 30s, 1m, 2m, 4m, ... maksimal 1 jam
+func backoff(attempt int) time.Duration {
+	d := baseBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
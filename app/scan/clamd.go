@@ -0,0 +1,148 @@
+// Package scan memeriksa lampiran yang diunggah memakai daemon antivirus
+// berprotokol clamd (ClamAV) sebelum file boleh diunduh.
+package scan
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const chunkSize = 32 * 1024
+
+// Result adalah hasil scan satu file
+type Result struct {
+	Clean     bool
+	Signature string // nama virus bila tidak bersih
+}
+
+// Clamd adalah client minimal protokol clamd (PING & INSTREAM).
+// Addr berupa host:port, atau unix:/path/ke/clamd.sock.
+type Clamd struct {
+	Addr    string
+	Timeout time.Duration
+}
+
+func (c *Clamd) dial(ctx context.Context) (net.Conn, error) {
+	network, addr := "tcp", c.Addr
+	if strings.HasPrefix(addr, "unix:") {
+		network, addr = "unix", strings.TrimPrefix(addr, "unix:")
+	}
+
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = time.Minute
+	}
+
+	d := net.Dialer{Timeout: 10 * time.Second}
+	conn, err := d.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	if dl, ok := ctx.Deadline(); ok && dl.Before(deadline) {
+		deadline = dl
+	}
+	conn.SetDeadline(deadline)
+
+	return conn, nil
+}
+
+// Ping memastikan daemon hidup
+func (c *Clamd) Ping(ctx context.Context) error {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("zPING\x00")); err != nil {
+		return err
+	}
+
+	reply, err := readReply(conn)
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("clamd: unexpected ping reply %q", reply)
+	}
+	return nil
+}
+
+// Scan mengirim isi r dengan perintah INSTREAM:
+// "zINSTREAM\0", lalu potongan [panjang uint32 big-endian][data],
+// diakhiri potongan dengan panjang 0.
+func (c *Clamd) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, chunkSize)
+	size := make([]byte, 4)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, werr := conn.Write(size); werr != nil {
+				return nil, werr
+			}
+			if _, werr := conn.Write(buf[:n]); werr != nil {
+				return nil, werr
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return nil, err
+	}
+
+	reply, err := readReply(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseReply(reply)
+}
+
+// readReply membaca satu balasan yang diakhiri NUL (mode "z")
+func readReply(conn net.Conn) (string, error) {
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !(errors.Is(err, io.EOF) && reply != "") {
+		return "", err
+	}
+	return strings.TrimRight(reply, "\x00\n"), nil
+}
+
+// parseReply: "stream: OK" | "stream: <signature> FOUND" | "<pesan> ERROR"
+func parseReply(reply string) (*Result, error) {
+	reply = strings.TrimPrefix(reply, "stream: ")
+
+	switch {
+	case reply == "OK":
+		return &Result{Clean: true}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return &Result{Signature: strings.TrimSuffix(reply, " FOUND")}, nil
+	default:
+		return nil, fmt.Errorf("clamd: %s", reply)
+	}
+}
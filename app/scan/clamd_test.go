@@ -0,0 +1,193 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stream adalah apa yang diterima stand-in dari satu koneksi INSTREAM
+type stream struct {
+	command string
+	chunks  []int
+	data    []byte
+	err     error
+}
+
+// fakeClamd menjalankan stand-in clamd di localhost yang membalas reply
+// untuk setiap koneksi dan melaporkan framing yang diterimanya
+func fakeClamd(t *testing.T, reply string) (string, <-chan stream) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	got := make(chan stream, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		got <- readStream(conn, reply)
+	}()
+	return ln.Addr().String(), got
+}
+
+func readStream(conn net.Conn, reply string) stream {
+	r := bufio.NewReader(conn)
+	var s stream
+
+	s.command, s.err = r.ReadString(0)
+	if s.err != nil {
+		return s
+	}
+	if s.command == "zPING\x00" {
+		conn.Write([]byte("PONG\x00"))
+		return s
+	}
+
+	size := make([]byte, 4)
+	for {
+		if _, s.err = io.ReadFull(r, size); s.err != nil {
+			return s
+		}
+		n := binary.BigEndian.Uint32(size)
+		if n == 0 {
+			break
+		}
+		chunk := make([]byte, n)
+		if _, s.err = io.ReadFull(r, chunk); s.err != nil {
+			return s
+		}
+		s.chunks = append(s.chunks, int(n))
+		s.data = append(s.data, chunk...)
+	}
+
+	conn.Write([]byte(reply + "\x00"))
+	return s
+}
+
+func TestClamdScan(t *testing.T) {
+	payload := bytes.Repeat([]byte("0123456789"), 7000) // 70000 byte → 3 chunk
+
+	tests := []struct {
+		name      string
+		reply     string
+		clean     bool
+		signature string
+		err       string
+	}{
+		{"clean", "stream: OK", true, "", ""},
+		{"infected", "stream: Eicar-Test-Signature FOUND", false, "Eicar-Test-Signature", ""},
+		{"error", "INSTREAM size limit exceeded. ERROR", false, "", "clamd: INSTREAM size limit exceeded. ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, got := fakeClamd(t, tt.reply)
+			c := &Clamd{Addr: addr, Timeout: 5 * time.Second}
+
+			res, err := c.Scan(context.Background(), bytes.NewReader(payload))
+
+			s := <-got
+			if s.err != nil {
+				t.Fatalf("stand-in: %v", s.err)
+			}
+			if s.command != "zINSTREAM\x00" {
+				t.Fatalf("command = %q", s.command)
+			}
+			// potongan maksimal chunkSize, lalu terminator panjang 0
+			want := []int{chunkSize, chunkSize, len(payload) - 2*chunkSize}
+			if len(s.chunks) != len(want) {
+				t.Fatalf("chunks = %v, want %v", s.chunks, want)
+			}
+			for i := range want {
+				if s.chunks[i] != want[i] {
+					t.Fatalf("chunks = %v, want %v", s.chunks, want)
+				}
+			}
+			if !bytes.Equal(s.data, payload) {
+				t.Fatal("stand-in received different data")
+			}
+
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			if res.Clean != tt.clean || res.Signature != tt.signature {
+				t.Fatalf("result = %+v", res)
+			}
+		})
+	}
+}
+
+func TestClamdScanEmpty(t *testing.T) {
+	addr, got := fakeClamd(t, "stream: OK")
+	c := &Clamd{Addr: addr, Timeout: 5 * time.Second}
+
+	res, err := c.Scan(context.Background(), strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	s := <-got
+	if s.err != nil || len(s.chunks) != 0 {
+		t.Fatalf("stream = %+v, want only the zero-length terminator", s)
+	}
+	if !res.Clean {
+		t.Fatalf("result = %+v", res)
+	}
+}
+
+func TestClamdPing(t *testing.T) {
+	addr, got := fakeClamd(t, "")
+	c := &Clamd{Addr: addr, Timeout: 5 * time.Second}
+
+	if err := c.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	if s := <-got; s.command != "zPING\x00" {
+		t.Fatalf("command = %q", s.command)
+	}
+}
+
+func TestParseReply(t *testing.T) {
+	tests := []struct {
+		reply     string
+		clean     bool
+		signature string
+		wantErr   bool
+	}{
+		{"stream: OK", true, "", false},
+		{"OK", true, "", false},
+		{"stream: Win.Test.EICAR_HDB-1 FOUND", false, "Win.Test.EICAR_HDB-1", false},
+		{"Can't allocate memory ERROR", false, "", true},
+		{"", false, "", true},
+	}
+
+	for _, tt := range tests {
+		res, err := parseReply(tt.reply)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseReply(%q): want error, got %+v", tt.reply, res)
+			}
+			continue
+		}
+		if err != nil || res.Clean != tt.clean || res.Signature != tt.signature {
+			t.Errorf("parseReply(%q) = %+v, %v", tt.reply, res, err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package scan
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"project_uas/app/model"
+	"project_uas/app/repository"
+	"project_uas/app/storage"
+)
+
+const (
+	maxAttempts = 10
+	baseBackoff = 30 * time.Second
+	maxBackoff  = time.Hour
+	scanTimeout = 5 * time.Minute
+)
+
+// Scanner memproses lampiran di karantina: scan lalu promosikan ke
+// storage content-addressed, atau buang bila terinfeksi.
+type Scanner struct {
+	Repo    *repository.AttachmentScanRepo
+	Storage storage.Storage
+	Clamd   *Clamd
+}
+
+func NewScanner(repo *repository.AttachmentScanRepo, store storage.Storage, clamd *Clamd) *Scanner {
+	return &Scanner{Repo: repo, Storage: store, Clamd: clamd}
+}
+
+// Run memproses antrian scan secara berkala sampai ctx dibatalkan
+func (s *Scanner) Run(ctx context.Context, interval time.Duration) {
+	if s.Clamd == nil || s.Clamd.Addr == "" {
+		log.Println("scan: CLAMD_ADDR not configured, attachments stay pending_scan")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.processBatch(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scanner) processBatch(ctx context.Context) {
+	list, err := s.Repo.Claim(10, scanTimeout+time.Minute)
+	if err != nil {
+		log.Println("scan: claim attachments:", err)
+		return
+	}
+
+	for _, att := range list {
+		if err := s.process(ctx, att); err != nil {
+			log.Printf("scan: attachment %s failed (attempt %d): %v", att.ID, att.ScanAttempts, err)
+			final := att.ScanAttempts >= maxAttempts
+			next := time.Now().Add(backoff(att.ScanAttempts))
+			if err := s.Repo.MarkRetry(att.ID, err.Error(), next, final); err != nil {
+				log.Println("scan: mark retry:", err)
+			}
+		}
+	}
+}
+
+func (s *Scanner) process(ctx context.Context, att model.AchievementAttachment) error {
+	scanCtx, cancel := context.WithTimeout(ctx, scanTimeout)
+	defer cancel()
+
+	body, err := s.Storage.Get(scanCtx, att.StorageKey)
+	if err != nil {
+		return err
+	}
+	result, err := s.Clamd.Scan(scanCtx, body)
+	body.Close()
+	if err != nil {
+		return err
+	}
+
+	if !result.Clean {
+		log.Printf("scan: attachment %s infected: %s", att.ID, result.Signature)
+		if err := s.Storage.Delete(ctx, att.StorageKey); err != nil {
+			log.Println("scan: delete infected file:", err)
+		}
+		return s.Repo.MarkInfected(att.ID, result.Signature)
+	}
+
+	// promosi + MarkClean memegang kunci blob yang sama dengan
+	// DeleteAttachment, jadi blob yang ditemukan Exists tidak bisa terhapus
+	// sebelum lampiran ini menunjuknya
+	return s.Repo.WithBlobLock(storage.BlobKey(att.Checksum), func(tx *sqlx.Tx) error {
+		blob, err := storage.Promote(ctx, s.Storage, att.StorageKey, att.Checksum, att.FileSize, att.ContentType)
+		if err != nil {
+			return err
+		}
+
+		updated, err := repository.MarkCleanTx(tx, att.ID, blob.Key)
+		if err != nil {
+			return err
+		}
+
+		if updated {
+			return nil
+		}
+
+		// lampiran dihapus selagi di-scan → jangan tinggalkan blob yatim.
+		// Dihitung ulang di bawah kunci: blob hasil dedup pun bisa sudah
+		// tidak dipakai bila lampiran lain dihapus sebelum kunci diambil
+		inUse, err := repository.BlobInUseTx(tx, blob.Key)
+		if err != nil {
+			return err
+		}
+		if !inUse {
+			if err := s.Storage.Delete(ctx, blob.Key); err != nil {
+				log.Println("scan: delete orphan blob:", err)
+			}
+		}
+		return nil
+	})
+}
+
+// backoff eksponensial: 30s, 1m, 2m, ... maksimal 1 jam
+func backoff(attempt int) time.Duration {
+	d := baseBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
@@ -0,0 +1,432 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"project_uas/app/model"
+	"project_uas/app/policy"
+	"project_uas/app/preview"
+	"project_uas/app/storage"
+	"project_uas/app/workflow"
+	"project_uas/config"
+	"project_uas/helper"
+)
+
+// ------------------------- ATTACHMENT ACCESS ----------------------------
+// Siapa yang boleh melihat / mengubah lampiran ditentukan policy
+// (attachment:read / attachment:write). Selain itu pemilik hanya boleh
+// mengubah selama prestasi masih bisa diedit; pemegang achievements:manage
+// tidak dibatasi status.
+func (s *AchievementService) attachmentAccess(c *fiber.Ctx, ref *model.AchievementReference) (canRead bool, canWrite bool) {
+	if policy.Default == nil {
+		return false, false
+	}
+
+	subject := policy.SubjectFrom(c)
+	res := policy.Resource{Kind: policy.KindAchievement, ID: ref.ID}
+
+	canRead = policy.Default.Allowed(subject, "attachment:read", res)
+	canWrite = policy.Default.Allowed(subject, "attachment:write", res) &&
+		(subject.HasPermission("achievements:manage") || workflow.Allowed(ref.Status, workflow.ActionEdit))
+
+	return canRead, canWrite
+}
+
+// loadAttachmentRef mengambil reference & mengecek akses user dari JWT
+func (s *AchievementService) loadAttachmentRef(c *fiber.Ctx, write bool) (*model.AchievementReference, error) {
+	userID, _ := c.Locals("user_id").(string)
+	if userID == "" {
+		return nil, c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": "missing user in token",
+		})
+	}
+
+	s.Repo.EnsureDBs()
+
+	ref, err := s.Repo.GetReferenceByID(c.Params("id"))
+	if err != nil {
+		return nil, c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "achievement not found",
+		})
+	}
+
+	canRead, canWrite := s.attachmentAccess(c, ref)
+	if !canRead || (write && !canWrite) {
+		return nil, c.Status(http.StatusForbidden).JSON(fiber.Map{
+			"error": "not allowed to access attachments of this achievement",
+		})
+	}
+
+	return ref, nil
+}
+
+func attachmentPath(refID string, attID string) string {
+	return "/api/v1/achievements/" + refID + "/attachments/" + attID
+}
+
+// signedAttachmentURL membuat link unduhan / pratinjau ("download" |
+// "preview/download") yang berlaku sementara
+func signedAttachmentURL(att model.AchievementAttachment, suffix string) (string, int64) {
+	path := attachmentPath(att.AchievementRefID, att.ID) + "/" + suffix
+	expires, sig := helper.SignResource(path, config.Env.AttachmentURLTTL)
+	return fmt.Sprintf("%s?expires=%d&sig=%s", path, expires, sig), expires
+}
+
+// attachmentLinks menyusun metadata lampiran beserta link bertanda tangan
+// untuk file (hanya bila bersih) dan pratinjaunya (bila sudah jadi)
+func attachmentLinks(rows []model.AchievementAttachment) []fiber.Map {
+	data := make([]fiber.Map, 0, len(rows))
+	for _, att := range rows {
+		item := fiber.Map{"attachment": att}
+		switch att.ScanStatus {
+		case model.ScanClean:
+			url, expires := signedAttachmentURL(att, "download")
+			item["download_url"] = url
+			item["expires_at"] = expires
+		case model.ScanFailed:
+			item["scan_message"] = "attachment could not be scanned, please upload it again"
+		}
+		if att.PreviewStatus == model.PreviewReady {
+			url, _ := signedAttachmentURL(att, "preview/download")
+			item["preview_url"] = url
+		}
+		data = append(data, item)
+	}
+	return data
+}
+
+// ------------------------- UPLOAD ----------------------------
+// POST /api/v1/achievements/:id/attachments
+func (s *AchievementService) UploadAttachment(c *fiber.Ctx) error {
+	ref, err := s.loadAttachmentRef(c, true)
+	if ref == nil {
+		return err
+	}
+	refID := ref.ID
+	userIDStr := c.Locals("user_id").(string)
+
+	// ===============================
+	// Ambil file dari form-data
+	// ===============================
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "file is required",
+		})
+	}
+
+	// ===============================
+	// Validasi: ukuran, MIME asli, allow-list kategori
+	// ===============================
+	ach, err := s.Repo.GetAchievementMongo(ref.MongoAchievementID)
+	if err != nil {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "achievement detail not found",
+		})
+	}
+
+	valid, violations := validateAttachment(file, ach.Category, config.Env.AttachmentMaxSize)
+	if len(violations) > 0 {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":      "invalid attachment",
+			"violations": violations,
+		})
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "failed read file",
+		})
+	}
+	defer src.Close()
+
+	// ===============================
+	// Simpan ke karantina; scanner memindahkannya ke storage
+	// content-addressed setelah dinyatakan bersih
+	// ===============================
+	blob, err := storage.PutQuarantine(c.UserContext(), s.Storage, src, valid.ContentType)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":  "failed save file",
+			"detail": err.Error(),
+		})
+	}
+
+	// ===============================
+	// Simpan ke database
+	// ===============================
+	att := model.AchievementAttachment{
+		ID:               uuid.New().String(),
+		AchievementRefID: refID,
+		FileName:         filepath.Base(file.Filename),
+		FileType:         valid.FileType,
+		ContentType:      valid.ContentType,
+		StorageKey:       blob.Key,
+		Checksum:         blob.SHA256,
+		FileSize:         blob.Size,
+		ScanStatus:       model.ScanPending,
+		UploadedBy:       userIDStr,
+	}
+	att.FileURL = attachmentPath(refID, att.ID)
+
+	if err := s.Repo.AddAttachment(att); err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":  "failed save attachment",
+			"detail": err.Error(),
+		})
+	}
+
+	if err := s.Repo.PushFileToAchievement(ref.MongoAchievementID, []string{att.FileURL}); err != nil {
+		log.Println("push attachment to mongo:", err)
+	}
+
+	return c.Status(http.StatusAccepted).JSON(fiber.Map{
+		"message":     "attachment uploaded, waiting for virus scan",
+		"id":          att.ID,
+		"file_url":    att.FileURL,
+		"checksum":    blob.SHA256,
+		"size":        blob.Size,
+		"scan_status": att.ScanStatus,
+	})
+}
+
+// ------------------------- LIST ----------------------------
+// GET /api/v1/achievements/:id/attachments
+func (s *AchievementService) ListAttachments(c *fiber.Ctx) error {
+	ref, err := s.loadAttachmentRef(c, false)
+	if ref == nil {
+		return err
+	}
+
+	rows, err := s.Repo.GetAttachments(ref.ID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":  "failed retrieve attachments",
+			"detail": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{"data": attachmentLinks(rows)})
+}
+
+// ------------------------- DOWNLOAD ----------------------------
+// GET /api/v1/achievements/:id/attachments/:attachmentId
+func (s *AchievementService) DownloadAttachment(c *fiber.Ctx) error {
+	ref, err := s.loadAttachmentRef(c, false)
+	if ref == nil {
+		return err
+	}
+
+	att, err := s.Repo.GetAttachment(ref.ID, c.Params("attachmentId"))
+	if err != nil {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "attachment not found",
+		})
+	}
+
+	return s.streamAttachment(c, att)
+}
+
+// GET /api/v1/achievements/:id/attachments/:attachmentId/download?expires=&sig=
+// Tanpa JWT; akses dibuktikan oleh signed URL dari ListAttachments.
+func (s *AchievementService) DownloadSignedAttachment(c *fiber.Ctx) error {
+	att, err := s.loadSignedAttachment(c, "download")
+	if att == nil {
+		return err
+	}
+
+	return s.streamAttachment(c, att)
+}
+
+// GET /api/v1/achievements/:id/attachments/:attachmentId/preview/download?expires=&sig=
+func (s *AchievementService) DownloadSignedPreview(c *fiber.Ctx) error {
+	att, err := s.loadSignedAttachment(c, "preview/download")
+	if att == nil {
+		return err
+	}
+
+	return s.streamPreview(c, att)
+}
+
+func (s *AchievementService) loadSignedAttachment(c *fiber.Ctx, suffix string) (*model.AchievementAttachment, error) {
+	refID := c.Params("id")
+	attID := c.Params("attachmentId")
+
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil || !helper.VerifyResourceSignature(attachmentPath(refID, attID)+"/"+suffix, expires, c.Query("sig")) {
+		return nil, c.Status(http.StatusForbidden).JSON(fiber.Map{
+			"error": "invalid or expired download link",
+		})
+	}
+
+	s.Repo.EnsureDBs()
+
+	att, err := s.Repo.GetAttachment(refID, attID)
+	if err != nil {
+		return nil, c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "attachment not found",
+		})
+	}
+
+	return att, nil
+}
+
+// ------------------------- PREVIEW ----------------------------
+// GET /api/v1/achievements/:id/attachments/:attachmentId/preview
+func (s *AchievementService) GetAttachmentPreview(c *fiber.Ctx) error {
+	ref, err := s.loadAttachmentRef(c, false)
+	if ref == nil {
+		return err
+	}
+
+	att, err := s.Repo.GetAttachment(ref.ID, c.Params("attachmentId"))
+	if err != nil {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "attachment not found",
+		})
+	}
+
+	return s.streamPreview(c, att)
+}
+
+func (s *AchievementService) streamPreview(c *fiber.Ctx, att *model.AchievementAttachment) error {
+	if att.ScanStatus != model.ScanClean || att.PreviewStatus != model.PreviewReady || att.PreviewKey == nil {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error":          "preview not available",
+			"preview_status": att.PreviewStatus,
+		})
+	}
+
+	body, err := s.Storage.Get(c.UserContext(), *att.PreviewKey)
+	if err != nil {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "preview file missing from storage",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, preview.ContentType)
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	c.Set(fiber.HeaderCacheControl, "private, max-age=3600")
+
+	return c.SendStream(body)
+}
+
+// streamAttachment mengalirkan isi blob tanpa memuat seluruhnya ke memori.
+// ?disposition=inline supaya bisa dibuka langsung di browser.
+func (s *AchievementService) streamAttachment(c *fiber.Ctx, att *model.AchievementAttachment) error {
+	switch att.ScanStatus {
+	case model.ScanClean:
+	case model.ScanInfected:
+		return c.Status(http.StatusGone).JSON(fiber.Map{
+			"error": "attachment was rejected by virus scan",
+		})
+	case model.ScanFailed:
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":       "attachment could not be scanned, please upload it again",
+			"scan_status": att.ScanStatus,
+		})
+	default:
+		return c.Status(http.StatusConflict).JSON(fiber.Map{
+			"error":       "attachment is waiting for virus scan",
+			"scan_status": att.ScanStatus,
+		})
+	}
+
+	// lampiran lama (sebelum storage) hanya punya path lokal di file_url
+	if att.StorageKey == "" {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "attachment file missing from storage",
+		})
+	}
+
+	body, err := s.Storage.Get(c.UserContext(), att.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "attachment file missing from storage",
+		})
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":  "failed read attachment",
+			"detail": err.Error(),
+		})
+	}
+
+	disposition := "attachment"
+	if c.Query("disposition") == "inline" {
+		disposition = "inline"
+	}
+
+	name := att.FileName
+	if name == "" {
+		name = att.ID + "." + att.FileType
+	}
+
+	contentType := att.ContentType
+	if contentType == "" {
+		contentType = fiber.MIMEOctetStream
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType(disposition, map[string]string{"filename": name}))
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	c.Set(fiber.HeaderCacheControl, "private, max-age=0")
+	if att.Checksum != "" {
+		c.Set(fiber.HeaderETag, `"`+att.Checksum+`"`)
+	}
+
+	// fasthttp menutup body setelah selesai dikirim
+	return c.SendStream(body, int(att.FileSize))
+}
+
+// ------------------------- DELETE ----------------------------
+// DELETE /api/v1/achievements/:id/attachments/:attachmentId
+func (s *AchievementService) DeleteAttachment(c *fiber.Ctx) error {
+	ref, err := s.loadAttachmentRef(c, true)
+	if ref == nil {
+		return err
+	}
+
+	att, err := s.Repo.GetAttachment(ref.ID, c.Params("attachmentId"))
+	if err != nil {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "attachment not found",
+		})
+	}
+
+	// blob content-addressed bisa dipakai lampiran lain; dihapus hanya bila
+	// tidak dipakai lagi, selagi kunci blob dipegang
+	ctx := c.UserContext()
+	err = s.Repo.DeleteAttachment(att.ID, func(storageKey string, previewKey string) {
+		if err := s.Storage.Delete(ctx, storageKey); err != nil {
+			log.Println("delete attachment blob:", err)
+		}
+		if previewKey != "" {
+			if err := s.Storage.Delete(ctx, previewKey); err != nil {
+				log.Println("delete attachment preview:", err)
+			}
+		}
+	})
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":  "failed delete attachment",
+			"detail": err.Error(),
+		})
+	}
+
+	if err := s.Repo.PullFileFromAchievement(ref.MongoAchievementID, att.FileURL); err != nil {
+		log.Println("pull attachment from mongo:", err)
+	}
+
+	return c.JSON(fiber.Map{"message": "attachment deleted"})
+}
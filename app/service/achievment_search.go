@@ -0,0 +1,348 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"project_uas/app/model"
+	"project_uas/app/policy"
+)
+
+// ------------------------- SEARCH ----------------------------
+// GET /api/v1/achievements/search
+//
+//	?q=            full-text pada title / description / organizer
+//	&category=     bisa diulang atau dipisah koma (juga level, status,
+//	&level=        program_study, academic_year)
+//	&status=
+//	&program_study=
+//	&academic_year=
+//	&year=2025     singkatan untuk from=2025-01-01&to=2025-12-31
+//	&from=&to=     rentang event_date (YYYY-MM-DD, inklusif)
+//	&sort=         relevance | newest | event_date
+//	&page=&limit=
+//
+// Scope: achievements:read-all → semua, admin unit → mahasiswa di unitnya,
+// students:advisees → mahasiswa bimbingan, achievements:create → prestasinya
+// sendiri. Subject lain sudah ditolak policy (achievement:search).
+func (s *AchievementService) SearchAchievements(c *fiber.Ctx) error {
+	q, msg := parseSearchQuery(c)
+	if msg != "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": msg})
+	}
+
+	scope, err := s.searchScope(c)
+	if err != nil {
+		return c.Status(http.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	s.Repo.EnsureDBs()
+	tally := newSearchTally(q)
+
+	// scope kecil (mahasiswa, dosen wali) cukup dimuat sekali dan dikirim
+	// ke Mongo sebagai $in terbatas; scope besar dicocokkan per batch hasil
+	// Mongo sehingga tidak ada daftar ID tanpa batas di kedua sisi
+	scoped, err := s.Repo.SearchCandidates(scope, nil, smallSearchScope+1)
+	if err != nil {
+		return searchFailed(c, err)
+	}
+
+	var onlyIDs []string
+	byMongoID := map[string]model.SearchCandidate{}
+	small := len(scoped) <= smallSearchScope
+	if small {
+		onlyIDs = make([]string, 0, len(scoped))
+		for _, cand := range scoped {
+			byMongoID[cand.MongoID] = cand
+			onlyIDs = append(onlyIDs, cand.MongoID)
+		}
+	}
+
+	err = s.Repo.SearchMongo(c.UserContext(), q, onlyIDs, func(hits []model.SearchHit) error {
+		candidates := byMongoID
+		if !small {
+			ids := make([]string, len(hits))
+			for i, hit := range hits {
+				ids[i] = hit.ID.Hex()
+			}
+			list, err := s.Repo.SearchCandidates(scope, ids, 0)
+			if err != nil {
+				return err
+			}
+			candidates = make(map[string]model.SearchCandidate, len(list))
+			for _, cand := range list {
+				candidates[cand.MongoID] = cand
+			}
+		}
+
+		for _, hit := range hits {
+			if cand, ok := candidates[hit.ID.Hex()]; ok {
+				tally.add(hit, cand)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return searchFailed(c, err)
+	}
+
+	pageIDs := make([]string, len(tally.page))
+	for i, cand := range tally.page {
+		pageIDs[i] = cand.MongoID
+	}
+	docs, err := s.Repo.SearchDocuments(c.UserContext(), pageIDs)
+	if err != nil {
+		return searchFailed(c, err)
+	}
+
+	data := make([]fiber.Map, 0, len(tally.page))
+	for _, cand := range tally.page {
+		doc, ok := docs[cand.MongoID]
+		if !ok {
+			continue
+		}
+		data = append(data, fiber.Map{
+			"reference":   cand,
+			"achievement": doc,
+		})
+	}
+
+	facets := map[string][]model.FacetCount{}
+	for name, counts := range tally.facets {
+		facets[name] = facetList(counts)
+	}
+
+	// relevansi teks tidak bisa di-keyset, jadi search tetap memakai page
+	return c.JSON(fiber.Map{
+		"data": data,
+		"meta": fiber.Map{
+			"page":     q.Page,
+			"limit":    q.Limit,
+			"total":    tally.total,
+			"has_more": q.Page*q.Limit < tally.total,
+		},
+		"facets": facets,
+	})
+}
+
+// searchFailed mencatat error internal (query Postgres / Mongo) di log dan
+// hanya mengembalikan pesan umum ke client
+func searchFailed(c *fiber.Ctx, err error) error {
+	log.Println("search achievements:", err)
+	return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "failed search achievements"})
+}
+
+// smallSearchScope: scope dengan reference sebanyak ini atau kurang
+// dicocokkan ke Mongo lewat satu $in
+const smallSearchScope = 1000
+
+// searchTally menghitung total, facet dan isi halaman dari hasil yang
+// dialirkan sesuai urutan sort. Facet dihitung tanpa filternya sendiri
+// (facet status mengabaikan ?status= tetapi tetap menerapkan filter lain),
+// supaya pilihan lain di facet yang sama tetap terlihat.
+type searchTally struct {
+	q      model.AchievementSearch
+	total  int
+	page   []model.SearchCandidate
+	facets map[string]map[string]int
+}
+
+func newSearchTally(q model.AchievementSearch) *searchTally {
+	facets := map[string]map[string]int{}
+	for _, name := range []string{"category", "level", "year", "status", "program_study", "academic_year"} {
+		facets[name] = map[string]int{}
+	}
+	return &searchTally{q: q, facets: facets}
+}
+
+// searchDimension adalah satu facet yang juga bisa difilter
+type searchDimension struct {
+	name   string
+	value  string
+	filter []string
+	fold   bool // kategori/tingkat diisi bebas ("Nasional" vs "nasional")
+}
+
+func (d searchDimension) matches() bool {
+	if len(d.filter) == 0 {
+		return true
+	}
+	for _, f := range d.filter {
+		if f == d.value || d.fold && strings.EqualFold(f, d.value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *searchTally) add(hit model.SearchHit, cand model.SearchCandidate) {
+	dims := []searchDimension{
+		{"category", strings.ToLower(hit.Category), t.q.Categories, true},
+		{"level", strings.ToLower(hit.Level), t.q.Levels, true},
+		{"status", cand.Status, t.q.Statuses, false},
+		{"program_study", cand.ProgramStudy, t.q.ProgramStudy, false},
+		{"academic_year", cand.AcademicYear, t.q.AcademicYear, false},
+	}
+
+	missed := -1
+	for i, d := range dims {
+		if d.matches() {
+			continue
+		}
+		if missed >= 0 {
+			return // gagal di dua filter: tidak dihitung di facet mana pun
+		}
+		missed = i
+	}
+
+	// hanya gagal di filternya sendiri: masuk facet itu saja
+	if missed >= 0 {
+		t.count(dims[missed].name, dims[missed].value)
+		return
+	}
+
+	for _, d := range dims {
+		t.count(d.name, d.value)
+	}
+	if hit.EventDate != nil {
+		t.count("year", strconv.Itoa(hit.EventDate.Year()))
+	}
+
+	if t.total >= (t.q.Page-1)*t.q.Limit && len(t.page) < t.q.Limit {
+		t.page = append(t.page, cand)
+	}
+	t.total++
+}
+
+func (t *searchTally) count(facet string, value string) {
+	if value != "" {
+		t.facets[facet][value]++
+	}
+}
+
+func (s *AchievementService) searchScope(c *fiber.Ctx) (model.SearchScope, error) {
+	subject := policy.SubjectFrom(c)
+
+	if subject.HasPermission("achievements:read-all") {
+		return model.SearchScope{IncludeDrafts: true}, nil
+	}
+	// scope diisi middleware.Authorize bila lolos sebagai admin unit
+	if units := policy.ScopeFrom(c); units != nil {
+		return model.SearchScope{Units: units, IncludeDrafts: true}, nil
+	}
+	if subject.HasPermission("students:advisees") {
+		lecturerID, err := s.StudentRepo.GetLecturerIDByUserID(subject.UserID)
+		if err != nil {
+			return model.SearchScope{}, errors.New("lecturer profile not found")
+		}
+		ids, err := s.StudentRepo.GetStudentIDsByAdvisor(lecturerID)
+		if err != nil {
+			log.Println("search achievements: load advisees:", err)
+			return model.SearchScope{}, errors.New("failed load advisees")
+		}
+		if ids == nil {
+			ids = []string{}
+		}
+		return model.SearchScope{StudentIDs: ids}, nil
+	}
+	if subject.HasPermission("achievements:create") {
+		student, err := s.StudentRepo.FindByUserID(subject.UserID)
+		if err != nil {
+			return model.SearchScope{}, errors.New("student profile not found")
+		}
+		return model.SearchScope{StudentIDs: []string{student.ID}, IncludeDrafts: true}, nil
+	}
+
+	return model.SearchScope{}, errors.New("not allowed to search achievements")
+}
+
+func parseSearchQuery(c *fiber.Ctx) (model.AchievementSearch, string) {
+	q := model.AchievementSearch{
+		Query:        strings.TrimSpace(c.Query("q")),
+		Categories:   queryList(c, "category"),
+		Levels:       queryList(c, "level"),
+		Statuses:     queryList(c, "status"),
+		ProgramStudy: queryList(c, "program_study"),
+		AcademicYear: queryList(c, "academic_year"),
+		Sort:         c.Query("sort"),
+	}
+
+	switch q.Sort {
+	case "", "relevance", "newest", "event_date":
+	default:
+		return q, "sort must be relevance, newest or event_date"
+	}
+
+	if y := c.Query("year"); y != "" {
+		year, err := strconv.Atoi(y)
+		if err != nil || year < 1900 || year > 9999 {
+			return q, "invalid year"
+		}
+		from := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := from.AddDate(1, 0, 0)
+		q.From, q.To = &from, &to
+	}
+	if v := c.Query("from"); v != "" {
+		from, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return q, "from must be YYYY-MM-DD"
+		}
+		q.From = &from
+	}
+	if v := c.Query("to"); v != "" {
+		to, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return q, "to must be YYYY-MM-DD"
+		}
+		to = to.AddDate(0, 0, 1) // inklusif
+		q.To = &to
+	}
+
+	q.Page, _ = strconv.Atoi(c.Query("page", "1"))
+	q.Limit, _ = strconv.Atoi(c.Query("limit", "20"))
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Limit < 1 || q.Limit > 100 {
+		q.Limit = 20
+	}
+
+	return q, ""
+}
+
+// queryList menerima ?k=a&k=b maupun ?k=a,b
+func queryList(c *fiber.Ctx, key string) []string {
+	var list []string
+	for _, raw := range c.Context().QueryArgs().PeekMulti(key) {
+		for _, v := range strings.Split(string(raw), ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				list = append(list, v)
+			}
+		}
+	}
+	return list
+}
+
+func facetList(counts map[string]int) []model.FacetCount {
+	list := make([]model.FacetCount, 0, len(counts))
+	for v, n := range counts {
+		if v == "" {
+			continue
+		}
+		list = append(list, model.FacetCount{Value: v, Count: n})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Value < list[j].Value
+	})
+	return list
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"project_uas/app/model"
+)
+
+func searchHit(category string, level string, year int) model.SearchHit {
+	date := time.Date(year, 6, 1, 0, 0, 0, 0, time.UTC)
+	return model.SearchHit{ID: primitive.NewObjectID(), Category: category, Level: level, EventDate: &date}
+}
+
+func facetCount(t *searchTally, facet string, value string) int {
+	return t.facets[facet][value]
+}
+
+// facet dihitung tanpa filternya sendiri, tetapi dengan filter lain
+func TestSearchTallyFacets(t *testing.T) {
+	tally := newSearchTally(model.AchievementSearch{
+		Categories: []string{"kompetisi"},
+		Statuses:   []string{"verified"},
+		Page:       1,
+		Limit:      10,
+	})
+
+	rows := []struct {
+		hit  model.SearchHit
+		cand model.SearchCandidate
+	}{
+		{searchHit("Kompetisi", "Nasional", 2024), model.SearchCandidate{RefID: "a", Status: "verified", ProgramStudy: "IF"}},
+		{searchHit("kompetisi", "lokal", 2025), model.SearchCandidate{RefID: "b", Status: "submitted", ProgramStudy: "IF"}},
+		{searchHit("Seminar", "nasional", 2025), model.SearchCandidate{RefID: "c", Status: "verified", ProgramStudy: "SI"}},
+		{searchHit("Seminar", "nasional", 2025), model.SearchCandidate{RefID: "d", Status: "draft", ProgramStudy: "SI"}},
+	}
+	for _, r := range rows {
+		tally.add(r.hit, r.cand)
+	}
+
+	if tally.total != 1 || len(tally.page) != 1 || tally.page[0].RefID != "a" {
+		t.Fatalf("total = %d, page = %+v", tally.total, tally.page)
+	}
+
+	tests := []struct {
+		facet string
+		value string
+		count int
+	}{
+		// status mengabaikan ?status=, tetap dibatasi kategori
+		{"status", "verified", 1},
+		{"status", "submitted", 1},
+		{"status", "draft", 0},
+		// kategori mengabaikan ?category= (tanpa beda huruf), tetap dibatasi status
+		{"category", "kompetisi", 1},
+		{"category", "seminar", 1},
+		// facet lain hanya dari hasil yang lolos semua filter
+		{"level", "nasional", 1},
+		{"level", "lokal", 0},
+		{"program_study", "IF", 1},
+		{"program_study", "SI", 0},
+		{"year", "2024", 1},
+		{"year", "2025", 0},
+	}
+	for _, tt := range tests {
+		if got := facetCount(tally, tt.facet, tt.value); got != tt.count {
+			t.Errorf("facet %s[%s] = %d, want %d", tt.facet, tt.value, got, tt.count)
+		}
+	}
+}
+
+func TestSearchTallyPage(t *testing.T) {
+	tally := newSearchTally(model.AchievementSearch{Page: 2, Limit: 2})
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		tally.add(searchHit("lomba", "lokal", 2025), model.SearchCandidate{RefID: id, Status: "verified"})
+	}
+
+	if tally.total != 5 || len(tally.page) != 2 || tally.page[0].RefID != "c" || tally.page[1].RefID != "d" {
+		t.Fatalf("total = %d, page = %+v", tally.total, tally.page)
+	}
+}
@@ -4,28 +4,40 @@ import (
 	"net/http"
 	"log"
 	"time"
-	"strconv"
-	"fmt"
-	"path/filepath"
 	"strings"
-	"os"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 
+	"project_uas/app/audit"
+	"project_uas/app/listing"
 	"project_uas/app/model"
+	"project_uas/app/policy"
 	"project_uas/app/repository"
+	"project_uas/app/saga"
+	"project_uas/app/storage"
+	"project_uas/app/workflow"
 )
 
 type AchievementService struct {
 	Repo        *repository.AchievementRepo
 	StudentRepo *repository.StudentRepo
+	Workflow    *WorkflowService
+	Notifier    *NotificationService
+	Storage     storage.Storage
+	Saga        *saga.Coordinator
 }
 
-func NewAchievementService(repo *repository.AchievementRepo, studentRepo *repository.StudentRepo) *AchievementService {
+func NewAchievementService(repo *repository.AchievementRepo, studentRepo *repository.StudentRepo, workflowService *WorkflowService, notifier *NotificationService, store storage.Storage, coordinator *saga.Coordinator) *AchievementService {
 	return &AchievementService{
 		Repo:        repo,
 		StudentRepo: studentRepo,
+		Workflow:    workflowService,
+		Notifier:    notifier,
+		Storage:     store,
+		Saga:        coordinator,
 	}
 }
 
@@ -67,26 +79,18 @@ func (s *AchievementService) CreateAchievement(c *fiber.Ctx) error {
 
 	s.Repo.EnsureDBs()
 
-	mongoHex, err := s.Repo.CreateAchievementMongo(req)
-	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error":  "failed insert achievement into mongo",
-			"detail": err.Error(),
-		})
-	}
-
 	ref := model.AchievementReference{
-		ID:                 uuid.New().String(),
-		StudentID:          student.ID,
-		MongoAchievementID: mongoHex,
-		Status:             "draft",
-		CreatedAt:          now,
-		UpdatedAt:          now,
+		ID:        uuid.New().String(),
+		StudentID: student.ID,
+		Status:    "draft",
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 
-	if err := s.Repo.CreateReference(ref); err != nil {
+	// Mongo + Postgres lewat saga: bila reference gagal, dokumen Mongo dihapus lagi
+	if err := s.Saga.CreateAchievement(req, &ref); err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error":  "failed insert reference to postgres",
+			"error":  "failed to create achievement",
 			"detail": err.Error(),
 		})
 	}
@@ -119,10 +123,32 @@ func (s *AchievementService) GetAchievementDetail(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.JSON(fiber.Map{
+	resp := fiber.Map{
 		"reference":   ref,
 		"achievement": ach,
-	})
+	}
+
+	// lampiran + link pratinjau untuk yang berhak melihat bukti
+	if canRead, _ := s.attachmentAccess(c, ref); canRead {
+		if rows, err := s.Repo.GetAttachments(refID); err == nil {
+			resp["attachments"] = attachmentLinks(rows)
+		}
+	}
+
+	// ronde revisi yang masih terbuka → tampilkan komentarnya
+	if ref.Status == workflow.StatusRevisionRequested {
+		history, err := s.Repo.GetHistory(refID)
+		if err == nil {
+			for i := len(history) - 1; i >= 0; i-- {
+				if history[i].NewStatus == workflow.StatusRevisionRequested {
+					resp["revision"] = history[i]
+					break
+				}
+			}
+		}
+	}
+
+	return c.JSON(resp)
 }
 
 // ----------------------- SUBMIT ----------------------------
@@ -154,34 +180,62 @@ func (s *AchievementService) SubmitAchievement(c *fiber.Ctx) error {
 		return c.Status(http.StatusForbidden).JSON(fiber.Map{"error": "not allowed"})
 	}
 
-	if ref.Status != "draft" {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "only draft can be submitted"})
+	next, err := workflow.Next(ref.Status, workflow.ActionSubmit)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "only draft or revision-requested achievements can be submitted"})
 	}
 
-	if err := s.Repo.Submit(refID); err != nil {
+	// rantai approver ditentukan dari kategori & tingkat prestasi
+	// (di-resolve ulang saat resubmit karena kategori bisa saja diubah)
+	ach, err := s.Repo.GetAchievementMongo(ref.MongoAchievementID)
+	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error":  "failed to submit",
+			"error":  "failed retrieve achievement",
 			"detail": err.Error(),
 		})
 	}
 
-	_ = s.Repo.AddHistory(refID, "draft", "submitted", userIDStr, "")
+	stages, err := s.Workflow.StagesFor(ach.Category, ach.Level)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":  "failed resolve workflow",
+			"detail": err.Error(),
+		})
+	}
 
-	if student.AdvisorID != nil {
-		_ = s.Repo.CreateNotification(
-			*student.AdvisorID,
-			"Pengajuan Prestasi Baru",
-			"Mahasiswa mengirim pengajuan prestasi untuk diverifikasi.",
-		)
+	notes := s.Notifier.NewBatch()
+	s.notifyStageApprovers(notes, stages[0], student, refID)
+
+	if err := s.Repo.ApplyTransition(model.StatusChange{
+		RefID:         refID,
+		FromStatus:    ref.Status,
+		ToStatus:      next,
+		FromStage:     ref.CurrentStage,
+		ToStage:       0,
+		Stages:        stages,
+		ChangedBy:     userIDStr,
+		Notifications: notes.List,
+	}); err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":  "failed to submit",
+			"detail": err.Error(),
+		})
 	}
 
+	notes.Publish()
+	s.publishStatus(refID, next, stages[0], student)
+
 	return c.JSON(fiber.Map{
-		"message": "submitted",
-		"status":  "submitted",
+		"message":       "submitted",
+		"status":        next,
+		"stages":        stages,
+		"pending_stage": stages[0],
 	})
 }
 
 // ------------------------- VERIFY ----------------------------
+// Approve stage yang sedang berjalan. Stage terakhir → verified,
+// selain itu reference maju ke stage berikutnya.
 func (s *AchievementService) VerifyAchievement(c *fiber.Ctx) error {
 	refID := c.Params("id")
 	if refID == "" {
@@ -189,45 +243,79 @@ func (s *AchievementService) VerifyAchievement(c *fiber.Ctx) error {
 	}
 
 	userID := c.Locals("user_id").(string)
-	role := c.Locals("role").(string)
-
-	if role != "lecturer" && role != "admin" {
-		return c.Status(http.StatusForbidden).JSON(fiber.Map{"error": "only lecturer or admin can verify"})
-	}
 
 	ref, err := s.Repo.GetReferenceByID(refID)
 	if err != nil {
 		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "reference not found"})
 	}
 
-	if ref.Status != "submitted" {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "only submitted achievements can be verified"})
+	stages := []string(ref.WorkflowStages)
+	if len(stages) == 0 {
+		stages = workflow.DefaultStages
 	}
 
-	student, err := s.StudentRepo.GetByID(ref.StudentID)
+	action := workflow.ApprovalAction(stages, ref.CurrentStage)
+	next, err := workflow.Next(ref.Status, action)
 	if err != nil {
-		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "student not found"})
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "only submitted achievements can be verified"})
 	}
 
-	if role == "lecturer" {
-		lecturerID, err := s.StudentRepo.GetLecturerIDByUserID(userID)
-		if err != nil {
-			return c.Status(http.StatusForbidden).JSON(fiber.Map{"error": "lecturer profile not found"})
-		}
-		if student.AdvisorID == nil || *student.AdvisorID != lecturerID {
-			return c.Status(http.StatusForbidden).JSON(fiber.Map{"error": "not your advisee"})
-		}
+	stage, student, errStatus, errMsg := s.authorizeStage(c, ref)
+	if errStatus != 0 {
+		return c.Status(errStatus).JSON(fiber.Map{"error": errMsg})
+	}
+
+	nextStage := ref.CurrentStage
+	if action == workflow.ActionAdvance {
+		nextStage++
+	}
+
+	notes := s.Notifier.NewBatch()
+	if action == workflow.ActionAdvance {
+		s.notifyStageApprovers(notes, stages[nextStage], student, refID)
+		notes.Add(
+			student.UserID,
+			model.NotifStageApproved,
+			fiber.Map{"Stage": stage, "NextStage": stages[nextStage]},
+			refID,
+		)
+	} else {
+		notes.Add(student.UserID, model.NotifAchievementVerified, nil, refID)
+	}
+
+	if err := s.Repo.ApplyTransition(model.StatusChange{
+		RefID:         refID,
+		FromStatus:    ref.Status,
+		ToStatus:      next,
+		FromStage:     ref.CurrentStage,
+		ToStage:       nextStage,
+		StageName:     stage,
+		ChangedBy:     userID,
+		Notifications: notes.List,
+	}); err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":  "failed update status",
+			"detail": err.Error(),
+		})
 	}
+	notes.Publish()
+
+	if action == workflow.ActionAdvance {
+		s.publishStatus(refID, next, stages[nextStage], student)
 
-	if err := s.Repo.UpdateReferenceStatus(refID, "verified", &userID); err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "failed update status"})
+		return c.JSON(fiber.Map{
+			"message":       "stage approved",
+			"status":        next,
+			"approved":      stage,
+			"pending_stage": stages[nextStage],
+		})
 	}
 
-	_ = s.Repo.AddHistory(refID, "submitted", "verified", userID, "")
+	s.publishStatus(refID, next, "", student)
 
 	return c.JSON(fiber.Map{
 		"message": "achievement verified",
-		"status":  "verified",
+		"status":  next,
 	})
 }
 
@@ -239,11 +327,6 @@ func (s *AchievementService) RejectAchievement(c *fiber.Ctx) error {
 	}
 
 	userID := c.Locals("user_id").(string)
-	role := c.Locals("role").(string)
-
-	if role != "lecturer" && role != "admin" {
-		return c.Status(http.StatusForbidden).JSON(fiber.Map{"error": "only lecturer or admin can reject"})
-	}
 
 	var body struct {
 		Note string `json:"note"`
@@ -257,160 +340,256 @@ func (s *AchievementService) RejectAchievement(c *fiber.Ctx) error {
 		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "reference not found"})
 	}
 
-	if ref.Status != "submitted" {
+	next, err := workflow.Next(ref.Status, workflow.ActionReject)
+	if err != nil {
 		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "only submitted achievements can be rejected"})
 	}
 
-	student, err := s.StudentRepo.GetByID(ref.StudentID)
-	if err != nil {
-		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "student not found"})
+	stage, student, errStatus, errMsg := s.authorizeStage(c, ref)
+	if errStatus != 0 {
+		return c.Status(errStatus).JSON(fiber.Map{"error": errMsg})
 	}
 
-	if role == "lecturer" {
-		lecturerID, err := s.StudentRepo.GetLecturerIDByUserID(userID)
-		if err != nil {
-			return c.Status(http.StatusForbidden).JSON(fiber.Map{"error": "lecturer profile not found"})
-		}
-		if student.AdvisorID == nil || *student.AdvisorID != lecturerID {
-			return c.Status(http.StatusForbidden).JSON(fiber.Map{"error": "not your advisee"})
-		}
-	}
+	// notifikasi ke akun user mahasiswa (bukan students.id)
+	notes := s.Notifier.NewBatch()
+	notes.Add(
+		student.UserID,
+		model.NotifAchievementRejected,
+		fiber.Map{"Note": body.Note},
+		refID,
+	)
 
-	if err := s.Repo.RejectReference(refID, userID, body.Note); err != nil {
+	if err := s.Repo.ApplyTransition(model.StatusChange{
+		RefID:         refID,
+		FromStatus:    ref.Status,
+		ToStatus:      next,
+		FromStage:     ref.CurrentStage,
+		ToStage:       ref.CurrentStage,
+		StageName:     stage,
+		ChangedBy:     userID,
+		Note:          body.Note,
+		Notifications: notes.List,
+	}); err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "failed reject achievement"})
 	}
 
-	_ = s.Repo.AddHistory(refID, "submitted", "rejected", userID, body.Note)
-
-	_ = s.Repo.CreateNotification(
-		ref.StudentID,
-		"Prestasi Ditolak",
-		"Prestasi Anda ditolak dengan catatan: "+body.Note,
-	)
+	notes.Publish()
+	s.publishStatus(refID, next, "", student)
 
 	return c.JSON(fiber.Map{
 		"message": "achievement rejected",
-		"status":  "rejected",
+		"status":  next,
 	})
 }
 
-// ------------------------- HISTORY ----------------------------
-func (s *AchievementService) GetAchievementHistory(c *fiber.Ctx) error {
+// ------------------------- REQUEST REVISION ----------------------------
+// POST /api/v1/achievements/:id/request-revision
+// Mengembalikan prestasi ke mahasiswa dengan komentar per-field.
+func (s *AchievementService) RequestRevision(c *fiber.Ctx) error {
 	refID := c.Params("id")
 	if refID == "" {
 		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "missing id"})
 	}
 
-	s.Repo.EnsureDBs()
+	userID := c.Locals("user_id").(string)
 
-	var rows []struct {
-		ID               string    `db:"id" json:"id"`
-		AchievementRefID string    `db:"achievement_ref_id" json:"achievement_ref_id"`
-		OldStatus        string    `db:"old_status" json:"old_status"`
-		NewStatus        string    `db:"new_status" json:"new_status"`
-		ChangedBy        string    `db:"changed_by" json:"changed_by"`
-		Note             string    `db:"note" json:"note"`
-		ChangedAt        time.Time `db:"changed_at" json:"changed_at"`
+	var body struct {
+		Note     string `json:"note"`
+		Comments []struct {
+			Field   string `json:"field"`
+			Comment string `json:"comment"`
+		} `json:"comments"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if body.Note == "" && len(body.Comments) == 0 {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "note or comments required"})
 	}
 
-	q := `SELECT id, achievement_ref_id, old_status, new_status, changed_by, note, changed_at
-		  FROM achievement_history WHERE achievement_ref_id=$1 ORDER BY changed_at ASC`
+	comments := []model.RevisionComment{}
+	for _, cm := range body.Comments {
+		field := strings.TrimSpace(cm.Field)
+		if !workflow.RevisableFields[field] {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "unknown field: " + cm.Field})
+		}
+		if strings.TrimSpace(cm.Comment) == "" {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "comment required for field: " + field})
+		}
+		comments = append(comments, model.RevisionComment{Field: field, Comment: cm.Comment})
+	}
 
-	if err := s.Repo.Psql.Select(&rows, q, refID); err != nil {
+	ref, err := s.Repo.GetReferenceByID(refID)
+	if err != nil {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "reference not found"})
+	}
+
+	next, err := workflow.Next(ref.Status, workflow.ActionRequestRevision)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "only submitted achievements can be returned for revision"})
+	}
+
+	stage, student, errStatus, errMsg := s.authorizeStage(c, ref)
+	if errStatus != 0 {
+		return c.Status(errStatus).JSON(fiber.Map{"error": errMsg})
+	}
+
+	notes := s.Notifier.NewBatch()
+	notes.Add(student.UserID, model.NotifRevisionRequested, fiber.Map{"Note": body.Note}, refID)
+
+	// saat resubmit approval dimulai lagi dari stage pertama
+	if err := s.Repo.ApplyTransition(model.StatusChange{
+		RefID:         refID,
+		FromStatus:    ref.Status,
+		ToStatus:      next,
+		FromStage:     ref.CurrentStage,
+		ToStage:       0,
+		StageName:     stage,
+		ChangedBy:     userID,
+		Note:          body.Note,
+		Comments:      comments,
+		Notifications: notes.List,
+	}); err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error":  "failed retrieve history",
+			"error":  "failed request revision",
 			"detail": err.Error(),
 		})
 	}
 
-	return c.JSON(fiber.Map{"history": rows})
+	notes.Publish()
+	s.publishStatus(refID, next, "", student)
+
+	return c.JSON(fiber.Map{
+		"message":  "revision requested",
+		"status":   next,
+		"comments": comments,
+	})
 }
 
-// ------------------------- ATTACHMENT ----------------------------
-func (s *AchievementService) UploadAttachment(c *fiber.Ctx) error {
-	refID := c.Params("id")
-	if refID == "" {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "missing id",
-		})
+// authorizeStage memastikan user boleh memutuskan stage yang sedang
+// berjalan (policy achievement:decide). Mengembalikan nama stage &
+// mahasiswa, atau status & pesan error.
+func (s *AchievementService) authorizeStage(c *fiber.Ctx, ref *model.AchievementReference) (string, *model.Student, int, string) {
+	stage := workflow.CurrentStage(ref.WorkflowStages, ref.CurrentStage)
+
+	student, err := s.StudentRepo.GetByID(ref.StudentID)
+	if err != nil {
+		return "", nil, http.StatusNotFound, "student not found"
 	}
 
-	// user dari JWT
-	userID := c.Locals("user_id")
-	if userID == nil {
-		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
-			"error": "missing user in token",
-		})
+	res := policy.Resource{Kind: policy.KindAchievement, ID: ref.ID}
+	if policy.Default == nil || !policy.Default.Allowed(policy.SubjectFrom(c), "achievement:decide", res) {
+		if stage == workflow.StageAdvisor {
+			return "", nil, http.StatusForbidden, "not your advisee"
+		}
+		return "", nil, http.StatusForbidden, "waiting for approval by " + stage
 	}
-	userIDStr := userID.(string)
 
-	// ===============================
-	// Ambil file dari form-data
-	// ===============================
-	file, err := c.FormFile("file")
-	if err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "file is required",
-		})
+	return stage, student, 0, ""
+}
+
+// notifyStageApprovers menambahkan notifikasi untuk approver stage
+// berikutnya ke notes
+func (s *AchievementService) notifyStageApprovers(notes *NotificationBatch, stage string, student *model.Student, refID string) {
+	data := fiber.Map{"NIM": student.StudentID}
+
+	if stage == workflow.StageAdvisor {
+		if student.AdvisorID != nil {
+			notes.AddLecturer(*student.AdvisorID, model.NotifAchievementSubmitted, data, refID)
+		}
+		return
 	}
 
-	// pastikan folder uploads ada
-	uploadDir := "uploads"
-	if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed create upload directory",
-		})
+	// kepala departemen mahasiswa bila sudah ditetapkan, selain itu semua
+	// pemegang role stage
+	if stage == workflow.StageHeadOfDepartment {
+		if head, err := s.StudentRepo.GetDepartmentHead(student.ID); err == nil && head != "" {
+			notes.Add(head, model.NotifAchievementSubmitted, data, refID)
+			return
+		}
 	}
 
-	// ===============================
-	// Generate nama file aman & unik
-	// ===============================
-	ext := filepath.Ext(file.Filename)
-	fileName := fmt.Sprintf(
-		"%s_%s%s",
-		refID,
-		uuid.New().String(),
-		ext,
-	)
+	notes.AddRole(stage, model.NotifAchievementSubmitted, data, refID)
+}
 
-	filePath := filepath.Join(uploadDir, fileName)
+// publishStatus mendorong perubahan status ke mahasiswa & dosen pembimbingnya
+func (s *AchievementService) publishStatus(refID string, status string, pendingStage string, student *model.Student) {
+	recipients := []string{student.UserID}
 
-	// ===============================
-	// Simpan file ke folder uploads/
-	// ===============================
-	if err := c.SaveFile(file, filePath); err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed save file",
-		})
+	if student.AdvisorID != nil {
+		if advisorUserID, err := s.Notifier.Repo.GetLecturerUserID(*student.AdvisorID); err == nil {
+			recipients = append(recipients, advisorUserID)
+		}
 	}
 
-	// ===============================
-	// Tentukan file type
-	// ===============================
-	fileType := c.FormValue("file_type")
-	if fileType == "" {
-		fileType = strings.TrimPrefix(ext, ".")
+	s.Notifier.PublishStatusChange(refID, status, pendingStage, recipients...)
+}
+
+// ------------------------- PENDING ----------------------------
+// GET /api/v1/achievements/pending
+// Antrian approval untuk stage berbasis role (mis. head_of_department).
+// Stage advisor dilayani oleh /lecturers/:id/advisees.
+func (s *AchievementService) GetPendingApprovals(c *fiber.Ctx) error {
+	role := c.Locals("role").(string)
+
+	// achievements:manage melihat antrean semua stage
+	var stages []string
+	if !policy.SubjectFrom(c).HasPermission("achievements:manage") {
+		stages = []string{role}
+	}
+
+	p, err := listing.Parse(c, repository.ReferenceListSpec)
+	if err != nil {
+		return listing.Fail(c, err)
+	}
+
+	where, args := repository.PendingCondition(stages)
+	if role == workflow.StageHeadOfDepartment {
+		// departemen yang sudah punya kepala hanya masuk antrean kepalanya
+		userID, _ := c.Locals("user_id").(string)
+		where = append(where, `ar.student_id IN (
+			SELECT student_id FROM student_units
+			WHERE head_user_id IS NULL OR head_user_id = ?
+		)`)
+		args = append(args, userID)
+	}
+	page, err := s.Repo.ListReferences(p, where, args...)
+	if err != nil {
+		return listing.Fail(c, err)
+	}
+
+	results, problems, err := withAchievementDetails(s.Repo, page.Items)
+	if err != nil {
+		return listing.Fail(c, err)
+	}
+	for _, item := range results {
+		ref := item["reference"].(model.AchievementReference)
+		item["pending_stage"] = workflow.CurrentStage(ref.WorkflowStages, ref.CurrentStage)
+	}
+
+	return listing.Respond(c, results, page.Meta, fiber.Map{"integrity_errors": problems})
+}
+
+// ------------------------- HISTORY ----------------------------
+func (s *AchievementService) GetAchievementHistory(c *fiber.Ctx) error {
+	refID := c.Params("id")
+	if refID == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "missing id"})
 	}
 
-	// ===============================
-	// Simpan ke database
-	// ===============================
 	s.Repo.EnsureDBs()
 
-	if err := s.Repo.AddAttachment(refID, filePath, fileType, userIDStr); err != nil {
+	rows, err := s.Repo.GetHistory(refID)
+	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error":  "failed save attachment",
+			"error":  "failed retrieve history",
 			"detail": err.Error(),
 		})
 	}
 
-	return c.JSON(fiber.Map{
-		"message":  "attachment uploaded",
-		"file_url": filePath,
-	})
+	return c.JSON(fiber.Map{"history": rows})
 }
 
-
 // ------------------------- DELETE ----------------------------
 func (s *AchievementService) DeleteAchievement(c *fiber.Ctx) error {
 	refID := c.Params("id")
@@ -440,20 +619,22 @@ func (s *AchievementService) DeleteAchievement(c *fiber.Ctx) error {
 		return c.Status(http.StatusForbidden).JSON(fiber.Map{"error": "not allowed"})
 	}
 
-	if ref.Status != "draft" {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "only draft achievements can be deleted"})
-	}
-
-	if err := s.Repo.SoftDeleteMongo(ref.MongoAchievementID); err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error":  "failed to soft delete mongo",
-			"detail": err.Error(),
-		})
-	}
-
-	if err := s.Repo.SoftDeleteReference(refID); err != nil {
+	next, err := workflow.Next(ref.Status, workflow.ActionDelete)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "only draft or revision-requested achievements can be deleted"})
+	}
+
+	// soft delete Mongo + status Postgres lewat saga: bila status gagal, dokumen dipulihkan
+	if err := s.Saga.DeleteAchievement(ref, model.StatusChange{
+		RefID:      refID,
+		FromStatus: ref.Status,
+		ToStatus:   next,
+		FromStage:  ref.CurrentStage,
+		ToStage:    ref.CurrentStage,
+		ChangedBy:  userIDStr,
+	}); err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error":  "failed to update reference",
+			"error":  "failed to delete achievement",
 			"detail": err.Error(),
 		})
 	}
@@ -468,63 +649,112 @@ func (s *AchievementService) DeleteAchievement(c *fiber.Ctx) error {
 func (s *AchievementService) GetAdviseeAchievements(c *fiber.Ctx) error {
 	lecturerID := c.Params("id")
 
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	p, err := listing.Parse(c, repository.ReferenceListSpec)
+	if err != nil {
+		return listing.Fail(c, err)
+	}
+
+	// hanya yang sedang menunggu stage advisor
+	where, args := repository.PendingCondition([]string{workflow.StageAdvisor})
+	where = append(where, "ar.student_id IN (SELECT id FROM students WHERE advisor_id = ?)")
+	args = append(args, lecturerID)
 
-	if page < 1 {
-		page = 1
+	page, err := s.Repo.ListReferences(p, where, args...)
+	if err != nil {
+		return listing.Fail(c, err)
+	}
+
+	refIDs := make([]string, 0, len(page.Items))
+	for _, ref := range page.Items {
+		refIDs = append(refIDs, ref.ID)
 	}
-	if limit < 1 {
-		limit = 10
+	// thumbnail bukti supaya dosen tidak perlu mengunduh tiap sertifikat
+	attachments, err := s.Repo.GetAttachmentsByRefIDs(refIDs)
+	if err != nil {
+		log.Println("advisee attachments:", err)
 	}
-	offset := (page - 1) * limit
 
-	studentIDs, err := s.StudentRepo.GetStudentIDsByAdvisor(lecturerID)
-	if err != nil || len(studentIDs) == 0 {
-		return c.JSON(fiber.Map{
-			"data":  []any{},
-			"page":  page,
-			"limit": limit,
-		})
+	results, problems, err := withAchievementDetails(s.Repo, page.Items)
+	if err != nil {
+		return listing.Fail(c, err)
+	}
+	for _, item := range results {
+		ref := item["reference"].(model.AchievementReference)
+		item["attachments"] = attachmentLinks(attachments[ref.ID])
 	}
 
-	refs, err := s.Repo.GetReferencesByStudentIDs(studentIDs, limit, offset)
+	return listing.Respond(c, results, page.Meta, fiber.Map{"integrity_errors": problems})
+}
+
+// GET /api/v1/achievements (ADMIN)
+// ?sort= &status= &student_id= &created_from= &created_until= &cursor= &limit=
+func (s *AchievementService) GetAll(c *fiber.Ctx) error {
+	p, err := listing.Parse(c, repository.ReferenceListSpec)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return listing.Fail(c, err)
 	}
 
-	results := []any{}
-	for _, ref := range refs {
-		detail, err := s.Repo.GetAchievementMongoDetail(ref.MongoAchievementID)
-		if err != nil {
-			fmt.Println("Mongo miss:", ref.MongoAchievementID)
-			continue
-		}
+	// admin unit hanya melihat prestasi mahasiswa di unitnya
+	var where []string
+	var args []interface{}
+	if scope := policy.ScopeFrom(c); scope != nil {
+		cond, scopeArgs := repository.UnitScopeCondition(scope, "ar.student_id")
+		where, args = []string{cond}, scopeArgs
+	}
 
-		results = append(results, fiber.Map{
-			"reference": ref,
-			"detail":    detail,
-		})
+	page, err := s.Repo.ListReferences(p, where, args...)
+	if err != nil {
+		return listing.Fail(c, err)
 	}
 
-	return c.JSON(fiber.Map{
-		"data":  results,
-		"page":  page,
-		"limit": limit,
-	})
+	results, problems, err := withAchievementDetails(s.Repo, page.Items)
+	if err != nil {
+		return listing.Fail(c, err)
+	}
+
+	return listing.Respond(c, results, page.Meta, fiber.Map{"integrity_errors": problems})
 }
 
-// GET /api/v1/achievements (ADMIN)
-func (s *AchievementService) GetAll(c *fiber.Ctx) error {
-	data, err := s.Repo.GetAllReferencesWithDetail()
+// withAchievementDetails menggabungkan reference dengan dokumen Mongo-nya
+// memakai satu query $in per halaman. Urutan mengikuti refs; reference yang
+// dokumennya hilang tetap dikembalikan (detail null) dan dicatat sebagai
+// integrity error.
+func withAchievementDetails(repo *repository.AchievementRepo, refs []model.AchievementReference) ([]fiber.Map, []model.IntegrityError, error) {
+	ids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		ids = append(ids, ref.MongoAchievementID)
+	}
+
+	docs, err := repo.GetAchievementMongoDetails(ids)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"error": "failed fetch achievements",
-		})
+		return nil, nil, err
 	}
-	return c.JSON(fiber.Map{
-		"data": data,
-	})
+
+	results := make([]fiber.Map, 0, len(refs))
+	problems := []model.IntegrityError{}
+	for _, ref := range refs {
+		detail, ok := docs[ref.MongoAchievementID]
+		if !ok {
+			reason := "mongo document not found"
+			if !primitive.IsValidObjectID(ref.MongoAchievementID) {
+				reason = "invalid mongo achievement id"
+			}
+			log.Printf("integrity: reference %s → %s: %s", ref.ID, ref.MongoAchievementID, reason)
+			problems = append(problems, model.IntegrityError{
+				RefID:              ref.ID,
+				MongoAchievementID: ref.MongoAchievementID,
+				Reason:             reason,
+			})
+		}
+
+		item := fiber.Map{"reference": ref, "detail": nil}
+		if ok {
+			item["detail"] = detail
+		}
+		results = append(results, item)
+	}
+
+	return results, problems, nil
 }
 
 // PUT /api/v1/achievements/:id (STUDENT, DRAFT ONLY)
@@ -540,14 +770,44 @@ func (s *AchievementService) UpdateAchievement(c *fiber.Ctx) error {
 		})
 	}
 
-	err := s.Repo.UpdateDraftAchievement(refID, userID, body)
+	previous, err := s.Repo.UpdateDraftAchievement(refID, userID, body)
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
+	// jejak audit: hanya field yang dikirim (body sudah berisi updated_at)
+	before := fiber.Map{}
+	for key := range body {
+		before[key] = documentField(previous, key)
+	}
+	audit.Record(c, "achievement", refID, before, body)
+
 	return c.JSON(fiber.Map{
 		"message": "achievement updated",
 	})
 }
+
+// documentField membaca field dokumen Mongo, termasuk path bertitik
+// ("details.score"); nil bila tidak ada
+func documentField(doc bson.M, path string) interface{} {
+	var cur interface{} = doc
+	for _, part := range strings.Split(path, ".") {
+		switch m := cur.(type) {
+		case bson.M:
+			cur = m[part]
+		case bson.D:
+			var next interface{}
+			for _, e := range m {
+				if e.Key == part {
+					next = e.Value
+				}
+			}
+			cur = next
+		default:
+			return nil
+		}
+	}
+	return cur
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// =====================
+// ATTACHMENT VALIDATION
+// =====================
+
+const (
+	mimePDF  = "application/pdf"
+	mimePNG  = "image/png"
+	mimeJPEG = "image/jpeg"
+)
+
+// attachmentExtensions: ekstensi yang sah untuk tiap MIME hasil sniffing
+var attachmentExtensions = map[string][]string{
+	mimePDF:  {".pdf"},
+	mimePNG:  {".png"},
+	mimeJPEG: {".jpg", ".jpeg"},
+}
+
+// attachmentFileTypes: nilai file_type yang disimpan, diturunkan dari isi file
+var attachmentFileTypes = map[string]string{
+	mimePDF:  "pdf",
+	mimePNG:  "png",
+	mimeJPEG: "jpeg",
+}
+
+// allowedAttachmentTypes: allow-list per kategori prestasi (lowercase).
+// Kategori yang tidak terdaftar memakai defaultAttachmentTypes.
+var allowedAttachmentTypes = map[string][]string{
+	"publikasi": {mimePDF},
+	"kompetisi": {mimePDF, mimePNG, mimeJPEG},
+	"seminar":   {mimePDF, mimePNG, mimeJPEG},
+}
+
+var defaultAttachmentTypes = []string{mimePDF, mimePNG, mimeJPEG}
+
+// AttachmentViolation adalah satu pelanggaran validasi lampiran
+type AttachmentViolation struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidatedAttachment adalah hasil validasi yang dipakai saat menyimpan
+type ValidatedAttachment struct {
+	ContentType string
+	FileType    string
+}
+
+func allowedTypesFor(category string) []string {
+	if types, ok := allowedAttachmentTypes[strings.ToLower(strings.TrimSpace(category))]; ok {
+		return types
+	}
+	return defaultAttachmentTypes
+}
+
+// validateAttachment memeriksa ukuran, MIME asli (dari isi, bukan header
+// client), allow-list kategori, dan kecocokan ekstensi nama file.
+func validateAttachment(file *multipart.FileHeader, category string, maxSize int64) (*ValidatedAttachment, []AttachmentViolation) {
+	var violations []AttachmentViolation
+
+	if file.Size == 0 {
+		return nil, []AttachmentViolation{{
+			Field: "file", Code: "empty", Message: "file is empty",
+		}}
+	}
+	if maxSize > 0 && file.Size > maxSize {
+		violations = append(violations, AttachmentViolation{
+			Field:   "file",
+			Code:    "too_large",
+			Message: fmt.Sprintf("file is %d bytes, maximum is %d bytes", file.Size, maxSize),
+		})
+	}
+
+	contentType, err := sniffContentType(file)
+	if err != nil {
+		return nil, append(violations, AttachmentViolation{
+			Field: "file", Code: "unreadable", Message: "failed read file",
+		})
+	}
+
+	allowed := allowedTypesFor(category)
+	if !containsString(allowed, contentType) {
+		violations = append(violations, AttachmentViolation{
+			Field:   "file",
+			Code:    "type_not_allowed",
+			Message: fmt.Sprintf("%s is not allowed for category %q (allowed: %s)", contentType, category, strings.Join(allowed, ", ")),
+		})
+	}
+
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	if exts, ok := attachmentExtensions[contentType]; ok && !containsString(exts, ext) {
+		violations = append(violations, AttachmentViolation{
+			Field:   "filename",
+			Code:    "extension_mismatch",
+			Message: fmt.Sprintf("extension %q does not match file content (%s)", ext, contentType),
+		})
+	}
+
+	if len(violations) > 0 {
+		return nil, violations
+	}
+
+	return &ValidatedAttachment{
+		ContentType: contentType,
+		FileType:    attachmentFileTypes[contentType],
+	}, nil
+}
+
+// sniffContentType membaca 512 byte pertama untuk mendeteksi MIME
+func sniffContentType(file *multipart.FileHeader) (string, error) {
+	f, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+
+	ct := http.DetectContentType(head[:n])
+	if i := strings.Index(ct, ";"); i >= 0 {
+		ct = ct[:i]
+	}
+	return ct, nil
+}
+
+func containsString(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
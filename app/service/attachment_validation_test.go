@@ -0,0 +1,103 @@
+package service
+
+import (
+	"bytes"
+	"mime/multipart"
+	"testing"
+)
+
+var (
+	pdfData  = []byte("%PDF-1.4\n1 0 obj\n<< /Type /Page >>\nendobj\n%%EOF\n")
+	pngData  = append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...)
+	jpegData = append([]byte("\xFF\xD8\xFF\xE0"), make([]byte, 32)...)
+	textData = []byte("bukan sertifikat, hanya teks biasa\n")
+)
+
+// multipartFile membuat *multipart.FileHeader di memori seperti hasil
+// parsing form upload
+func multipartFile(t *testing.T, name string, data []byte) *multipart.FileHeader {
+	t.Helper()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data)
+	w.Close()
+
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { form.RemoveAll() })
+	return form.File["file"][0]
+}
+
+func TestValidateAttachment(t *testing.T) {
+	tests := []struct {
+		name        string
+		filename    string
+		data        []byte
+		category    string
+		maxSize     int64
+		contentType string   // diisi bila lolos
+		codes       []string // diisi bila ditolak
+	}{
+		// lolos
+		{"pdf", "sertifikat.pdf", pdfData, "kompetisi", 0, mimePDF, nil},
+		{"png", "foto.png", pngData, "seminar", 0, mimePNG, nil},
+		{"jpeg extension", "foto.jpeg", jpegData, "kompetisi", 0, mimeJPEG, nil},
+		{"uppercase jpg extension", "FOTO.JPG", jpegData, "kompetisi", 0, mimeJPEG, nil},
+
+		// ekstensi palsu: MIME dibaca dari isi, bukan nama file
+		{"png named pdf", "sertifikat.pdf", pngData, "kompetisi", 0, "", []string{"extension_mismatch"}},
+		{"pdf named png", "foto.png", pdfData, "kompetisi", 0, "", []string{"extension_mismatch"}},
+		{"text named pdf", "sertifikat.pdf", textData, "kompetisi", 0, "", []string{"type_not_allowed"}},
+		{"no extension", "sertifikat", pdfData, "kompetisi", 0, "", []string{"extension_mismatch"}},
+
+		// file kosong ditolak sebelum pemeriksaan lain
+		{"empty", "kosong.pdf", nil, "kompetisi", 10, "", []string{"empty"}},
+
+		// batas ukuran inklusif
+		{"at limit", "sertifikat.pdf", pdfData, "kompetisi", int64(len(pdfData)), mimePDF, nil},
+		{"one byte over limit", "sertifikat.pdf", pdfData, "kompetisi", int64(len(pdfData)) - 1, "", []string{"too_large"}},
+		{"over limit and spoofed", "sertifikat.pdf", pngData, "kompetisi", 8, "", []string{"too_large", "extension_mismatch"}},
+
+		// allow-list kategori
+		{"publikasi pdf only", "foto.png", pngData, "publikasi", 0, "", []string{"type_not_allowed"}},
+		{"category case and spaces", "foto.png", pngData, " Publikasi ", 0, "", []string{"type_not_allowed"}},
+		{"unknown category falls back", "foto.png", pngData, "hackathon", 0, mimePNG, nil},
+		{"empty category falls back", "foto.jpg", jpegData, "", 0, mimeJPEG, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := multipartFile(t, tt.filename, tt.data)
+			v, violations := validateAttachment(file, tt.category, tt.maxSize)
+
+			if tt.codes == nil {
+				if len(violations) > 0 {
+					t.Fatalf("violations = %+v", violations)
+				}
+				if v.ContentType != tt.contentType || v.FileType != attachmentFileTypes[tt.contentType] {
+					t.Fatalf("validated = %+v, want %s", v, tt.contentType)
+				}
+				return
+			}
+
+			if v != nil {
+				t.Fatalf("validated = %+v, want violations %v", v, tt.codes)
+			}
+			if len(violations) != len(tt.codes) {
+				t.Fatalf("violations = %+v, want %v", violations, tt.codes)
+			}
+			for i, code := range tt.codes {
+				if violations[i].Code != code {
+					t.Fatalf("violations = %+v, want %v", violations, tt.codes)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"project_uas/app/listing"
+	"project_uas/app/repository"
+)
+
+type AuditService struct {
+	Repo *repository.AuditRepo
+}
+
+func NewAuditService(repo *repository.AuditRepo) *AuditService {
+	return &AuditService{Repo: repo}
+}
+
+// =====================
+// GET /audit-logs
+// =====================
+// ?actor_user_id= &action= &resource_type= &resource_id= &request_id=
+// &method= &failed= &from= &until= &q= &cursor= &limit=
+// mis. siapa mengganti dosen wali mahasiswa X:
+// ?resource_type=student&resource_id=X&action=student:assign-advisor,student:bulk-assign-advisor
+func (s *AuditService) GetAll(c *fiber.Ctx) error {
+	p, err := listing.Parse(c, repository.AuditListSpec)
+	if err != nil {
+		return listing.Fail(c, err)
+	}
+
+	page, err := s.Repo.List(p)
+	if err != nil {
+		return listing.Fail(c, err)
+	}
+	return listing.Respond(c, page.Items, page.Meta)
+}
+
+// =====================
+// GET /audit-logs/:id
+// =====================
+func (s *AuditService) GetByID(c *fiber.Ctx) error {
+	row, err := s.Repo.GetByID(c.Params("id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "audit log not found"})
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": row})
+}
@@ -1,10 +1,13 @@
 package service
 
 import (
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
 	"project_uas/helper"
@@ -13,12 +16,14 @@ import (
 )
 
 type AuthService struct {
-	AuthRepo *repository.AuthRepo
+	AuthRepo    *repository.AuthRepo
+	SessionRepo *repository.SessionRepo
 }
 
-func NewAuthService(authRepo *repository.AuthRepo) *AuthService {
+func NewAuthService(authRepo *repository.AuthRepo, sessionRepo *repository.SessionRepo) *AuthService {
 	return &AuthService{
-		AuthRepo: authRepo,
+		AuthRepo:    authRepo,
+		SessionRepo: sessionRepo,
 	}
 }
 
@@ -60,40 +65,29 @@ func (s *AuthService) Login(c *fiber.Ctx) error {
 		})
 	}
 
-	// (5) Ambil role
-	roleName, err := s.AuthRepo.GetRoleNameByID(user.RoleID)
-	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "cannot load role",
-		})
+	// (5) Catat session (perangkat) baru
+	session := model.Session{
+		ID:        uuid.NewString(),
+		UserID:    user.ID,
+		Device:    req.Device,
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
 	}
-
-	// Ambil permissions user
-	perms, err := s.AuthRepo.GetPermissionsByRole(user.RoleID)
-	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "cannot load permissions",
-		})
+	if session.Device == "" {
+		session.Device = describeDevice(session.UserAgent)
 	}
 
-	// (6) Generate Access Token
-	accessToken, err := helper.GenerateAccessToken(
-		user.ID,
-		user.Username,
-		roleName,
-		perms,
-	)
-	if err != nil {
+	if err := s.SessionRepo.Create(&session); err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed generate access token",
+			"error": "failed create session",
 		})
 	}
 
-	// Generate Refresh Token
-	refreshToken, err := helper.GenerateRefreshToken(user.ID, roleName)
+	// (6) Ambil role, permissions & generate token
+	tokens, err := s.issueTokens(user, session.ID)
 	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed generate refresh token",
+			"error": err.Error(),
 		})
 	}
 
@@ -101,20 +95,182 @@ func (s *AuthService) Login(c *fiber.Ctx) error {
 	return c.Status(http.StatusOK).JSON(fiber.Map{
 		"status": "success",
 		"data": fiber.Map{
-			"token":        accessToken,
-			"refreshToken": refreshToken,
+			"token":        tokens.AccessToken,
+			"refreshToken": tokens.RefreshToken,
 			"user": fiber.Map{
 				"id":          user.ID,
 				"username":    user.Username,
 				"email":       user.Email,
 				"fullName":    user.FullName,
-				"role":        roleName,
-				"permissions": perms,
+				"role":        tokens.Role,
+				"permissions": tokens.Permissions,
 			},
 		},
 	})
 }
 
+// =====================
+//       REFRESH
+// =====================
+func (s *AuthService) Refresh(c *fiber.Ctx) error {
+	var req model.RefreshRequest
+
+	if err := c.BodyParser(&req); err != nil || req.RefreshToken == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "refreshToken is required",
+		})
+	}
+
+	// (1) Validasi signature & expiry
+	claims, err := helper.VerifyRefreshToken(req.RefreshToken)
+	if err != nil {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": "invalid or expired refresh token",
+		})
+	}
+
+	// (2) Cocokkan dengan catatan di server
+	stored, err := s.AuthRepo.FindRefreshToken(claims.ID)
+	if err != nil || stored.FamilyID != claims.FamilyID || stored.UserID != claims.UserID {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": "invalid or expired refresh token",
+		})
+	}
+
+	if stored.RevokedAt != nil {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": "refresh token has been revoked",
+		})
+	}
+
+	if active, err := s.SessionRepo.IsActive(stored.FamilyID); err != nil || !active {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+			"error": "session has been signed out",
+		})
+	}
+
+	// (3) Reuse detection: token lama dipakai lagi → cabut seluruh family
+	if stored.UsedAt != nil {
+		return s.rejectReusedToken(c, stored.FamilyID)
+	}
+
+	// (4) User masih aktif?
+	user, err := s.AuthRepo.FindByID(claims.UserID)
+	if err != nil || !user.IsActive {
+		_ = s.SessionRepo.Revoke(stored.FamilyID)
+		return c.Status(http.StatusForbidden).JSON(fiber.Map{
+			"error": "account disabled",
+		})
+	}
+
+	// (5) Terbitkan pasangan token baru (role & permission dibaca ulang)
+	tokens, err := s.newTokenPair(user, stored.FamilyID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// (6) Rotasi: token lama ditandai terpakai, token baru disimpan
+	rotated, err := s.AuthRepo.RotateRefreshToken(stored.ID, tokens.record)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed rotate refresh token",
+		})
+	}
+	if !rotated {
+		return s.rejectReusedToken(c, stored.FamilyID)
+	}
+
+	_ = s.SessionRepo.Touch(stored.FamilyID, c.IP())
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data": fiber.Map{
+			"token":        tokens.AccessToken,
+			"refreshToken": tokens.RefreshToken,
+		},
+	})
+}
+
+func (s *AuthService) rejectReusedToken(c *fiber.Ctx, familyID string) error {
+	// family == session, jadi session ikut dicabut
+	if err := s.SessionRepo.Revoke(familyID); err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to revoke token family",
+		})
+	}
+
+	return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+		"error": "refresh token reuse detected, please login again",
+	})
+}
+
+// =====================
+//    TOKEN ISSUING
+// =====================
+type issuedTokens struct {
+	AccessToken  string
+	RefreshToken string
+	Role         string
+	Permissions  []string
+
+	record *model.RefreshToken
+}
+
+// newTokenPair membuat access + refresh token untuk family (session)
+// tertentu tanpa menyimpan refresh token-nya (dipakai oleh rotasi).
+func (s *AuthService) newTokenPair(user *model.User, familyID string) (*issuedTokens, error) {
+	roleName, err := s.AuthRepo.GetRoleNameByID(user.RoleID)
+	if err != nil {
+		return nil, errors.New("cannot load role")
+	}
+
+	perms, err := s.AuthRepo.GetPermissionsByRole(user.RoleID)
+	if err != nil {
+		return nil, errors.New("cannot load permissions")
+	}
+
+	accessToken, err := helper.GenerateAccessToken(user.ID, user.Username, roleName, perms, familyID)
+	if err != nil {
+		return nil, errors.New("failed generate access token")
+	}
+
+	record := &model.RefreshToken{
+		ID:        uuid.NewString(),
+		FamilyID:  familyID,
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(helper.RefreshTokenTTL),
+	}
+
+	refreshToken, err := helper.GenerateRefreshToken(user.ID, roleName, record.FamilyID, record.ID, record.ExpiresAt)
+	if err != nil {
+		return nil, errors.New("failed generate refresh token")
+	}
+
+	return &issuedTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		Role:         roleName,
+		Permissions:  perms,
+		record:       record,
+	}, nil
+}
+
+// issueTokens membuat pasangan token pertama untuk session baru (login)
+func (s *AuthService) issueTokens(user *model.User, familyID string) (*issuedTokens, error) {
+	tokens, err := s.newTokenPair(user, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.AuthRepo.SaveRefreshToken(tokens.record); err != nil {
+		return nil, errors.New("failed store refresh token")
+	}
+
+	return tokens, nil
+}
+
 // =====================
 //     GET PROFILE
 // =====================
@@ -180,6 +336,19 @@ func (s *AuthService) Logout(c *fiber.Ctx) error {
 		})
 	}
 
+	// 🔹 session & refresh token family ikut dicabut
+	if claims.SessionID != "" {
+		_ = s.SessionRepo.Revoke(claims.SessionID)
+	}
+
+	// 🔹 refresh token dari body (opsional, untuk token tanpa sid)
+	var body model.RefreshRequest
+	if err := c.BodyParser(&body); err == nil && body.RefreshToken != "" {
+		if rc, err := helper.VerifyRefreshToken(body.RefreshToken); err == nil && rc.UserID == claims.UserID {
+			_ = s.SessionRepo.Revoke(rc.FamilyID)
+		}
+	}
+
 	return c.JSON(fiber.Map{
 		"message": "logout success",
 	})
@@ -5,15 +5,18 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 
+	"project_uas/app/listing"
+	"project_uas/app/policy"
 	"project_uas/app/repository"
 )
 
 type LecturerService struct {
-	Repo *repository.LecturerRepo
+	Repo        *repository.LecturerRepo
+	AdvisorRepo *repository.AdvisorRepo
 }
 
-func NewLecturerService(repo *repository.LecturerRepo) *LecturerService {
-	return &LecturerService{Repo: repo}
+func NewLecturerService(repo *repository.LecturerRepo, advisorRepo *repository.AdvisorRepo) *LecturerService {
+	return &LecturerService{Repo: repo, AdvisorRepo: advisorRepo}
 }
 
 func (s *LecturerService) GetProfile(c *fiber.Ctx) error {
@@ -35,16 +38,80 @@ func (s *LecturerService) GetProfile(c *fiber.Ctx) error {
 	return c.Status(http.StatusOK).JSON(data)
 }
 
+// GET /lecturers ?sort= &department= &department_id= &faculty_id= &q= &cursor= &limit=
 func (s *LecturerService) GetAll(c *fiber.Ctx) error {
-	data, err := s.Repo.GetAll()
+	p, err := listing.Parse(c, repository.LecturerListSpec)
+	if err != nil {
+		return listing.Fail(c, err)
+	}
+
+	page, err := s.Repo.List(p)
+	if err != nil {
+		return listing.Fail(c, err)
+	}
+
+	return listing.Respond(c, page.Items, page.Meta)
+}
+
+
+// PUT /lecturers/:id/department
+// Admin unit hanya boleh memindahkan dosen ke departemen di unitnya
+func (s *LecturerService) UpdateDepartment(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var body struct {
+		DepartmentID string `json:"department_id"`
+	}
+
+	if err := c.BodyParser(&body); err != nil || body.DepartmentID == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "department_id required",
+		})
+	}
+
+	if status, msg := authorizeUnit(c, policy.KindDepartment, body.DepartmentID); status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	ok, err := s.Repo.UpdateDepartment(id, body.DepartmentID)
 	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed get lecturers",
+			"error": err.Error(),
+		})
+	}
+	if !ok {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "lecturer not found",
 		})
 	}
 
-	return c.Status(http.StatusOK).JSON(fiber.Map{
-		"data": data,
+	return c.JSON(fiber.Map{
+		"message": "department updated successfully",
 	})
 }
 
+// GET /lecturers/advisor-load ?department_id=
+// jumlah mahasiswa bimbingan aktif tiap dosen di departemen
+func (s *LecturerService) GetAdvisorLoad(c *fiber.Ctx) error {
+	departmentID := c.Query("department_id")
+	if departmentID == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "department_id required",
+		})
+	}
+
+	if status, msg := authorizeUnit(c, policy.KindDepartment, departmentID); status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	loads, err := s.AdvisorRepo.Loads(departmentID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": loads,
+	})
+}
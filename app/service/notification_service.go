@@ -0,0 +1,502 @@
+package service
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+
+	"project_uas/app/dispatch"
+	"project_uas/app/listing"
+	"project_uas/app/model"
+	"project_uas/app/realtime"
+	"project_uas/app/repository"
+	"project_uas/config"
+	"project_uas/helper"
+)
+
+type NotificationService struct {
+	Repo       *repository.NotificationRepo
+	Events     *realtime.Publisher
+	Hub        *realtime.Hub
+	Templates  *dispatch.Templates
+	Dispatcher *dispatch.Dispatcher
+}
+
+func NewNotificationService(
+	repo *repository.NotificationRepo,
+	events *realtime.Publisher,
+	hub *realtime.Hub,
+	templates *dispatch.Templates,
+	dispatcher *dispatch.Dispatcher,
+) *NotificationService {
+	return &NotificationService{
+		Repo:       repo,
+		Events:     events,
+		Hub:        hub,
+		Templates:  templates,
+		Dispatcher: dispatcher,
+	}
+}
+
+// =====================
+// NOTIFY (dipakai service lain)
+// =====================
+
+// Notify menyimpan notifikasi untuk satu user. Judul & isi dirender dari
+// template sesuai locale user, dan pesan untuk channel eksternal (email)
+// ditulis ke outbox dalam transaksi yang sama. Kegagalan hanya di-log
+// supaya tidak menggagalkan aksi utama (assign advisor, dst).
+func (s *NotificationService) Notify(userID string, notifType string, data map[string]interface{}, refID string) {
+	b := s.NewBatch()
+	b.Add(userID, notifType, data, refID)
+	s.save(b)
+}
+
+// NotifyLecturer mengirim notifikasi ke akun user milik dosen (lecturers.id)
+func (s *NotificationService) NotifyLecturer(lecturerID string, notifType string, data map[string]interface{}, refID string) {
+	b := s.NewBatch()
+	b.AddLecturer(lecturerID, notifType, data, refID)
+	s.save(b)
+}
+
+// NotifyRole mengirim notifikasi ke semua user aktif dengan role tertentu
+func (s *NotificationService) NotifyRole(role string, notifType string, data map[string]interface{}, refID string) {
+	b := s.NewBatch()
+	b.AddRole(role, notifType, data, refID)
+	s.save(b)
+}
+
+// save menyimpan setiap notifikasi di transaksinya sendiri lalu
+// mempublikasikan yang berhasil
+func (s *NotificationService) save(b *NotificationBatch) {
+	saved := b.List[:0]
+	for _, pn := range b.List {
+		if err := s.Repo.Create(&pn.Notification, pn.Outbox); err != nil {
+			log.Println("failed create notification:", err)
+			continue
+		}
+		saved = append(saved, pn)
+	}
+	b.List = saved
+	b.Publish()
+}
+
+// NotificationBatch mengumpulkan notifikasi yang sudah dirender. Untuk
+// transisi workflow, List ikut disimpan oleh ApplyTransition (lihat
+// model.StatusChange) dan Publish dipanggil setelah commit.
+type NotificationBatch struct {
+	s    *NotificationService
+	List []*model.PendingNotification
+}
+
+func (s *NotificationService) NewBatch() *NotificationBatch {
+	return &NotificationBatch{s: s, List: []*model.PendingNotification{}}
+}
+
+// Add merender notifikasi untuk satu user beserta pesan outbox untuk
+// channel eksternal yang diaktifkan user. Kegagalan hanya di-log.
+func (b *NotificationBatch) Add(userID string, notifType string, data map[string]interface{}, refID string) {
+	if userID == "" {
+		return
+	}
+	s := b.s
+
+	recipient, err := s.Repo.GetRecipient(userID)
+	if err != nil {
+		log.Println("failed load notification recipient:", err)
+		return
+	}
+
+	vars := map[string]interface{}{"Name": recipient.FullName}
+	for k, v := range data {
+		vars[k] = v
+	}
+	if refID != "" && config.Env.AppBaseURL != "" {
+		vars["Link"] = strings.TrimRight(config.Env.AppBaseURL, "/") + "/achievements/" + refID
+	}
+
+	rendered, err := s.Templates.Render(recipient.Locale, notifType, vars)
+	if err != nil {
+		log.Println("failed render notification:", err)
+		return
+	}
+
+	pn := &model.PendingNotification{
+		Notification: model.Notification{
+			UserID:  userID,
+			Type:    notifType,
+			Title:   rendered.Title,
+			Message: rendered.Message,
+		},
+		Outbox: []model.OutboxMessage{},
+	}
+	if refID != "" {
+		pn.Notification.ReferenceID = &refID
+	}
+
+	for _, ch := range s.Dispatcher.Channels() {
+		address := ch.Address(*recipient)
+		if address == "" {
+			continue
+		}
+
+		enabled, err := s.Repo.IsChannelEnabled(userID, ch.Name(), notifType)
+		if err != nil {
+			log.Println("failed load notification preference:", err)
+			continue
+		}
+		if !enabled {
+			continue
+		}
+
+		pn.Outbox = append(pn.Outbox, model.OutboxMessage{
+			Channel:   ch.Name(),
+			Recipient: address,
+			Subject:   rendered.Subject,
+			Body:      rendered.Body,
+		})
+	}
+
+	b.List = append(b.List, pn)
+}
+
+// AddLecturer: notifikasi ke akun user milik dosen (lecturers.id)
+func (b *NotificationBatch) AddLecturer(lecturerID string, notifType string, data map[string]interface{}, refID string) {
+	userID, err := b.s.Repo.GetLecturerUserID(lecturerID)
+	if err != nil {
+		log.Println("failed resolve lecturer user:", err)
+		return
+	}
+	b.Add(userID, notifType, data, refID)
+}
+
+// AddRole: notifikasi ke semua user aktif dengan role tertentu
+func (b *NotificationBatch) AddRole(role string, notifType string, data map[string]interface{}, refID string) {
+	userIDs, err := b.s.Repo.GetActiveUserIDsByRole(role)
+	if err != nil {
+		log.Println("failed resolve role recipients:", err)
+		return
+	}
+	for _, id := range userIDs {
+		b.Add(id, notifType, data, refID)
+	}
+}
+
+// Publish mendorong notifikasi yang sudah tersimpan ke client realtime
+func (b *NotificationBatch) Publish() {
+	for _, pn := range b.List {
+		b.s.Events.Publish(realtime.EventNotification, pn.Notification, pn.Notification.UserID)
+	}
+}
+
+// PublishStatusChange mendorong perubahan status prestasi secara realtime
+// (tanpa menyimpan notifikasi), mis. supaya daftar bimbingan dosen ter-update.
+func (s *NotificationService) PublishStatusChange(refID string, status string, pendingStage string, userIDs ...string) {
+	s.Events.Publish(realtime.EventAchievementStatus, fiber.Map{
+		"reference_id":  refID,
+		"status":        status,
+		"pending_stage": pendingStage,
+	}, userIDs...)
+}
+
+// =====================
+// GET /notifications
+// =====================
+func (s *NotificationService) GetAll(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	p, err := listing.Parse(c, repository.NotificationListSpec)
+	if err != nil {
+		return listing.Fail(c, err)
+	}
+
+	page, err := s.Repo.List(userID, p)
+	if err != nil {
+		return listing.Fail(c, err)
+	}
+
+	unread, err := s.Repo.CountUnread(userID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed count notifications",
+		})
+	}
+
+	return listing.Respond(c, page.Items, page.Meta, fiber.Map{"unread_count": unread})
+}
+
+// =====================
+// GET /notifications/unread-count
+// =====================
+func (s *NotificationService) UnreadCount(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	unread, err := s.Repo.CountUnread(userID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed count notifications",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"unread_count": unread,
+	})
+}
+
+// =====================
+// PUT /notifications/:id/read
+// =====================
+func (s *NotificationService) MarkRead(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	ok, err := s.Repo.MarkRead(c.Params("id"), userID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed update notification",
+		})
+	}
+	if !ok {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "notification not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "notification marked as read",
+	})
+}
+
+// =====================
+// PUT /notifications/read-all
+// =====================
+func (s *NotificationService) MarkAllRead(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	n, err := s.Repo.MarkAllRead(userID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed update notifications",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "all notifications marked as read",
+		"updated": n,
+	})
+}
+
+// =====================
+// DELETE /notifications/:id
+// =====================
+func (s *NotificationService) Delete(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	ok, err := s.Repo.Delete(c.Params("id"), userID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed delete notification",
+		})
+	}
+	if !ok {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "notification not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "notification deleted",
+	})
+}
+
+// =====================
+// GET /notifications/preferences
+// =====================
+func (s *NotificationService) GetPreferences(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	recipient, err := s.Repo.GetRecipient(userID)
+	if err != nil {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "user not found",
+		})
+	}
+
+	saved, err := s.Repo.GetPreferences(userID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed get preferences",
+		})
+	}
+
+	// nilai efektif untuk setiap channel × jenis (default: aktif)
+	effective := []model.NotificationPreference{}
+	for _, ch := range s.channelNames() {
+		for _, t := range model.NotificationTypes {
+			enabled, err := s.Repo.IsChannelEnabled(userID, ch, t)
+			if err != nil {
+				return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+					"error": "failed get preferences",
+				})
+			}
+			effective = append(effective, model.NotificationPreference{
+				Channel:          ch,
+				NotificationType: t,
+				Enabled:          enabled,
+			})
+		}
+	}
+
+	locale := recipient.Locale
+	if locale == "" {
+		locale = config.Env.DefaultLocale
+	}
+
+	return c.JSON(fiber.Map{
+		"locale":      locale,
+		"channels":    s.channelNames(),
+		"types":       model.NotificationTypes,
+		"saved":       saved,
+		"preferences": effective,
+	})
+}
+
+// =====================
+// PUT /notifications/preferences
+// =====================
+func (s *NotificationService) UpdatePreferences(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var body struct {
+		Locale      string                         `json:"locale"`
+		Preferences []model.NotificationPreference `json:"preferences"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	channels := map[string]bool{}
+	for _, ch := range s.channelNames() {
+		channels[ch] = true
+	}
+	types := map[string]bool{"*": true}
+	for _, t := range model.NotificationTypes {
+		types[t] = true
+	}
+
+	for _, p := range body.Preferences {
+		if !channels[p.Channel] {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "unknown channel: " + p.Channel,
+			})
+		}
+		if !types[p.NotificationType] {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "unknown notification type: " + p.NotificationType,
+			})
+		}
+	}
+
+	if body.Locale != "" {
+		if !s.Templates.HasLocale(body.Locale) {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "unsupported locale: " + body.Locale,
+			})
+		}
+		if err := s.Repo.SetLocale(userID, body.Locale); err != nil {
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed update locale",
+			})
+		}
+	}
+
+	if err := s.Repo.SavePreferences(userID, body.Preferences); err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed update preferences",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "preferences updated",
+	})
+}
+
+// channelNames: channel yang dikenal walau belum dikonfigurasi (mis. SMTP kosong)
+func (s *NotificationService) channelNames() []string {
+	names := []string{"email"}
+	for _, ch := range s.Dispatcher.Channels() {
+		if ch.Name() != "email" {
+			names = append(names, ch.Name())
+		}
+	}
+	return names
+}
+
+// =====================
+// GET /notifications/stream (SSE)
+// =====================
+func (s *NotificationService) Stream(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	sessionID, _ := c.Locals("session_id").(string)
+	ip := c.IP()
+
+	unread, _ := s.Repo.CountUnread(userID)
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	sub := s.Hub.Subscribe(userID)
+
+	c.Context().SetBodyStreamWriter(fasthttp.StreamWriter(func(w *bufio.Writer) {
+		defer s.Hub.Unsubscribe(sub)
+
+		heartbeat := time.NewTicker(25 * time.Second)
+		defer heartbeat.Stop()
+
+		writeSSE(w, "unread_count", fiber.Map{"unread_count": unread})
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		for {
+			select {
+			case ev := <-sub.C:
+				writeSSE(w, ev.Type, ev.Data)
+
+			case <-heartbeat.C:
+				// session dicabut (remote sign-out) → tutup stream
+				if sessionID != "" && !helper.IsSessionActive(sessionID, ip) {
+					writeSSE(w, "signed_out", fiber.Map{})
+					_ = w.Flush()
+					return
+				}
+				fmt.Fprint(w, ": ping\n\n")
+			}
+
+			// client putus → Flush error
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}))
+
+	return nil
+}
+
+func writeSSE(w *bufio.Writer, event string, data interface{}) {
+	raw, ok := data.(json.RawMessage)
+	if !ok {
+		raw, _ = json.Marshal(data)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, raw)
+}
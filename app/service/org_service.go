@@ -0,0 +1,416 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"project_uas/app/model"
+	"project_uas/app/policy"
+	"project_uas/app/repository"
+)
+
+type OrgService struct {
+	Repo *repository.OrgRepo
+}
+
+func NewOrgService(repo *repository.OrgRepo) *OrgService {
+	return &OrgService{Repo: repo}
+}
+
+// orgError menerjemahkan error repo menjadi respons HTTP
+func orgError(c *fiber.Ctx, err error, what string) error {
+	var pqErr *pq.Error
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": what + " not found"})
+	case errors.Is(err, repository.ErrUnitInUse):
+		return c.Status(http.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case isUniqueViolation(err):
+		return c.Status(http.StatusConflict).JSON(fiber.Map{"error": "code already used"})
+	case errors.As(err, &pqErr) && pqErr.Code == "23503":
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "referenced parent unit or user does not exist"})
+	}
+	return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
+
+func validUUID(id string) bool {
+	_, err := uuid.Parse(id)
+	return err == nil
+}
+
+// authorizeUnit: subject boleh menautkan data ke unit tujuan (unit:assign).
+// Mengembalikan status & pesan error, atau 0 bila boleh.
+func authorizeUnit(c *fiber.Ctx, kind string, id string) (int, string) {
+	if policy.Default == nil {
+		return http.StatusInternalServerError, "policy engine not configured"
+	}
+
+	d, err := policy.Default.Check(policy.SubjectFrom(c), "unit:assign", policy.Resource{Kind: kind, ID: id})
+	if errors.Is(err, policy.ErrNotFound) {
+		return http.StatusBadRequest, "unknown " + kind
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err.Error()
+	}
+	if !d.Allowed {
+		return http.StatusForbidden, kind + " is outside your units"
+	}
+	return 0, ""
+}
+
+type unitRequest struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+func (r *unitRequest) validate() string {
+	r.Code = strings.TrimSpace(r.Code)
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Code == "" || len(r.Code) > 20 {
+		return "code must be 1-20 characters"
+	}
+	if r.Name == "" || len(r.Name) > 150 {
+		return "name must be 1-150 characters"
+	}
+	return ""
+}
+
+// =====================
+// FACULTIES
+// =====================
+
+// GET /faculties
+func (s *OrgService) ListFaculties(c *fiber.Ctx) error {
+	rows, err := s.Repo.ListFaculties()
+	if err != nil {
+		return orgError(c, err, "faculty")
+	}
+	return c.JSON(fiber.Map{"data": rows})
+}
+
+// GET /faculties/:id — beserta departemen & program studi
+func (s *OrgService) GetFaculty(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if !validUUID(id) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "faculty not found"})
+	}
+
+	f, err := s.Repo.GetFaculty(id)
+	if err != nil {
+		return orgError(c, err, "faculty")
+	}
+	return c.JSON(fiber.Map{"data": f})
+}
+
+// POST /faculties
+func (s *OrgService) CreateFaculty(c *fiber.Ctx) error {
+	var req unitRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if msg := req.validate(); msg != "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": msg})
+	}
+
+	f := model.Faculty{Code: req.Code, Name: req.Name}
+	if err := s.Repo.CreateFaculty(&f); err != nil {
+		return orgError(c, err, "faculty")
+	}
+	return c.Status(http.StatusCreated).JSON(fiber.Map{"data": f})
+}
+
+// PUT /faculties/:id
+func (s *OrgService) UpdateFaculty(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if !validUUID(id) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "faculty not found"})
+	}
+
+	var req unitRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if msg := req.validate(); msg != "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": msg})
+	}
+
+	f := model.Faculty{ID: id, Code: req.Code, Name: req.Name}
+	if err := s.Repo.UpdateFaculty(&f); err != nil {
+		return orgError(c, err, "faculty")
+	}
+	return c.JSON(fiber.Map{"data": f})
+}
+
+// DELETE /faculties/:id — ditolak bila masih punya departemen
+func (s *OrgService) DeleteFaculty(c *fiber.Ctx) error {
+	return s.deleteUnit(c, "faculty", s.Repo.DeleteFaculty)
+}
+
+// =====================
+// DEPARTMENTS
+// =====================
+
+type departmentRequest struct {
+	unitRequest
+	FacultyID  string  `json:"faculty_id"`
+	HeadUserID *string `json:"head_user_id"`
+}
+
+func (r *departmentRequest) validate() string {
+	if msg := r.unitRequest.validate(); msg != "" {
+		return msg
+	}
+	if !validUUID(r.FacultyID) {
+		return "faculty_id required"
+	}
+	if r.HeadUserID != nil && *r.HeadUserID == "" {
+		r.HeadUserID = nil
+	}
+	if r.HeadUserID != nil && !validUUID(*r.HeadUserID) {
+		return "head_user_id must be a user id"
+	}
+	return ""
+}
+
+// GET /departments ?faculty_id=
+func (s *OrgService) ListDepartments(c *fiber.Ctx) error {
+	rows, err := s.Repo.ListDepartments(c.Query("faculty_id"))
+	if err != nil {
+		return orgError(c, err, "department")
+	}
+	return c.JSON(fiber.Map{"data": rows})
+}
+
+// GET /departments/:id — beserta program studi
+func (s *OrgService) GetDepartment(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if !validUUID(id) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "department not found"})
+	}
+
+	d, err := s.Repo.GetDepartment(id)
+	if err != nil {
+		return orgError(c, err, "department")
+	}
+	return c.JSON(fiber.Map{"data": d})
+}
+
+// POST /departments — head_user_id opsional (kepala departemen)
+func (s *OrgService) CreateDepartment(c *fiber.Ctx) error {
+	var req departmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if msg := req.validate(); msg != "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": msg})
+	}
+
+	d := model.Department{FacultyID: req.FacultyID, Code: req.Code, Name: req.Name, HeadUserID: req.HeadUserID}
+	if err := s.Repo.CreateDepartment(&d); err != nil {
+		return orgError(c, err, "department")
+	}
+	return c.Status(http.StatusCreated).JSON(fiber.Map{"data": d})
+}
+
+// PUT /departments/:id
+func (s *OrgService) UpdateDepartment(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if !validUUID(id) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "department not found"})
+	}
+
+	var req departmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if msg := req.validate(); msg != "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": msg})
+	}
+
+	d := model.Department{ID: id, FacultyID: req.FacultyID, Code: req.Code, Name: req.Name, HeadUserID: req.HeadUserID}
+	if err := s.Repo.UpdateDepartment(&d); err != nil {
+		return orgError(c, err, "department")
+	}
+	return c.JSON(fiber.Map{"data": d})
+}
+
+// DELETE /departments/:id — ditolak bila masih punya program studi
+func (s *OrgService) DeleteDepartment(c *fiber.Ctx) error {
+	return s.deleteUnit(c, "department", s.Repo.DeleteDepartment)
+}
+
+// =====================
+// STUDY PROGRAMS
+// =====================
+
+type studyProgramRequest struct {
+	unitRequest
+	DepartmentID string `json:"department_id"`
+	Degree       string `json:"degree"`
+}
+
+func (r *studyProgramRequest) validate() string {
+	if msg := r.unitRequest.validate(); msg != "" {
+		return msg
+	}
+	if !validUUID(r.DepartmentID) {
+		return "department_id required"
+	}
+	r.Degree = strings.TrimSpace(r.Degree)
+	if len(r.Degree) > 10 {
+		return "degree must be at most 10 characters"
+	}
+	return ""
+}
+
+// GET /study-programs ?department_id=
+func (s *OrgService) ListStudyPrograms(c *fiber.Ctx) error {
+	rows, err := s.Repo.ListStudyPrograms(c.Query("department_id"))
+	if err != nil {
+		return orgError(c, err, "study program")
+	}
+	return c.JSON(fiber.Map{"data": rows})
+}
+
+// GET /study-programs/:id
+func (s *OrgService) GetStudyProgram(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if !validUUID(id) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "study program not found"})
+	}
+
+	sp, err := s.Repo.GetStudyProgram(id)
+	if err != nil {
+		return orgError(c, err, "study program")
+	}
+	return c.JSON(fiber.Map{"data": sp})
+}
+
+// POST /study-programs
+func (s *OrgService) CreateStudyProgram(c *fiber.Ctx) error {
+	var req studyProgramRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if msg := req.validate(); msg != "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": msg})
+	}
+
+	sp := model.StudyProgram{DepartmentID: req.DepartmentID, Code: req.Code, Name: req.Name, Degree: req.Degree}
+	if err := s.Repo.CreateStudyProgram(&sp); err != nil {
+		return orgError(c, err, "study program")
+	}
+	return c.Status(http.StatusCreated).JSON(fiber.Map{"data": sp})
+}
+
+// PUT /study-programs/:id
+func (s *OrgService) UpdateStudyProgram(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if !validUUID(id) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "study program not found"})
+	}
+
+	var req studyProgramRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if msg := req.validate(); msg != "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": msg})
+	}
+
+	sp := model.StudyProgram{ID: id, DepartmentID: req.DepartmentID, Code: req.Code, Name: req.Name, Degree: req.Degree}
+	if err := s.Repo.UpdateStudyProgram(&sp); err != nil {
+		return orgError(c, err, "study program")
+	}
+	return c.JSON(fiber.Map{"data": sp})
+}
+
+// DELETE /study-programs/:id — mahasiswanya menjadi tanpa program studi
+func (s *OrgService) DeleteStudyProgram(c *fiber.Ctx) error {
+	return s.deleteUnit(c, "study program", s.Repo.DeleteStudyProgram)
+}
+
+func (s *OrgService) deleteUnit(c *fiber.Ctx, what string, del func(id string) (bool, error)) error {
+	id := c.Params("id")
+	if !validUUID(id) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": what + " not found"})
+	}
+
+	ok, err := del(id)
+	if err != nil {
+		return orgError(c, err, what)
+	}
+	if !ok {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": what + " not found"})
+	}
+	return c.JSON(fiber.Map{"message": what + " deleted"})
+}
+
+// =====================
+// UNIT ADMINS (delegasi scope admin)
+// =====================
+
+// GET /unit-admins ?user_id=
+func (s *OrgService) ListUnitAdmins(c *fiber.Ctx) error {
+	rows, err := s.Repo.ListUnitAdmins(c.Query("user_id"))
+	if err != nil {
+		return orgError(c, err, "unit admin")
+	}
+	return c.JSON(fiber.Map{"data": rows})
+}
+
+// POST /unit-admins {user_id, unit_id} — jenis unit dideteksi dari ID
+func (s *OrgService) AddUnitAdmin(c *fiber.Ctx) error {
+	var req struct {
+		UserID string `json:"user_id"`
+		UnitID string `json:"unit_id"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if !validUUID(req.UserID) || !validUUID(req.UnitID) {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "user_id and unit_id required"})
+	}
+
+	unitType, err := s.Repo.UnitType(req.UnitID)
+	if err != nil {
+		return orgError(c, err, "unit")
+	}
+	if unitType == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "unknown unit_id"})
+	}
+
+	actor, _ := c.Locals("user_id").(string)
+	ua := model.UnitAdmin{UserID: req.UserID, UnitType: unitType, UnitID: req.UnitID, CreatedBy: &actor}
+	if err := s.Repo.AddUnitAdmin(&ua); err != nil {
+		return orgError(c, err, "unit admin")
+	}
+
+	return c.Status(http.StatusCreated).JSON(fiber.Map{
+		"message":   "unit admin assigned",
+		"unit_type": unitType,
+	})
+}
+
+// DELETE /unit-admins/:userId/:unitId
+func (s *OrgService) RemoveUnitAdmin(c *fiber.Ctx) error {
+	userID, unitID := c.Params("userId"), c.Params("unitId")
+	if !validUUID(userID) || !validUUID(unitID) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "unit admin not found"})
+	}
+
+	ok, err := s.Repo.RemoveUnitAdmin(userID, unitID)
+	if err != nil {
+		return orgError(c, err, "unit admin")
+	}
+	if !ok {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "unit admin not found"})
+	}
+	return c.JSON(fiber.Map{"message": "unit admin removed"})
+}
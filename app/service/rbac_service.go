@@ -0,0 +1,277 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
+
+	"project_uas/app/model"
+	"project_uas/app/policy"
+	"project_uas/app/repository"
+)
+
+// nama role dipakai juga sebagai nama stage workflow, jadi dibatasi
+// huruf kecil / angka / underscore (mis. head_of_department)
+var (
+	roleNamePattern       = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+	permissionPartPattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,49}$`)
+)
+
+type RBACService struct {
+	Repo *repository.RBACRepo
+}
+
+func NewRBACService(repo *repository.RBACRepo) *RBACService {
+	return &RBACService{Repo: repo}
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// =====================
+// ROLES
+// =====================
+
+// GET /roles
+func (s *RBACService) ListRoles(c *fiber.Ctx) error {
+	roles, err := s.Repo.ListRoles()
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": roles})
+}
+
+// GET /roles/:id
+func (s *RBACService) GetRole(c *fiber.Ctx) error {
+	role, err := s.Repo.GetRole(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "role not found"})
+	}
+	return c.JSON(fiber.Map{"data": role})
+}
+
+type roleRequest struct {
+	Name          string   `json:"name"`
+	Description   *string  `json:"description"`
+	PermissionIDs []string `json:"permission_ids"`
+}
+
+// POST /roles — permission_ids opsional
+func (s *RBACService) CreateRole(c *fiber.Ctx) error {
+	var req roleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if !roleNamePattern.MatchString(req.Name) {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "name must be 2-50 chars of lowercase letters, digits or underscore",
+		})
+	}
+	if ok, resp := s.checkPermissionIDs(c, req.PermissionIDs); !ok {
+		return resp
+	}
+
+	role := model.Role{Name: req.Name, Description: req.Description}
+	if err := s.Repo.CreateRole(&role); err != nil {
+		if isUniqueViolation(err) {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": "role already exists"})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if len(req.PermissionIDs) > 0 {
+		if err := s.Repo.SetRolePermissions(role.ID, req.PermissionIDs); err != nil {
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	created, err := s.Repo.GetRole(role.ID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(http.StatusCreated).JSON(fiber.Map{
+		"message": "role created",
+		"data":    created,
+	})
+}
+
+// PUT /roles/:id — nama & deskripsi (role system tidak bisa diganti nama)
+func (s *RBACService) UpdateRole(c *fiber.Ctx) error {
+	var req roleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if !roleNamePattern.MatchString(req.Name) {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "name must be 2-50 chars of lowercase letters, digits or underscore",
+		})
+	}
+
+	err := s.Repo.UpdateRole(c.Params("id"), req.Name, req.Description)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "role not found"})
+	case errors.Is(err, repository.ErrSystemRole):
+		return c.Status(http.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case isUniqueViolation(err):
+		return c.Status(http.StatusConflict).JSON(fiber.Map{"error": "role already exists"})
+	case err != nil:
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "role updated"})
+}
+
+// DELETE /roles/:id — hanya role kustom yang tidak dipakai user
+func (s *RBACService) DeleteRole(c *fiber.Ctx) error {
+	err := s.Repo.DeleteRole(c.Params("id"))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "role not found"})
+	case errors.Is(err, repository.ErrSystemRole), errors.Is(err, repository.ErrRoleInUse),
+		errors.Is(err, repository.ErrLastRoleManager):
+		return c.Status(http.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "role deleted"})
+}
+
+// =====================
+// ROLE ↔ PERMISSION
+// =====================
+
+// PUT /roles/:id/permissions {permission_ids: [...]} — ganti semua
+// POST /roles/:id/permissions {permission_ids: [...]} — tambah
+func (s *RBACService) SetRolePermissions(c *fiber.Ctx) error {
+	var req roleRequest
+	if err := c.BodyParser(&req); err != nil || req.PermissionIDs == nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "permission_ids required"})
+	}
+	if ok, resp := s.checkPermissionIDs(c, req.PermissionIDs); !ok {
+		return resp
+	}
+
+	roleID := c.Params("id")
+	var err error
+	if c.Method() == fiber.MethodPut {
+		err = s.Repo.SetRolePermissions(roleID, req.PermissionIDs)
+	} else {
+		err = s.Repo.AddRolePermissions(roleID, req.PermissionIDs)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "role not found"})
+	}
+	if errors.Is(err, repository.ErrLastRoleManager) {
+		return c.Status(http.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	role, err := s.Repo.GetRole(roleID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{
+		"message": "role permissions updated",
+		"data":    role,
+	})
+}
+
+// DELETE /roles/:id/permissions/:permissionId
+func (s *RBACService) RemoveRolePermission(c *fiber.Ctx) error {
+	err := s.Repo.RemoveRolePermission(c.Params("id"), c.Params("permissionId"))
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "role not found"})
+	}
+	if errors.Is(err, repository.ErrLastRoleManager) {
+		return c.Status(http.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "permission removed from role"})
+}
+
+// checkPermissionIDs: semua id harus ada (mengembalikan respons 422 bila tidak)
+func (s *RBACService) checkPermissionIDs(c *fiber.Ctx, ids []string) (bool, error) {
+	if len(ids) == 0 {
+		return true, nil
+	}
+	missing, err := s.Repo.UnknownPermissions(ids)
+	if err != nil {
+		return false, c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if len(missing) > 0 {
+		return false, c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":   "unknown permissions",
+			"missing": missing,
+		})
+	}
+	return true, nil
+}
+
+// =====================
+// PERMISSIONS
+// =====================
+
+// GET /permissions
+func (s *RBACService) ListPermissions(c *fiber.Ctx) error {
+	list, err := s.Repo.ListPermissions()
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": list})
+}
+
+// POST /permissions {resource, action, description}
+func (s *RBACService) CreatePermission(c *fiber.Ctx) error {
+	var p model.Permission
+	if err := c.BodyParser(&p); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if !permissionPartPattern.MatchString(p.Resource) || !permissionPartPattern.MatchString(p.Action) {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "resource and action must be lowercase letters, digits, '-' or '_'",
+		})
+	}
+
+	if err := s.Repo.CreatePermission(&p); err != nil {
+		if isUniqueViolation(err) {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{"error": "permission already exists"})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(http.StatusCreated).JSON(fiber.Map{
+		"message": "permission created",
+		"data":    p,
+	})
+}
+
+// DELETE /permissions/:id — dicabut dari semua role. Permission yang
+// dirujuk policy.Rules atau dipegang role system tidak bisa dihapus.
+func (s *RBACService) DeletePermission(c *fiber.Ctx) error {
+	deleted, err := s.Repo.DeletePermission(c.Params("id"), policy.Rules.Permissions())
+	if errors.Is(err, repository.ErrPermissionInUse) {
+		return c.Status(http.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !deleted {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "permission not found"})
+	}
+	return c.JSON(fiber.Map{"message": "permission deleted"})
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"project_uas/app/reconcile"
+)
+
+type ReconcileService struct {
+	Reconciler *reconcile.Reconciler
+}
+
+func NewReconcileService(reconciler *reconcile.Reconciler) *ReconcileService {
+	return &ReconcileService{Reconciler: reconciler}
+}
+
+// =====================
+// GET /admin/integrity         → laporan saja
+// POST /admin/integrity?fix=true → laporan + perbaikan yang aman
+// =====================
+func (s *ReconcileService) Check(c *fiber.Ctx) error {
+	fix := c.Method() == fiber.MethodPost && c.QueryBool("fix")
+
+	report, err := s.Reconciler.Run(c.UserContext(), fix)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": report,
+	})
+}
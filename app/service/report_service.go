@@ -5,6 +5,8 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 
+	"project_uas/app/model"
+	"project_uas/app/policy"
 	"project_uas/app/repository"
 )
 
@@ -17,7 +19,8 @@ func NewReportService(repo *repository.ReportRepo) *ReportService {
 }
 
 func (s *ReportService) GetAchievementStats(c *fiber.Ctx) error {
-	data, err := s.Repo.CountAchievementsByStatus()
+	// admin unit hanya melihat statistik unitnya
+	data, err := s.Repo.CountAchievementsByStatus(policy.ScopeFrom(c))
 	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"error": "failed report",
@@ -42,4 +45,26 @@ func (s *ReportService) GetStudentReport(c *fiber.Ctx) error {
 		"student_id": studentID,
 		"data":       data,
 	})
-}	
+}
+
+// GET /api/v1/reports/units?level=faculty|department|study_program
+func (s *ReportService) GetUnitReport(c *fiber.Ctx) error {
+	level := c.Query("level", model.UnitFaculty)
+	if level != model.UnitFaculty && level != model.UnitDepartment && level != model.UnitStudyProgram {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "level must be faculty, department or study_program",
+		})
+	}
+
+	data, err := s.Repo.CountAchievementsByUnit(level, policy.ScopeFrom(c))
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed unit report",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"level": level,
+		"data":  data,
+	})
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"project_uas/app/listing"
+	"project_uas/app/repository"
+)
+
+type SessionService struct {
+	Repo *repository.SessionRepo
+}
+
+func NewSessionService(repo *repository.SessionRepo) *SessionService {
+	return &SessionService{Repo: repo}
+}
+
+// =====================
+// GET /auth/sessions
+// =====================
+func (s *SessionService) ListMine(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	current, _ := c.Locals("session_id").(string)
+
+	return s.list(c, userID, current)
+}
+
+// =====================
+// DELETE /auth/sessions/:id
+// =====================
+func (s *SessionService) RevokeMine(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+
+	return s.revoke(c, userID, c.Params("id"))
+}
+
+// =====================
+// DELETE /auth/sessions (semua kecuali session ini)
+// =====================
+func (s *SessionService) RevokeOthers(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	current, _ := c.Locals("session_id").(string)
+
+	return s.revokeAll(c, userID, current)
+}
+
+// =====================
+// GET /users/:id/sessions (ADMIN)
+// =====================
+func (s *SessionService) ListForUser(c *fiber.Ctx) error {
+	return s.list(c, c.Params("id"), "")
+}
+
+// =====================
+// DELETE /users/:id/sessions/:sid (ADMIN)
+// =====================
+func (s *SessionService) RevokeForUser(c *fiber.Ctx) error {
+	return s.revoke(c, c.Params("id"), c.Params("sid"))
+}
+
+// =====================
+// DELETE /users/:id/sessions (ADMIN)
+// =====================
+func (s *SessionService) RevokeAllForUser(c *fiber.Ctx) error {
+	return s.revokeAll(c, c.Params("id"), "")
+}
+
+func (s *SessionService) list(c *fiber.Ctx, userID string, current string) error {
+	p, err := listing.Parse(c, repository.SessionListSpec)
+	if err != nil {
+		return listing.Fail(c, err)
+	}
+
+	page, err := s.Repo.ListActiveByUser(userID, p)
+	if err != nil {
+		return listing.Fail(c, err)
+	}
+
+	for i := range page.Items {
+		page.Items[i].Current = page.Items[i].ID == current
+	}
+
+	return listing.Respond(c, page.Items, page.Meta)
+}
+
+func (s *SessionService) revoke(c *fiber.Ctx, userID string, sessionID string) error {
+	session, err := s.Repo.GetByID(sessionID)
+	if err != nil || session.UserID != userID {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "session not found",
+		})
+	}
+
+	if err := s.Repo.Revoke(sessionID); err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed revoke session",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "session revoked",
+		"id":      sessionID,
+	})
+}
+
+func (s *SessionService) revokeAll(c *fiber.Ctx, userID string, exceptID string) error {
+	n, err := s.Repo.RevokeAllForUser(userID, exceptID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed revoke sessions",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "sessions revoked",
+		"revoked": n,
+	})
+}
+
+// describeDevice membuat label singkat dari User-Agent, mis. "Chrome on Windows"
+func describeDevice(ua string) string {
+	if ua == "" {
+		return "Unknown device"
+	}
+
+	browser := "Browser"
+	switch {
+	case strings.Contains(ua, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "Chrome/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "Safari/"):
+		browser = "Safari"
+	case strings.Contains(ua, "okhttp"), strings.Contains(ua, "Dart/"), strings.Contains(ua, "CFNetwork"):
+		browser = "Mobile app"
+	}
+
+	os := ""
+	switch {
+	case strings.Contains(ua, "Android"):
+		os = "Android"
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPad"):
+		os = "iOS"
+	case strings.Contains(ua, "Windows"):
+		os = "Windows"
+	case strings.Contains(ua, "Mac OS X"):
+		os = "macOS"
+	case strings.Contains(ua, "Linux"):
+		os = "Linux"
+	}
+
+	if os == "" {
+		return browser
+	}
+	return browser + " on " + os
+}
@@ -0,0 +1,399 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"project_uas/app/audit"
+	"project_uas/app/model"
+	"project_uas/app/policy"
+	"project_uas/app/repository"
+)
+
+// maxAdvisorBatch: jumlah mahasiswa maksimal per bulk / auto assign
+const maxAdvisorBatch = 1000
+
+// =====================
+// POST /students/advisors (bulk)
+// =====================
+// {"assignments": [{"student_id", "advisor_id"}]} atau
+// {"student_ids": [...], "advisor_id"} untuk satu dosen yang sama.
+// Semua perubahan diterapkan dalam satu transaksi.
+func (s *StudentService) BulkAssignAdvisors(c *fiber.Ctx) error {
+	var body struct {
+		Assignments []struct {
+			StudentID string `json:"student_id"`
+			AdvisorID string `json:"advisor_id"`
+		} `json:"assignments"`
+		StudentIDs []string `json:"student_ids"`
+		AdvisorID  string   `json:"advisor_id"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	changes := []model.AdvisorChange{}
+	for _, a := range body.Assignments {
+		changes = append(changes, model.AdvisorChange{StudentID: a.StudentID, AdvisorID: a.AdvisorID})
+	}
+	for _, id := range body.StudentIDs {
+		changes = append(changes, model.AdvisorChange{StudentID: id, AdvisorID: body.AdvisorID})
+	}
+
+	if ok, err := s.checkAdvisorChanges(c, changes); !ok {
+		return err
+	}
+
+	if err := s.assignAdvisors(c, changes, model.AssignBulk); err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "advisors assigned successfully",
+		"data":    advisorSummary(changes),
+	})
+}
+
+// =====================
+// POST /students/advisors/auto
+// =====================
+// {"department_id", "student_ids"?, "max_per_lecturer"?, "dry_run"?}
+// Tanpa student_ids, semua mahasiswa departemen yang belum punya dosen
+// wali dibagi ke dosen aktif departemen itu, beban terkecil lebih dulu.
+func (s *StudentService) AutoAssignAdvisors(c *fiber.Ctx) error {
+	var body struct {
+		DepartmentID   string   `json:"department_id"`
+		StudentIDs     []string `json:"student_ids"`
+		MaxPerLecturer int      `json:"max_per_lecturer"`
+		DryRun         bool     `json:"dry_run"`
+	}
+	if err := c.BodyParser(&body); err != nil || body.DepartmentID == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "department_id required",
+		})
+	}
+	if body.MaxPerLecturer < 0 {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "max_per_lecturer must not be negative",
+		})
+	}
+
+	if status, msg := authorizeUnit(c, policy.KindDepartment, body.DepartmentID); status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	if len(body.StudentIDs) > 0 {
+		if msg := checkStudentIDs(body.StudentIDs); msg != "" {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": msg})
+		}
+		missing, err := s.AdvisorRepo.UnknownStudents(body.StudentIDs)
+		if err != nil {
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+		if len(missing) > 0 {
+			return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error":   "unknown students",
+				"missing": missing,
+			})
+		}
+		outside, err := s.AdvisorRepo.StudentsOutsideDepartment(body.StudentIDs, body.DepartmentID)
+		if err != nil {
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+		if len(outside) > 0 {
+			return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error":    "students are not in this department",
+				"students": outside,
+			})
+		}
+	}
+
+	loads, err := s.AdvisorRepo.Loads(body.DepartmentID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if len(loads) == 0 {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": "department has no active lecturers",
+		})
+	}
+
+	candidates, err := s.AdvisorRepo.Candidates(body.DepartmentID, body.StudentIDs)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if len(candidates) > maxAdvisorBatch {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("too many students (max %d per request)", maxAdvisorBatch),
+		})
+	}
+
+	changes, skipped := planAdvisors(candidates, loads, body.MaxPerLecturer)
+
+	if !body.DryRun && len(changes) > 0 {
+		if err := s.assignAdvisors(c, changes, model.AssignAuto); err != nil {
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	data := advisorSummary(changes)
+	data["dry_run"] = body.DryRun
+	data["loads"] = loads
+	data["skipped"] = skipped
+
+	message := "advisors assigned successfully"
+	if body.DryRun {
+		message = "advisor assignment planned (dry run)"
+	}
+	return c.JSON(fiber.Map{
+		"message": message,
+		"data":    data,
+	})
+}
+
+// =====================
+// GET /students/:id/advisor-history
+// =====================
+func (s *StudentService) GetAdvisorHistory(c *fiber.Ctx) error {
+	rows, err := s.AdvisorRepo.History(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"data": rows,
+	})
+}
+
+// checkAdvisorChanges memvalidasi permintaan penetapan: mahasiswa ada,
+// advisor adalah dosen aktif, dan bagi admin unit keduanya berada di
+// unitnya. Mengirim respons error dan false bila tidak valid.
+func (s *StudentService) checkAdvisorChanges(c *fiber.Ctx, changes []model.AdvisorChange) (bool, error) {
+	if len(changes) == 0 {
+		return false, c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "assignments required",
+		})
+	}
+
+	studentIDs := make([]string, 0, len(changes))
+	advisorIDs := []string{}
+	seenAdvisor := map[string]bool{}
+	for _, ch := range changes {
+		if ch.AdvisorID == "" || !validUUID(ch.AdvisorID) {
+			return false, c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "every assignment needs a valid advisor_id",
+			})
+		}
+		studentIDs = append(studentIDs, ch.StudentID)
+		if !seenAdvisor[ch.AdvisorID] {
+			seenAdvisor[ch.AdvisorID] = true
+			advisorIDs = append(advisorIDs, ch.AdvisorID)
+		}
+	}
+	if msg := checkStudentIDs(studentIDs); msg != "" {
+		return false, c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": msg})
+	}
+
+	missing, err := s.AdvisorRepo.UnknownStudents(studentIDs)
+	if err != nil {
+		return false, c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if len(missing) > 0 {
+		return false, c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":   "unknown students",
+			"missing": missing,
+		})
+	}
+
+	inactive, err := s.AdvisorRepo.InactiveLecturers(advisorIDs)
+	if err != nil {
+		return false, c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if len(inactive) > 0 {
+		return false, c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":   "advisor_id is not an active lecturer",
+			"missing": inactive,
+		})
+	}
+
+	// admin unit: mahasiswa & dosen harus di unitnya
+	if scope := policy.ScopeFrom(c); scope != nil {
+		outside, err := s.AdvisorRepo.StudentsOutsideScope(studentIDs, scope)
+		if err != nil {
+			return false, c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+		if len(outside) > 0 {
+			return false, c.Status(http.StatusForbidden).JSON(fiber.Map{
+				"error":    "students are outside your units",
+				"students": outside,
+			})
+		}
+		for _, id := range advisorIDs {
+			if status, msg := authorizeUnit(c, policy.KindLecturer, id); status != 0 {
+				return false, c.Status(status).JSON(fiber.Map{"error": msg})
+			}
+		}
+	}
+
+	return true, nil
+}
+
+// checkStudentIDs: UUID valid, tidak duplikat dan tidak melebihi batas
+func checkStudentIDs(ids []string) string {
+	if len(ids) > maxAdvisorBatch {
+		return fmt.Sprintf("too many students (max %d per request)", maxAdvisorBatch)
+	}
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if !validUUID(id) {
+			return fmt.Sprintf("invalid student_id %q", id)
+		}
+		if seen[id] {
+			return "duplicate student_id " + id
+		}
+		seen[id] = true
+	}
+	return ""
+}
+
+// assignAdvisors menerapkan perubahan, mencatatnya ke jejak audit lalu
+// memberi tahu mahasiswa dan dosen wali barunya
+func (s *StudentService) assignAdvisors(c *fiber.Ctx, changes []model.AdvisorChange, method string) error {
+	var assignedBy *string
+	if userID, ok := c.Locals("user_id").(string); ok {
+		assignedBy = &userID
+	}
+
+	if err := s.AdvisorRepo.Assign(changes, method, assignedBy); err != nil {
+		if errors.Is(err, repository.ErrStudentNotFound) {
+			return errors.New("student was removed during assignment, nothing was changed")
+		}
+		return err
+	}
+
+	for _, ch := range changes {
+		if ch.Changed {
+			audit.Record(c, "student", ch.StudentID,
+				fiber.Map{"advisor_id": ch.PreviousAdvisorID},
+				fiber.Map{"advisor_id": ch.AdvisorID},
+			)
+		}
+	}
+
+	s.notifyAdvisorChanges(changes)
+	return nil
+}
+
+// notifyAdvisorChanges: tiap mahasiswa mendapat notifikasi sendiri; dosen
+// cukup satu notifikasi berisi daftar NIM bimbingan barunya
+func (s *StudentService) notifyAdvisorChanges(changes []model.AdvisorChange) {
+	nims := map[string][]string{}
+	order := []string{}
+	for _, ch := range changes {
+		if !ch.Changed {
+			continue
+		}
+		s.Notifier.Notify(ch.StudentUserID, model.NotifAdvisorAssigned, nil, "")
+
+		if _, ok := nims[ch.AdvisorID]; !ok {
+			order = append(order, ch.AdvisorID)
+		}
+		nims[ch.AdvisorID] = append(nims[ch.AdvisorID], ch.StudentNIM)
+	}
+
+	for _, advisorID := range order {
+		list := nims[advisorID]
+		data := fiber.Map{"NIM": nimList(list)}
+		if len(list) > 1 {
+			data["Count"] = len(list)
+		}
+		s.Notifier.NotifyLecturer(advisorID, model.NotifAdviseeAssigned, data, "")
+	}
+}
+
+// nimList: paling banyak 10 NIM supaya notifikasi tetap ringkas
+func nimList(nims []string) string {
+	const max = 10
+	if len(nims) <= max {
+		return strings.Join(nims, ", ")
+	}
+	return fmt.Sprintf("%s (+%d)", strings.Join(nims[:max], ", "), len(nims)-max)
+}
+
+func advisorSummary(changes []model.AdvisorChange) fiber.Map {
+	assigned := 0
+	for _, ch := range changes {
+		if ch.Changed {
+			assigned++
+		}
+	}
+	return fiber.Map{
+		"assigned":  assigned,
+		"unchanged": len(changes) - assigned,
+		"changes":   changes,
+	}
+}
+
+// planAdvisors membagi kandidat satu per satu ke dosen dengan beban
+// terkecil. Kandidat yang sudah dibimbing dosen departemen ini dilepas
+// dulu dari beban dosennya (rebalance). Bila semua dosen sudah mencapai
+// maxPerLecturer (> 0), NIM kandidat sisanya dikembalikan di skipped.
+// loads diubah menjadi beban setelah pembagian; Changed menandai
+// mahasiswa yang akan berganti dosen wali (dipakai dry run).
+func planAdvisors(candidates []repository.AdvisorCandidate, loads []model.AdvisorLoad, maxPerLecturer int) ([]model.AdvisorChange, []string) {
+	index := map[string]int{}
+	for i, l := range loads {
+		index[l.LecturerID] = i
+	}
+	for _, cand := range candidates {
+		if cand.AdvisorID == nil {
+			continue
+		}
+		if i, ok := index[*cand.AdvisorID]; ok {
+			loads[i].Advisees--
+		}
+	}
+
+	changes := []model.AdvisorChange{}
+	skipped := []string{}
+	for _, cand := range candidates {
+		best := 0
+		for i := range loads {
+			if loads[i].Advisees < loads[best].Advisees {
+				best = i
+			}
+		}
+		if maxPerLecturer > 0 && loads[best].Advisees >= maxPerLecturer {
+			// tetap pada dosen walinya yang sekarang
+			if cand.AdvisorID != nil {
+				if i, ok := index[*cand.AdvisorID]; ok {
+					loads[i].Advisees++
+				}
+			}
+			skipped = append(skipped, cand.StudentID)
+			continue
+		}
+
+		loads[best].Advisees++
+		advisorID := loads[best].LecturerID
+		changes = append(changes, model.AdvisorChange{
+			StudentID:         cand.ID,
+			AdvisorID:         advisorID,
+			StudentNIM:        cand.StudentID,
+			PreviousAdvisorID: cand.AdvisorID,
+			Changed:           cand.AdvisorID == nil || *cand.AdvisorID != advisorID,
+		})
+	}
+	return changes, skipped
+}
@@ -5,15 +5,21 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 
+	"project_uas/app/listing"
+	"project_uas/app/model"
+	"project_uas/app/policy"
 	"project_uas/app/repository"
 )
 
 type StudentService struct {
-	Repo *repository.StudentRepo
+	Repo            *repository.StudentRepo
+	AchievementRepo *repository.AchievementRepo
+	AdvisorRepo     *repository.AdvisorRepo
+	Notifier        *NotificationService
 }
 
-func NewStudentService(repo *repository.StudentRepo) *StudentService {
-	return &StudentService{Repo: repo}
+func NewStudentService(repo *repository.StudentRepo, achievementRepo *repository.AchievementRepo, advisorRepo *repository.AdvisorRepo, notifier *NotificationService) *StudentService {
+	return &StudentService{Repo: repo, AchievementRepo: achievementRepo, AdvisorRepo: advisorRepo, Notifier: notifier}
 }
 
 // GET /students/profile
@@ -42,17 +48,21 @@ func (s *StudentService) GetProfile(c *fiber.Ctx) error {
 // =====================
 // GET /students
 // =====================
+// ?sort= &program_study= &study_program_id= &department_id= &faculty_id=
+// &academic_year= &advisor_id= &q= &cursor= &limit=
 func (s *StudentService) GetAll(c *fiber.Ctx) error {
-	data, err := s.Repo.GetAll()
+	p, err := listing.Parse(c, repository.StudentListSpec)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return listing.Fail(c, err)
 	}
 
-	return c.JSON(fiber.Map{
-		"data": data,
-	})
+	// admin unit hanya melihat mahasiswa di unitnya
+	page, err := s.Repo.List(p, policy.ScopeFrom(c))
+	if err != nil {
+		return listing.Fail(c, err)
+	}
+
+	return listing.Respond(c, page.Items, page.Meta)
 }
 
 // =====================
@@ -76,24 +86,32 @@ func (s *StudentService) GetByID(c *fiber.Ctx) error {
 // =====================
 // GET /students/:id/achievements
 // =====================
+// ?sort= &status= &cursor= &limit=
 func (s *StudentService) GetAchievements(c *fiber.Ctx) error {
 	id := c.Params("id")
 
-	data, err := s.Repo.GetAchievementsByStudentID(id)
+	p, err := listing.Parse(c, repository.ReferenceListSpec)
 	if err != nil {
-		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return listing.Fail(c, err)
 	}
 
-	return c.JSON(fiber.Map{
-		"data": data,
-	})
+	page, err := s.AchievementRepo.ListReferences(p, []string{"ar.student_id = ?", "ar.status <> 'deleted'"}, id)
+	if err != nil {
+		return listing.Fail(c, err)
+	}
+
+	results, problems, err := withAchievementDetails(s.AchievementRepo, page.Items)
+	if err != nil {
+		return listing.Fail(c, err)
+	}
+
+	return listing.Respond(c, results, page.Meta, fiber.Map{"integrity_errors": problems})
 }
 
 // =====================
 // PUT /students/:id/advisor (ADMIN)
 // =====================
+// advisor_id harus dosen aktif; pergantian dicatat di riwayat advisor
 func (s *StudentService) UpdateAdvisor(c *fiber.Ctx) error {
 	id := c.Params("id")
 
@@ -107,13 +125,67 @@ func (s *StudentService) UpdateAdvisor(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := s.Repo.UpdateAdvisor(id, body.AdvisorID); err != nil {
+	if _, err := s.Repo.GetByID(id); err != nil {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "student not found",
+		})
+	}
+
+	changes := []model.AdvisorChange{{StudentID: id, AdvisorID: body.AdvisorID}}
+	if ok, err := s.checkAdvisorChanges(c, changes); !ok {
+		return err
+	}
+
+	if err := s.assignAdvisors(c, changes, model.AssignManual); err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
+	if !changes[0].Changed {
+		return c.JSON(fiber.Map{
+			"message": "advisor unchanged",
+		})
+	}
 	return c.JSON(fiber.Map{
 		"message": "advisor updated successfully",
 	})
 }
+
+// =====================
+// PUT /students/:id/study-program
+// =====================
+// Admin unit hanya boleh memindahkan mahasiswa ke program studi di unitnya
+func (s *StudentService) UpdateStudyProgram(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var body struct {
+		StudyProgramID string `json:"study_program_id"`
+	}
+
+	if err := c.BodyParser(&body); err != nil || body.StudyProgramID == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "study_program_id required",
+		})
+	}
+
+	if status, msg := authorizeUnit(c, policy.KindProgram, body.StudyProgramID); status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	ok, err := s.Repo.UpdateStudyProgram(id, body.StudyProgramID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if !ok {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "student not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "study program updated successfully",
+	})
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"project_uas/app/audit"
+	"project_uas/app/model"
+	"project_uas/app/userimport"
+)
+
+type UserImportService struct {
+	Importer *userimport.Importer
+}
+
+func NewUserImportService(importer *userimport.Importer) *UserImportService {
+	return &UserImportService{Importer: importer}
+}
+
+func importReportPath(id string) string {
+	return "/api/v1/users/imports/" + id + "/report"
+}
+
+// =====================
+// POST /users/import (multipart)
+// =====================
+// file=<.csv|.xlsx> &dry_run=true &default_role=student|lecturer
+// &default_password=
+func (s *UserImportService) Import(c *fiber.Ctx) error {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "file is required",
+		})
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "cannot read file"})
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": "cannot read file"})
+	}
+
+	rows, err := userimport.Parse(fh.Filename, data)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	imp, err := s.Importer.Run(c.UserContext(), rows, userimport.Options{
+		Filename:        fh.Filename,
+		DryRun:          c.FormValue("dry_run") == "true",
+		DefaultRole:     c.FormValue("default_role"),
+		DefaultPassword: c.FormValue("default_password"),
+		CreatedBy:       &userID,
+		OnCreated: func(id string, created map[string]interface{}) {
+			audit.Record(c, "user", id, nil, created)
+		},
+	})
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	status := http.StatusCreated
+	if imp.DryRun {
+		status = http.StatusOK
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"data":       imp,
+		"report_url": importReportPath(imp.ID),
+	})
+}
+
+func (s *UserImportService) load(c *fiber.Ctx) (*model.UserImport, error) {
+	id := c.Params("id")
+	if !validUUID(id) {
+		return nil, c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "import not found"})
+	}
+
+	imp, err := s.Importer.Repo.Get(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, c.Status(http.StatusNotFound).JSON(fiber.Map{"error": "import not found"})
+	}
+	if err != nil {
+		return nil, c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return imp, nil
+}
+
+// =====================
+// GET /users/imports/:id
+// =====================
+func (s *UserImportService) GetImport(c *fiber.Ctx) error {
+	imp, err := s.load(c)
+	if imp == nil {
+		return err
+	}
+	return c.JSON(fiber.Map{
+		"data":       imp,
+		"report_url": importReportPath(imp.ID),
+	})
+}
+
+// =====================
+// GET /users/imports/:id/report ?errors_only=true → CSV
+// =====================
+func (s *UserImportService) DownloadReport(c *fiber.Ctx) error {
+	imp, err := s.load(c)
+	if imp == nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := userimport.WriteReport(&buf, imp, c.QueryBool("errors_only")); err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="import-%s-report.csv"`, imp.ID))
+	return c.Send(buf.Bytes())
+}
@@ -5,6 +5,8 @@ import (
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
+	"project_uas/app/audit"
+	"project_uas/app/listing"
 	"project_uas/app/model"
 	"project_uas/app/repository"
 )
@@ -20,12 +22,18 @@ func NewUserService(repo *repository.UserRepo) *UserService {
 // =====================
 // GET ALL USERS
 // =====================
+// ?sort=-created_at|username|full_name &role= &is_active= &q= &cursor= &limit=
 func (s *UserService) GetAll(c *fiber.Ctx) error {
-	users, err := s.UserRepo.GetAll()
+	p, err := listing.Parse(c, repository.UserListSpec)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return listing.Fail(c, err)
+	}
+
+	page, err := s.UserRepo.List(p)
+	if err != nil {
+		return listing.Fail(c, err)
 	}
-	return c.JSON(users)
+	return listing.Respond(c, page.Items, page.Meta)
 }
 
 // =====================
@@ -156,6 +164,25 @@ func (s *UserService) Create(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"error": "commit failed"})
 	}
 
+	created := fiber.Map{
+		"id":       userID,
+		"username": user.Username,
+		"email":    user.Email,
+		"fullName": user.FullName,
+		"role":     body.Role,
+		"isActive": user.IsActive,
+	}
+	switch body.Role {
+	case "student":
+		created["student_id"] = body.StudentID
+		created["program_study"] = body.ProgramStudy
+		created["academic_year"] = body.AcademicYear
+	case "lecturer":
+		created["lecturer_id"] = body.LecturerID
+		created["department"] = body.Department
+	}
+	audit.Record(c, "user", userID, nil, created)
+
 	return c.Status(201).JSON(fiber.Map{
 		"message": "user created successfully",
 		"user_id": userID,
@@ -188,10 +215,14 @@ func (s *UserService) Update(c *fiber.Ctx) error {
 		FullName: body.FullName,
 	}
 
+	before, _ := s.UserRepo.GetByID(id)
+
 	if err := s.UserRepo.Update(id, &user); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	s.recordUser(c, id, before)
+
 	return c.JSON(fiber.Map{"message": "user updated"})
 }
 
@@ -201,13 +232,30 @@ func (s *UserService) Update(c *fiber.Ctx) error {
 func (s *UserService) Delete(c *fiber.Ctx) error {
 	id := c.Params("id")
 
+	before, _ := s.UserRepo.GetByID(id)
+
 	if err := s.UserRepo.Delete(id); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	s.recordUser(c, id, before)
+
 	return c.JSON(fiber.Map{"message": "user deactivated"})
 }
 
+// recordUser mencatat user sebelum & sesudah diubah ke jejak audit
+// (before kosong bila user tidak ditemukan)
+func (s *UserService) recordUser(c *fiber.Ctx, id string, before *model.User) {
+	if before == nil || before.ID == "" {
+		return
+	}
+	after, err := s.UserRepo.GetByID(id)
+	if err != nil {
+		return
+	}
+	audit.Record(c, "user", id, before, after)
+}
+
 // =====================
 // UPDATE ROLE
 // =====================
@@ -224,27 +272,33 @@ func (s *UserService) UpdateRole(c *fiber.Ctx) error {
 		})
 	}
 
-	// validasi role
-	if body.Role != "admin" && body.Role != "student" && body.Role != "lecturer" {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "invalid role",
-		})
-	}
-
-	// ambil role_id dari table roles
+	// role valid = role yang ada di tabel roles (termasuk role kustom)
 	roleID, err := s.UserRepo.GetRoleIDByName(body.Role)
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{
-			"error": "role not found",
+			"error": "invalid role",
 		})
 	}
 
+	previousRole, _ := s.UserRepo.GetRoleName(userID)
+
 	// update role user
-	if err := s.UserRepo.UpdateRole(userID, roleID); err != nil {
+	updated, err := s.UserRepo.UpdateRole(userID, roleID)
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
+	if !updated {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "user not found",
+		})
+	}
+
+	audit.Record(c, "user", userID,
+		fiber.Map{"role": previousRole},
+		fiber.Map{"role": body.Role},
+	)
 
 	return c.JSON(fiber.Map{
 		"message": "role updated successfully",
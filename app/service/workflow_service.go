@@ -0,0 +1,141 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"project_uas/app/model"
+	"project_uas/app/listing"
+	"project_uas/app/repository"
+	"project_uas/app/workflow"
+)
+
+type WorkflowService struct {
+	Repo *repository.WorkflowRepo
+}
+
+func NewWorkflowService(repo *repository.WorkflowRepo) *WorkflowService {
+	return &WorkflowService{Repo: repo}
+}
+
+// StagesFor mengembalikan rantai approver untuk kategori & tingkat prestasi
+func (s *WorkflowService) StagesFor(category string, level string) ([]string, error) {
+	rules, err := s.Repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return workflow.Resolve(rules, category, level), nil
+}
+
+// =====================
+// GET /workflows (ADMIN)
+// =====================
+func (s *WorkflowService) GetAll(c *fiber.Ctx) error {
+	p, err := listing.Parse(c, repository.WorkflowListSpec)
+	if err != nil {
+		return listing.Fail(c, err)
+	}
+
+	page, err := s.Repo.List(p)
+	if err != nil {
+		return listing.Fail(c, err)
+	}
+
+	return listing.Respond(c, page.Items, page.Meta, fiber.Map{"default": workflow.DefaultStages})
+}
+
+// =====================
+// POST /workflows (ADMIN)
+// =====================
+func (s *WorkflowService) Create(c *fiber.Ctx) error {
+	var body struct {
+		Name     string   `json:"name"`
+		Category string   `json:"category"`
+		Level    string   `json:"level"`
+		Stages   []string `json:"stages"`
+	}
+
+	if err := c.BodyParser(&body); err != nil || body.Name == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "name is required",
+		})
+	}
+
+	if len(body.Stages) == 0 {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "at least one stage is required",
+		})
+	}
+
+	// stage selain advisor harus nama role yang ada
+	seen := map[string]bool{}
+	for _, stage := range body.Stages {
+		if seen[stage] {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "duplicate stage: " + stage,
+			})
+		}
+		seen[stage] = true
+
+		if stage == workflow.StageAdvisor {
+			continue
+		}
+
+		exists, err := s.Repo.RoleExists(stage)
+		if err != nil {
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if !exists {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "unknown stage (no such role): " + stage,
+			})
+		}
+	}
+
+	rule := model.WorkflowRule{
+		Name:   body.Name,
+		Stages: body.Stages,
+	}
+	if v := strings.TrimSpace(body.Category); v != "" {
+		rule.Category = &v
+	}
+	if v := strings.TrimSpace(body.Level); v != "" {
+		rule.Level = &v
+	}
+
+	if err := s.Repo.Create(&rule); err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusCreated).JSON(fiber.Map{
+		"message": "workflow created",
+		"data":    rule,
+	})
+}
+
+// =====================
+// DELETE /workflows/:id (ADMIN)
+// =====================
+func (s *WorkflowService) Delete(c *fiber.Ctx) error {
+	deleted, err := s.Repo.Delete(c.Params("id"))
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if !deleted {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "workflow not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "workflow deleted",
+	})
+}
@@ -0,0 +1,178 @@
+// Package storage menyimpan file lampiran di backend yang bisa diganti
+// (disk lokal atau object storage S3-compatible seperti MinIO).
+//
+// File disimpan content-addressed: key diturunkan dari SHA-256 isinya,
+// sehingga sertifikat yang sama hanya tersimpan satu kali.
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"project_uas/config"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+// Storage adalah backend penyimpanan blob
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// New membuat backend sesuai STORAGE_DRIVER (local | s3)
+func New(cfg config.Config) (Storage, error) {
+	switch cfg.StorageDriver {
+	case "", "local":
+		return NewLocal(cfg.StorageLocalDir)
+	case "s3":
+		return NewS3(S3Options{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			UseSSL:    cfg.S3UseSSL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.StorageDriver)
+	}
+}
+
+// Blob adalah hasil penyimpanan content-addressed
+type Blob struct {
+	Key    string
+	SHA256 string
+	Size   int64
+	// Deduplicated true bila isi yang sama sudah tersimpan sebelumnya
+	Deduplicated bool
+}
+
+// BlobKey mengembalikan key untuk hash SHA-256 (hex), dibagi per prefix
+// supaya direktori / listing tidak terlalu besar: blobs/ab/cd/abcd....
+func BlobKey(sum string) string {
+	return "blobs/" + sum[:2] + "/" + sum[2:4] + "/" + sum
+}
+
+// PutBlob menyimpan isi r dengan key berdasarkan SHA-256. Isi ditampung
+// dulu di file sementara karena hash baru diketahui setelah dibaca habis.
+func PutBlob(ctx context.Context, s Storage, r io.Reader, contentType string) (*Blob, error) {
+	tmp, sum, size, err := spool(r)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	blob := &Blob{Key: BlobKey(sum), SHA256: sum, Size: size}
+
+	exists, err := s.Exists(ctx, blob.Key)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		blob.Deduplicated = true
+		return blob, nil
+	}
+
+	if err := s.Put(ctx, blob.Key, tmp, size, contentType); err != nil {
+		return nil, err
+	}
+
+	return blob, nil
+}
+
+// PutQuarantine menyimpan isi r di area karantina (key unik, bukan
+// content-addressed) sampai lolos scan. SHA256 tetap dihitung supaya
+// Promote tidak perlu membaca ulang untuk menentukan key akhir.
+func PutQuarantine(ctx context.Context, s Storage, r io.Reader, contentType string) (*Blob, error) {
+	tmp, sum, size, err := spool(r)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	blob := &Blob{Key: "quarantine/" + uuid.New().String(), SHA256: sum, Size: size}
+	if err := s.Put(ctx, blob.Key, tmp, size, contentType); err != nil {
+		return nil, err
+	}
+
+	return blob, nil
+}
+
+// Promote memindahkan file karantina yang bersih ke key content-addressed
+// lalu menghapus salinan karantina.
+func Promote(ctx context.Context, s Storage, quarantineKey string, sum string, size int64, contentType string) (*Blob, error) {
+	blob := &Blob{Key: BlobKey(sum), SHA256: sum, Size: size}
+
+	exists, err := s.Exists(ctx, blob.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		blob.Deduplicated = true
+	} else {
+		src, err := s.Get(ctx, quarantineKey)
+		if err != nil {
+			return nil, err
+		}
+		err = s.Put(ctx, blob.Key, src, size, contentType)
+		src.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.Delete(ctx, quarantineKey); err != nil {
+		return nil, err
+	}
+
+	return blob, nil
+}
+
+// spool menyalin r ke file sementara sambil menghitung SHA-256;
+// file dikembalikan dalam posisi siap dibaca dari awal.
+func spool(r io.Reader) (*os.File, string, int64, error) {
+	tmp, err := os.CreateTemp("", "blob-*")
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, "", 0, err
+	}
+
+	return tmp, hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// validKey mencegah path traversal pada backend lokal
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,408 @@
+// Package userimport mengimpor user (mahasiswa / dosen) secara massal dari
+// CSV atau XLSX. Seluruh file divalidasi lebih dulu (duplikat di file
+// maupun di database, unit & dosen wali yang tidak dikenal); baris yang
+// valid lalu di-insert per chunk, masing-masing dalam satu transaksi dengan
+// savepoint per baris sehingga satu baris gagal tidak membatalkan chunk.
+package userimport
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"project_uas/app/model"
+	"project_uas/app/repository"
+)
+
+const DefaultChunkSize = 500
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{3,50}$`)
+
+// role yang boleh dibuat lewat impor
+var importableRoles = map[string]bool{"student": true, "lecturer": true}
+
+type Importer struct {
+	Repo      *repository.UserImportRepo
+	ChunkSize int
+}
+
+func New(repo *repository.UserImportRepo) *Importer {
+	return &Importer{Repo: repo, ChunkSize: DefaultChunkSize}
+}
+
+type Options struct {
+	Filename        string
+	DryRun          bool
+	DefaultRole     string // dipakai bila kolom role kosong (default student)
+	DefaultPassword string // dipakai bila kolom password kosong
+	CreatedBy       *string
+	// OnCreated dipanggil untuk setiap user yang berhasil dibuat, dengan
+	// data user seperti yang dicatat POST /users (untuk audit)
+	OnCreated func(userID string, created map[string]interface{})
+}
+
+// Run memvalidasi lalu (kecuali dry-run) meng-insert baris yang valid.
+// Hasil per baris selalu disimpan di user_imports.
+func (im *Importer) Run(ctx context.Context, rows []model.UserImportRow, opts Options) (*model.UserImport, error) {
+	if opts.DefaultRole == "" {
+		opts.DefaultRole = "student"
+	}
+
+	imp := &model.UserImport{
+		Filename:  opts.Filename,
+		DryRun:    opts.DryRun,
+		Total:     len(rows),
+		CreatedBy: opts.CreatedBy,
+		Rows:      make([]model.ImportRowResult, len(rows)),
+	}
+
+	prepared, err := im.validate(rows, opts, imp.Rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if !opts.DryRun {
+		if err := hashPasswords(prepared); err != nil {
+			return nil, err
+		}
+		// chunk yang gagal tidak membatalkan chunk yang sudah commit, jadi
+		// import tetap disimpan supaya user yang terbuat punya laporan
+		im.insert(ctx, prepared, imp.Rows)
+
+		for _, p := range prepared {
+			if imp.Rows[p.index].Status != model.ImportRowCreated {
+				continue
+			}
+			imp.Rows[p.index].UserID = p.user.User.ID
+			if opts.OnCreated != nil {
+				opts.OnCreated(p.user.User.ID, snapshot(&p.user))
+			}
+		}
+	}
+
+	for _, r := range imp.Rows {
+		switch r.Status {
+		case model.ImportRowCreated:
+			imp.Created++
+		case model.ImportRowInvalid:
+			imp.Invalid++
+		case model.ImportRowFailed:
+			imp.Failed++
+		}
+	}
+
+	if err := im.Repo.Save(imp); err != nil {
+		return nil, err
+	}
+	return imp, nil
+}
+
+// snapshot: field user yang sama dengan audit POST /users (tanpa password)
+func snapshot(u *repository.ImportedUser) map[string]interface{} {
+	created := map[string]interface{}{
+		"id":       u.User.ID,
+		"username": u.User.Username,
+		"email":    u.User.Email,
+		"fullName": u.User.FullName,
+		"role":     u.Role,
+		"isActive": u.User.IsActive,
+	}
+	switch u.Role {
+	case "student":
+		created["student_id"] = u.StudentID
+		created["program_study"] = u.ProgramStudy
+		created["academic_year"] = u.AcademicYear
+		if u.AdvisorID != nil {
+			created["advisor_id"] = *u.AdvisorID
+		}
+	case "lecturer":
+		created["lecturer_id"] = u.LecturerID
+		created["department"] = u.Department
+	}
+	return created
+}
+
+// pending adalah baris valid beserta indeksnya di hasil
+type pending struct {
+	index    int
+	password string
+	user     repository.ImportedUser
+}
+
+func (im *Importer) validate(rows []model.UserImportRow, opts Options, results []model.ImportRowResult) ([]*pending, error) {
+	var usernames, emails, studentIDs, lecturerIDs []string
+	for _, r := range rows {
+		usernames = append(usernames, r.Username)
+		emails = append(emails, r.Email)
+		studentIDs = append(studentIDs, r.StudentID)
+		lecturerIDs = append(lecturerIDs, r.LecturerID, r.Advisor)
+	}
+
+	lk, err := im.Repo.Lookups(usernames, emails, studentIDs, lecturerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// kunci yang sudah dipakai baris sebelumnya di file → nomor baris
+	seen := map[string]int{}
+	claim := func(kind string, value string, row int) string {
+		key := kind + ":" + strings.ToLower(value)
+		if first, ok := seen[key]; ok {
+			return fmt.Sprintf("duplicate %s %q (also on row %d)", kind, value, first)
+		}
+		seen[key] = row
+		return ""
+	}
+
+	prepared := []*pending{}
+	for i, r := range rows {
+		var errs []string
+		fail := func(format string, args ...interface{}) {
+			errs = append(errs, fmt.Sprintf(format, args...))
+		}
+
+		role := r.Role
+		if role == "" {
+			role = opts.DefaultRole
+		}
+		password := r.Password
+		if password == "" {
+			password = opts.DefaultPassword
+		}
+
+		switch {
+		case r.Username == "":
+			fail("username is required")
+		case !usernamePattern.MatchString(r.Username):
+			fail("username must be 3-50 letters, digits, '.', '_' or '-'")
+		case lk.Usernames[strings.ToLower(r.Username)]:
+			fail("username %q already exists", r.Username)
+		default:
+			if msg := claim("username", r.Username, r.Row); msg != "" {
+				errs = append(errs, msg)
+			}
+		}
+
+		if addr, err := mail.ParseAddress(r.Email); err != nil || addr.Address != r.Email {
+			fail("invalid email %q", r.Email)
+		} else if lk.Emails[strings.ToLower(r.Email)] {
+			fail("email %q already exists", r.Email)
+		} else if msg := claim("email", r.Email, r.Row); msg != "" {
+			errs = append(errs, msg)
+		}
+
+		if r.FullName == "" {
+			fail("full_name is required")
+		}
+		if len(password) < 8 {
+			fail("password must be at least 8 characters (set a password column or a default password)")
+		}
+
+		roleID, roleExists := lk.Roles[role]
+		if !importableRoles[role] || !roleExists {
+			fail("role must be student or lecturer, got %q", role)
+		}
+
+		p := &pending{
+			index:    i,
+			password: password,
+			user: repository.ImportedUser{
+				User: model.User{
+					ID:       uuid.NewString(),
+					Username: r.Username,
+					Email:    r.Email,
+					FullName: r.FullName,
+					RoleID:   roleID,
+					IsActive: true,
+				},
+				Role:      role,
+				CreatedBy: opts.CreatedBy,
+			},
+		}
+
+		switch role {
+		case "student":
+			errs = append(errs, validateStudent(r, lk, claim, &p.user)...)
+		case "lecturer":
+			errs = append(errs, validateLecturer(r, lk, claim, &p.user)...)
+		}
+
+		results[i] = model.ImportRowResult{Row: r.Row, Username: r.Username, Status: model.ImportRowValid}
+		if len(errs) > 0 {
+			results[i].Status = model.ImportRowInvalid
+			results[i].Errors = errs
+			continue
+		}
+		prepared = append(prepared, p)
+	}
+	return prepared, nil
+}
+
+func validateStudent(r model.UserImportRow, lk *repository.ImportLookups, claim func(string, string, int) string, u *repository.ImportedUser) []string {
+	var errs []string
+
+	switch {
+	case r.StudentID == "":
+		errs = append(errs, "student_id (NIM) is required for students")
+	case len(r.StudentID) > 20:
+		errs = append(errs, "student_id must be at most 20 characters")
+	case lk.StudentIDs[strings.ToLower(r.StudentID)]:
+		errs = append(errs, fmt.Sprintf("student_id %q already exists", r.StudentID))
+	default:
+		if msg := claim("student_id", r.StudentID, r.Row); msg != "" {
+			errs = append(errs, msg)
+		}
+	}
+
+	if r.StudyProgram == "" {
+		errs = append(errs, "study_program is required for students")
+	} else if sp, ok := lk.Programs[strings.ToLower(r.StudyProgram)]; !ok {
+		errs = append(errs, fmt.Sprintf("unknown study program %q", r.StudyProgram))
+	} else {
+		u.ProgramStudy, u.ProgramID = sp.Name, &sp.ID
+	}
+
+	if len(r.AcademicYear) > 10 {
+		errs = append(errs, "academic_year must be at most 10 characters")
+	}
+	u.AcademicYear = r.AcademicYear
+
+	if r.Advisor != "" {
+		id, ok := lk.LecturerIDs[strings.ToLower(r.Advisor)]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("unknown advisor lecturer_id %q (import lecturers first)", r.Advisor))
+		}
+		u.AdvisorID = &id
+	}
+
+	u.StudentID = r.StudentID
+	return errs
+}
+
+func validateLecturer(r model.UserImportRow, lk *repository.ImportLookups, claim func(string, string, int) string, u *repository.ImportedUser) []string {
+	var errs []string
+
+	switch {
+	case r.LecturerID == "":
+		errs = append(errs, "lecturer_id (NIP) is required for lecturers")
+	case len(r.LecturerID) > 20:
+		errs = append(errs, "lecturer_id must be at most 20 characters")
+	default:
+		if _, exists := lk.LecturerIDs[strings.ToLower(r.LecturerID)]; exists {
+			errs = append(errs, fmt.Sprintf("lecturer_id %q already exists", r.LecturerID))
+		} else if msg := claim("lecturer_id", r.LecturerID, r.Row); msg != "" {
+			errs = append(errs, msg)
+		}
+	}
+
+	if r.Department != "" {
+		d, ok := lk.Departments[strings.ToLower(r.Department)]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("unknown department %q", r.Department))
+		} else {
+			u.Department, u.DepartmentID = d.Name, &d.ID
+		}
+	}
+
+	u.LecturerID = r.LecturerID
+	return errs
+}
+
+// hashPasswords: bcrypt sekali per password berbeda, paralel per CPU
+func hashPasswords(rows []*pending) error {
+	byPassword := map[string][]*pending{}
+	for _, p := range rows {
+		byPassword[p.password] = append(byPassword[p.password], p)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		sem      = make(chan struct{}, runtime.NumCPU())
+	)
+	for password, group := range byPassword {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(password string, group []*pending) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+			if err != nil {
+				mu.Lock()
+				firstErr = err
+				mu.Unlock()
+				return
+			}
+			for _, p := range group {
+				p.user.User.PasswordHash = string(hash)
+			}
+		}(password, group)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// insert per chunk; savepoint per baris supaya pelanggaran unik karena
+// balapan (mis. user dibuat bersamaan) hanya menggagalkan baris tersebut.
+// Bila satu chunk gagal (mis. koneksi putus), chunk itu di-rollback dan
+// baris sisanya ditandai failed; chunk sebelumnya tetap tersimpan.
+func (im *Importer) insert(ctx context.Context, rows []*pending, results []model.ImportRowResult) {
+	size := im.ChunkSize
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+
+	for start := 0; start < len(rows); start += size {
+		end := start + size
+		if end > len(rows) {
+			end = len(rows)
+		}
+		if err := im.insertChunk(ctx, rows[start:end], results); err != nil {
+			for _, p := range rows[start:] {
+				results[p.index].Status = model.ImportRowFailed
+				results[p.index].Errors = []string{"import aborted: " + err.Error()}
+			}
+			return
+		}
+	}
+}
+
+func (im *Importer) insertChunk(ctx context.Context, chunk []*pending, results []model.ImportRowResult) error {
+	tx, err := im.Repo.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, p := range chunk {
+		if _, err := tx.Exec(`SAVEPOINT import_row`); err != nil {
+			return err
+		}
+		if err := im.Repo.InsertTx(tx, &p.user); err != nil {
+			if _, rbErr := tx.Exec(`ROLLBACK TO SAVEPOINT import_row`); rbErr != nil {
+				return rbErr
+			}
+			results[p.index].Status = model.ImportRowFailed
+			results[p.index].Errors = []string{err.Error()}
+			continue
+		}
+		results[p.index].Status = model.ImportRowCreated
+	}
+
+	if err := tx.Commit(); err != nil {
+		for _, p := range chunk {
+			results[p.index].Status = model.ImportRowFailed
+			results[p.index].Errors = []string{"chunk rolled back: " + err.Error()}
+		}
+	}
+	return nil
+}
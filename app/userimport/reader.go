@@ -0,0 +1,175 @@
+package userimport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"project_uas/app/model"
+)
+
+// MaxRows membatasi jumlah baris data per file
+const MaxRows = 20000
+
+var ErrUnsupportedFormat = errors.New("unsupported file format, use .csv or .xlsx")
+
+// headerAliases: nama kolom yang diterima → field UserImportRow
+var headerAliases = map[string]string{
+	"username":      "username",
+	"email":         "email",
+	"full_name":     "full_name",
+	"fullname":      "full_name",
+	"name":          "full_name",
+	"nama":          "full_name",
+	"role":          "role",
+	"password":      "password",
+	"student_id":    "student_id",
+	"nim":           "student_id",
+	"study_program": "study_program",
+	"program_study": "study_program",
+	"prodi":         "study_program",
+	"academic_year": "academic_year",
+	"angkatan":      "academic_year",
+	"advisor":       "advisor",
+	"advisor_id":    "advisor",
+	"lecturer_id":   "lecturer_id",
+	"nip":           "lecturer_id",
+	"department":    "department",
+}
+
+// Parse membaca file CSV (koma atau titik koma) / XLSX. Baris pertama
+// adalah header; nomor baris mengikuti nomor baris di spreadsheet.
+func Parse(filename string, data []byte) ([]model.UserImportRow, error) {
+	var records [][]string
+	var err error
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		records, err = readCSV(data)
+	case ".xlsx":
+		records, err = readXLSX(bytes.NewReader(data), int64(len(data)))
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("file is empty")
+	}
+
+	columns := map[int]string{}
+	for i, h := range records[0] {
+		key := strings.ToLower(strings.TrimSpace(h))
+		key = strings.ReplaceAll(key, " ", "_")
+		if field, ok := headerAliases[key]; ok {
+			columns[i] = field
+		}
+	}
+	for _, required := range []string{"username", "email", "full_name"} {
+		if !hasField(columns, required) {
+			return nil, fmt.Errorf("missing required column %q", required)
+		}
+	}
+
+	rows := []model.UserImportRow{}
+	for i, rec := range records[1:] {
+		if blank(rec) {
+			continue
+		}
+		if len(rows) == MaxRows {
+			return nil, fmt.Errorf("too many rows (max %d)", MaxRows)
+		}
+
+		row := model.UserImportRow{Row: i + 2}
+		for col, field := range columns {
+			if col < len(rec) {
+				setField(&row, field, strings.TrimSpace(rec[col]))
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	// ekspor Excel berlocale Indonesia memakai ";" sebagai pemisah
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		r.Comma = ';'
+	}
+
+	// encoding/csv melewati baris kosong; record diisi ulang sesuai nomor
+	// barisnya supaya nomor baris di laporan sama dengan di file
+	var records [][]string
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %w", err)
+		}
+		line, _ := r.FieldPos(0)
+		for len(records) < line-1 {
+			records = append(records, nil)
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+func hasField(columns map[int]string, field string) bool {
+	for _, f := range columns {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func blank(rec []string) bool {
+	for _, v := range rec {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func setField(row *model.UserImportRow, field string, v string) {
+	switch field {
+	case "username":
+		row.Username = v
+	case "email":
+		row.Email = v
+	case "full_name":
+		row.FullName = v
+	case "role":
+		row.Role = strings.ToLower(v)
+	case "password":
+		row.Password = v
+	case "student_id":
+		row.StudentID = v
+	case "study_program":
+		row.StudyProgram = v
+	case "academic_year":
+		row.AcademicYear = v
+	case "advisor":
+		row.Advisor = v
+	case "lecturer_id":
+		row.LecturerID = v
+	case "department":
+		row.Department = v
+	}
+}
@@ -0,0 +1,97 @@
+package userimport
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"project_uas/app/model"
+)
+
+func parseFixture(t *testing.T, name string) ([]model.UserImportRow, error) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Parse(name, data)
+}
+
+func TestParseXLSX(t *testing.T) {
+	rows, err := parseFixture(t, "users.xlsx")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	want := []model.UserImportRow{
+		// shared string, rich text run, angka dan t="str"
+		{Row: 2, Username: "budi", Email: "budi@mail.com", FullName: "Budi Santoso", StudentID: "2023001", AcademicYear: "2023"},
+		// baris 3-4 tidak ada di sheet; inlineStr, sel kosong di XFD diabaikan
+		{Row: 5, Username: "sari", Email: "sari@mail.com", FullName: "Sari", StudentID: "2023002"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d: %+v", len(rows), len(want), rows)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, rows[i], want[i])
+		}
+	}
+}
+
+func TestParseXLSXBounds(t *testing.T) {
+	tests := []struct {
+		fixture string
+		err     string
+	}{
+		{"huge_row.xlsx", "too many rows"},
+		{"huge_column.xlsx", "too many columns"},
+		{"bad_column.xlsx", "bad cell reference"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			_, err := parseFixture(t, tt.fixture)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("err = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestParseCSVRowNumbers(t *testing.T) {
+	tests := []struct {
+		fixture string
+		rows    []int
+		names   []string
+	}{
+		// BOM, dua baris kosong, dan field kutip multi-baris (baris 6-7)
+		{"users.csv", []int{2, 5, 6, 8}, []string{"Budi Santoso", "Sari", "Line\none", "Last"}},
+		// pemisah ";" dan CRLF
+		{"users_semicolon.csv", []int{2, 4}, []string{"Budi", "Sari"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			rows, err := parseFixture(t, tt.fixture)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(rows) != len(tt.rows) {
+				t.Fatalf("got %d rows, want %d: %+v", len(rows), len(tt.rows), rows)
+			}
+			for i, r := range rows {
+				if r.Row != tt.rows[i] || r.FullName != tt.names[i] {
+					t.Errorf("row %d = (%d, %q), want (%d, %q)", i, r.Row, r.FullName, tt.rows[i], tt.names[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseRejectsUnknownFormat(t *testing.T) {
+	if _, err := Parse("users.txt", []byte("username\n")); err != ErrUnsupportedFormat {
+		t.Fatalf("err = %v, want ErrUnsupportedFormat", err)
+	}
+}
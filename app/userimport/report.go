@@ -0,0 +1,32 @@
+package userimport
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"project_uas/app/model"
+)
+
+// WriteReport menulis hasil per baris sebagai CSV; errorsOnly hanya
+// menyertakan baris invalid / failed
+func WriteReport(w io.Writer, imp *model.UserImport, errorsOnly bool) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"row", "username", "status", "errors"}); err != nil {
+		return err
+	}
+
+	for _, r := range imp.Rows {
+		if errorsOnly && len(r.Errors) == 0 {
+			continue
+		}
+		err := cw.Write([]string{strconv.Itoa(r.Row), r.Username, r.Status, strings.Join(r.Errors, "; ")})
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,250 @@
+package userimport
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Pembaca XLSX minimal (tanpa dependensi): hanya sheet pertama, nilai sel
+// sebagai teks. Cukup untuk file impor yang diekspor Excel / LibreOffice /
+// Google Sheets.
+
+const relOfficeDocument = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument"
+
+// batas ukuran tiap bagian XML setelah dekompresi (proteksi zip bomb)
+const maxXLSXPart = 64 << 20
+
+// Nomor baris / kolom dari atribut r dipakai sebagai indeks slice, jadi
+// dibatasi sebelum slice diperbesar. Baris: header + MaxRows (juga di
+// bawah batas Excel 1048576). Kolom: batas Excel (XFD) untuk referensi,
+// dan maxXLSXColumns untuk sel yang berisi nilai; sel kosong yang hanya
+// membawa format diabaikan.
+const (
+	maxXLSXRows    = MaxRows + 1
+	maxExcelColumn = 16384
+	maxXLSXColumns = 256
+)
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRels struct {
+	Rels []struct {
+		ID     string `xml:"Id,attr"`
+		Type   string `xml:"Type,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// <si> bisa berupa <t> langsung atau beberapa run <r><t>
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSX mengembalikan baris-baris sheet pertama; baris kosong di
+// tengah tetap dipertahankan supaya nomor baris sesuai dengan Excel
+func readXLSX(r io.ReaderAt, size int64) ([][]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("not a valid xlsx file: %w", err)
+	}
+
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeXML(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("xlsx: missing %s", sheetPath)
+	}
+	var sheet xlsxSheet
+	if err := decodeXML(f, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := [][]string{}
+	for _, row := range sheet.Rows {
+		// atribut r opsional; tanpa r baris dianggap berurutan
+		idx := len(rows)
+		if row.R > 0 {
+			idx = row.R - 1
+		}
+		if idx >= maxXLSXRows {
+			return nil, fmt.Errorf("too many rows (max %d)", MaxRows)
+		}
+		for len(rows) <= idx {
+			rows = append(rows, nil)
+		}
+
+		cells := []string{}
+		for i, c := range row.Cells {
+			if c.Value == "" && c.Inline.String() == "" {
+				continue
+			}
+			col := i
+			if c.Ref != "" {
+				if col, err = columnIndex(c.Ref); err != nil {
+					return nil, err
+				}
+			}
+			if col >= maxXLSXColumns {
+				return nil, fmt.Errorf("xlsx: too many columns (max %d)", maxXLSXColumns)
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+
+			switch c.Type {
+			case "s":
+				n, err := strconv.Atoi(c.Value)
+				if err != nil || n < 0 || n >= len(shared.Items) {
+					return nil, fmt.Errorf("xlsx: bad shared string index in %s", c.Ref)
+				}
+				cells[col] = shared.Items[n].String()
+			case "inlineStr":
+				cells[col] = c.Inline.String()
+			case "b":
+				cells[col] = map[string]string{"1": "TRUE", "0": "FALSE"}[c.Value]
+			default:
+				cells[col] = c.Value
+			}
+		}
+		rows[idx] = cells
+	}
+	return rows, nil
+}
+
+// firstSheetPath mengikuti workbook.xml → workbook.xml.rels
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	wbPath := "xl/workbook.xml"
+	if f, ok := files["_rels/.rels"]; ok {
+		var rels xlsxRels
+		if err := decodeXML(f, &rels); err != nil {
+			return "", err
+		}
+		for _, rel := range rels.Rels {
+			if rel.Type == relOfficeDocument {
+				wbPath = strings.TrimPrefix(rel.Target, "/")
+			}
+		}
+	}
+
+	f, ok := files[wbPath]
+	if !ok {
+		return "", errors.New("xlsx: workbook not found")
+	}
+	var wb xlsxWorkbook
+	if err := decodeXML(f, &wb); err != nil {
+		return "", err
+	}
+	if len(wb.Sheets) == 0 {
+		return "", errors.New("xlsx: workbook has no sheets")
+	}
+
+	dir := path.Dir(wbPath)
+	relsPath := path.Join(dir, "_rels", path.Base(wbPath)+".rels")
+	f, ok = files[relsPath]
+	if !ok {
+		return path.Join(dir, "worksheets/sheet1.xml"), nil
+	}
+	var rels xlsxRels
+	if err := decodeXML(f, &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Rels {
+		if rel.ID == wb.Sheets[0].RID {
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/"), nil
+			}
+			return path.Join(dir, rel.Target), nil
+		}
+	}
+	return "", fmt.Errorf("xlsx: sheet %q has no relationship", wb.Sheets[0].Name)
+}
+
+func decodeXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	lr := &io.LimitedReader{R: rc, N: maxXLSXPart + 1}
+	if err := xml.NewDecoder(lr).Decode(v); err != nil {
+		return fmt.Errorf("xlsx: parse %s: %w", f.Name, err)
+	}
+	if lr.N <= 0 {
+		return fmt.Errorf("xlsx: %s is too large", f.Name)
+	}
+	return nil
+}
+
+// columnIndex: "C12" → 2
+func columnIndex(ref string) (int, error) {
+	col := 0
+	n := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+		n++
+	}
+	if n == 0 || n > 3 || col > maxExcelColumn {
+		return 0, fmt.Errorf("xlsx: bad cell reference %q", ref)
+	}
+	return col - 1, nil
+}
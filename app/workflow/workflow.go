@@ -0,0 +1,160 @@
+// Package workflow berisi state machine verifikasi prestasi: tabel transisi
+// yang sah dan rantai approver (stage) per kategori / tingkat prestasi.
+package workflow
+
+import (
+	"errors"
+	"strings"
+
+	"project_uas/app/model"
+)
+
+// =====================
+// STATUS
+// =====================
+const (
+	StatusDraft     = "draft"
+	StatusSubmitted = "submitted"
+	StatusVerified  = "verified"
+	StatusRejected  = "rejected"
+	StatusDeleted   = "deleted"
+
+	StatusRevisionRequested = "revision_requested"
+)
+
+// =====================
+// ACTIONS
+// =====================
+const (
+	ActionEdit    = "edit"
+	ActionSubmit  = "submit"
+	ActionAdvance = "advance" // approve stage yang bukan terakhir
+	ActionApprove = "approve" // approve stage terakhir
+	ActionReject  = "reject"
+	ActionDelete  = "delete"
+
+	ActionRequestRevision = "request_revision"
+)
+
+// =====================
+// STAGES (approver)
+// =====================
+// Stage selain advisor adalah nama role, jadi role kustom bisa dipakai
+// sebagai approver tanpa perubahan kode.
+const (
+	StageAdvisor          = "advisor"
+	StageHeadOfDepartment = "head_of_department"
+	StageStudentAffairs   = "student_affairs"
+)
+
+// DefaultStages dipakai bila tidak ada rule yang cocok
+var DefaultStages = []string{StageAdvisor}
+
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// transitions: status asal → action → status tujuan
+var transitions = map[string]map[string]string{
+	StatusDraft: {
+		ActionEdit:   StatusDraft,
+		ActionSubmit: StatusSubmitted,
+		ActionDelete: StatusDeleted,
+	},
+	StatusSubmitted: {
+		ActionAdvance:         StatusSubmitted,
+		ActionApprove:         StatusVerified,
+		ActionReject:          StatusRejected,
+		ActionRequestRevision: StatusRevisionRequested,
+	},
+	// mahasiswa memperbaiki lalu mengirim ulang; approval mulai lagi dari stage pertama
+	StatusRevisionRequested: {
+		ActionEdit:   StatusRevisionRequested,
+		ActionSubmit: StatusSubmitted,
+		ActionDelete: StatusDeleted,
+	},
+}
+
+// RevisableFields adalah field prestasi yang boleh diberi komentar revisi
+var RevisableFields = map[string]bool{
+	"title":       true,
+	"description": true,
+	"category":    true,
+	"level":       true,
+	"organizer":   true,
+	"location":    true,
+	"event_date":  true,
+	"score":       true,
+	"attachments": true,
+}
+
+// Next mengembalikan status tujuan untuk action dari status asal
+func Next(from string, action string) (string, error) {
+	to, ok := transitions[from][action]
+	if !ok {
+		return "", ErrInvalidTransition
+	}
+	return to, nil
+}
+
+// Allowed melaporkan apakah action sah untuk status tersebut
+func Allowed(from string, action string) bool {
+	_, err := Next(from, action)
+	return err == nil
+}
+
+// ApprovalAction memilih advance / approve tergantung posisi stage
+func ApprovalAction(stages []string, current int) string {
+	if current >= len(stages)-1 {
+		return ActionApprove
+	}
+	return ActionAdvance
+}
+
+// CurrentStage mengembalikan nama stage yang sedang menunggu approval.
+// Reference lama (tanpa snapshot stage) dianggap memakai DefaultStages.
+func CurrentStage(stages []string, current int) string {
+	if len(stages) == 0 {
+		stages = DefaultStages
+	}
+	if current < 0 || current >= len(stages) {
+		return ""
+	}
+	return stages[current]
+}
+
+// =====================
+// RULE RESOLUTION
+// =====================
+
+// Resolve memilih rantai stage untuk kategori & tingkat prestasi.
+// Rule paling spesifik menang: kategori+tingkat > salah satu > default.
+func Resolve(rules []model.WorkflowRule, category string, level string) []string {
+	best := -1
+	var stages []string
+
+	for _, r := range rules {
+		score := 0
+
+		if r.Category != nil {
+			if !strings.EqualFold(*r.Category, category) {
+				continue
+			}
+			score += 2
+		}
+		if r.Level != nil {
+			if !strings.EqualFold(*r.Level, level) {
+				continue
+			}
+			score++
+		}
+
+		if score > best && len(r.Stages) > 0 {
+			best = score
+			stages = r.Stages
+		}
+	}
+
+	if stages == nil {
+		return DefaultStages
+	}
+	return stages
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"project_uas/config"
+	"project_uas/database"
+	"project_uas/database/seed"
+
+	"project_uas/app/audit"
+	"project_uas/app/model"
+	"project_uas/app/reconcile"
+	"project_uas/app/repository"
+	"project_uas/app/storage"
+	"project_uas/app/userimport"
+)
+
+// runCommand menjalankan subcommand CLI (go run . <command> [flags])
+// dan mengembalikan exit code
+func runCommand(args []string) int {
+	switch args[0] {
+	case "migrate":
+		return migrateCommand(args[1:])
+	case "seed":
+		return seedCommand(args[1:])
+	case "reconcile":
+		return reconcileCommand(args[1:])
+	case "import-users":
+		return importUsersCommand(args[1:])
+	default:
+		fmt.Fprintln(os.Stderr, "unknown command:", args[0])
+		fmt.Fprintln(os.Stderr, "commands: migrate, seed, reconcile, import-users")
+		return 2
+	}
+}
+
+// =====================
+// migrate up [--to N] | down [--steps N] | status
+// =====================
+func migrateCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: migrate up [--to N] | down [--steps N] | status")
+		return 2
+	}
+
+	migrator, err := database.NewMigrator(database.PostgresDB, database.MongoDB)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	ctx := context.Background()
+
+	fs := flag.NewFlagSet("migrate "+args[0], flag.ExitOnError)
+	to := fs.Int("to", 0, "migrate up to this version (default: latest)")
+	steps := fs.Int("steps", 1, "number of migrations to roll back")
+	fs.Parse(args[1:])
+
+	var ran []database.Migration
+	switch args[0] {
+	case "up":
+		ran, err = migrator.Up(ctx, *to)
+	case "down":
+		ran, err = migrator.Down(ctx, *steps)
+	case "status":
+		list, err := migrator.Status(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		for _, st := range list {
+			applied := "pending"
+			if st.AppliedAt != nil {
+				applied = st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if st.Modified {
+				applied += " (modified since applied)"
+			}
+			fmt.Printf("%04d  %-8s  %-36s  %s\n", st.Version, st.Store, st.Name, applied)
+		}
+		return 0
+	default:
+		fmt.Fprintln(os.Stderr, "unknown migrate command:", args[0])
+		return 2
+	}
+
+	// yang sudah jalan tetap dilaporkan walau langkah berikutnya gagal
+	for _, m := range ran {
+		fmt.Printf("%s %04d_%s\n", args[0], m.Version, m.Name)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(ran) == 0 {
+		fmt.Println("nothing to migrate")
+	}
+	return 0
+}
+
+// =====================
+// seed [--profile dev|demo|test|path.yaml] [--force]
+// =====================
+func seedCommand(args []string) int {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	profile := fs.String("profile", "dev", "fixture profile (dev, demo, test) or path to a .yaml/.json file")
+	force := fs.Bool("force", false, "allow seeding when APP_ENV is not development, dev, demo or test")
+	fs.Parse(args)
+
+	result, err := seed.Run(context.Background(), database.PostgresDB, database.MongoDB, seed.Options{
+		Profile: *profile,
+		AppEnv:  config.Env.AppEnv,
+		Force:   *force,
+	})
+	if result != nil {
+		fmt.Printf("created: %d roles, %d permissions, %d users, %d lecturers, %d students, %d workflows, %d units, %d achievements\n",
+			result.Roles, result.Permissions, result.Users, result.Lecturers, result.Students, result.Workflows, result.Units, result.Achievements)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// =====================
+// reconcile [--fix] [--json]
+// =====================
+// exit 1 bila masih ada temuan yang belum diperbaiki
+func reconcileCommand(args []string) int {
+	fs := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	fix := fs.Bool("fix", false, "repair issues that are safe to repair")
+	asJSON := fs.Bool("json", false, "print the full report as JSON")
+	fs.Parse(args)
+
+	store, err := storage.New(config.Env)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "init storage:", err)
+		return 1
+	}
+
+	achievementRepo := repository.NewAchievementRepo(database.PostgresDB, database.MongoDB)
+	reconciler := reconcile.New(repository.NewReconcileRepo(database.PostgresDB, database.MongoDB), achievementRepo, store)
+
+	report, err := reconciler.Run(context.Background(), *fix)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "reconcile:", err)
+		return 1
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		printReport(report)
+		for _, is := range report.Issues {
+			state := ""
+			switch {
+			case is.Fixed:
+				state = " [fixed]"
+			case is.FixError != "":
+				state = " [fix failed: " + is.FixError + "]"
+			case is.Fixable:
+				state = " [fixable]"
+			}
+			fmt.Printf("%-17s ref=%s mongo=%s attachment=%s: %s%s\n",
+				is.Kind, dash(is.RefID), dash(is.MongoID), dash(is.AttachmentID), is.Detail, state)
+		}
+	}
+
+	for _, is := range report.Issues {
+		if !is.Fixed {
+			return 1
+		}
+	}
+	return 0
+}
+
+// =====================
+// import-users <file.csv|file.xlsx> [--dry-run] [--role student|lecturer]
+//              [--default-password X] [--chunk N] [--report out.csv]
+// =====================
+// exit 1 bila ada baris invalid / gagal
+func importUsersCommand(args []string) int {
+	fs := flag.NewFlagSet("import-users", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "validate only, do not create users")
+	role := fs.String("role", "student", "role for rows without a role column")
+	password := fs.String("default-password", os.Getenv("IMPORT_DEFAULT_PASSWORD"), "password for rows without a password column")
+	chunk := fs.Int("chunk", userimport.DefaultChunkSize, "rows per transaction")
+	reportPath := fs.String("report", "", "write the per-row report (CSV) to this file")
+
+	// file boleh ditulis sebelum atau sesudah flag
+	var file string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		file, args = args[0], args[1:]
+	}
+	fs.Parse(args)
+	if file == "" {
+		file = fs.Arg(0)
+	}
+	if file == "" {
+		fmt.Fprintln(os.Stderr, "usage: import-users <file.csv|file.xlsx> [--dry-run] [--role R] [--default-password P] [--chunk N] [--report out.csv]")
+		return 2
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	rows, err := userimport.Parse(file, data)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "parse:", err)
+		return 1
+	}
+
+	importer := userimport.New(repository.NewUserImportRepo(database.PostgresDB))
+	importer.ChunkSize = *chunk
+
+	// jejak audit per user seperti POST /users/import
+	var changes []audit.Change
+	imp, err := importer.Run(context.Background(), rows, userimport.Options{
+		Filename:        filepath.Base(file),
+		DryRun:          *dryRun,
+		DefaultRole:     *role,
+		DefaultPassword: *password,
+		OnCreated: func(id string, created map[string]interface{}) {
+			changes = append(changes, audit.Change{ResourceType: "user", ResourceID: id, After: created})
+		},
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import:", err)
+		return 1
+	}
+	if !imp.DryRun {
+		entries := audit.System("user:import", "import-users "+filepath.Base(file), imp.ID, changes)
+		if err := repository.NewAuditRepo(database.PostgresDB).Insert(entries); err != nil {
+			fmt.Fprintln(os.Stderr, "audit:", err)
+		}
+	}
+
+	mode := "imported"
+	if imp.DryRun {
+		mode = "dry run"
+	}
+	fmt.Printf("%s %s: %d rows, %d created, %d invalid, %d failed (import id %s)\n",
+		mode, imp.Filename, imp.Total, imp.Created, imp.Invalid, imp.Failed, imp.ID)
+	for _, r := range imp.Rows {
+		if len(r.Errors) > 0 {
+			fmt.Printf("  row %d %s [%s]: %s\n", r.Row, dash(r.Username), r.Status, strings.Join(r.Errors, "; "))
+		}
+	}
+
+	if *reportPath != "" {
+		out, err := os.Create(*reportPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer out.Close()
+		if err := userimport.WriteReport(out, imp, false); err != nil {
+			fmt.Fprintln(os.Stderr, "write report:", err)
+			return 1
+		}
+	}
+
+	if imp.Invalid > 0 || imp.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func printReport(report *model.ReconcileReport) {
+	fmt.Printf("checked %d references, %d mongo documents, %d attachments\n",
+		report.References, report.Documents, report.Attachments)
+
+	kinds := make([]string, 0, len(report.Counts))
+	for k := range report.Counts {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	for _, k := range kinds {
+		fmt.Printf("  %-17s %d\n", k, report.Counts[k])
+	}
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
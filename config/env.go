@@ -3,15 +3,49 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
 	AppPort     string
+	AppBaseURL  string
 	JWTSecret   string
 	PostgresURI string
 	MongoURI    string
+
+	// Notifikasi
+	DefaultLocale string
+	SMTPHost      string
+	SMTPPort      string
+	SMTPUsername  string
+	SMTPPassword  string
+	SMTPFrom      string
+
+	// Penyimpanan lampiran
+	StorageDriver   string // local | s3
+	StorageLocalDir string
+	S3Endpoint      string
+	S3Region        string
+	S3Bucket        string
+	S3AccessKey     string
+	S3SecretKey     string
+	S3UseSSL        bool
+
+	// Batas ukuran satu lampiran (byte)
+	AttachmentMaxSize int64
+	// Masa berlaku signed URL unduhan lampiran
+	AttachmentURLTTL time.Duration
+	// Daemon antivirus (host:port atau unix:/path/clamd.sock)
+	ClamdAddr string
+
+	// Jalankan migration yang tertunda saat server start
+	AutoMigrate bool
+	// development | demo | test | production. Tanpa default: seed hanya
+	// jalan bila diisi eksplisit dengan environment non-production
+	AppEnv string
 }
 
 var Env Config
@@ -24,8 +58,47 @@ func LoadEnv() {
 
 	Env = Config{
 		AppPort:     os.Getenv("APP_PORT"),
+		AppBaseURL:  os.Getenv("APP_BASE_URL"),
 		JWTSecret:   os.Getenv("JWT_SECRET"),
 		PostgresURI: os.Getenv("POSTGRES_URI"),
 		MongoURI:    os.Getenv("MONGO_URI"),
+
+		DefaultLocale: getEnv("DEFAULT_LOCALE", "id"),
+		SMTPHost:      os.Getenv("SMTP_HOST"),
+		SMTPPort:      getEnv("SMTP_PORT", "25"),
+		SMTPUsername:  os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:  os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:      getEnv("SMTP_FROM", "no-reply@localhost"),
+
+		StorageDriver:   getEnv("STORAGE_DRIVER", "local"),
+		StorageLocalDir: getEnv("STORAGE_LOCAL_DIR", "uploads"),
+		S3Endpoint:      os.Getenv("S3_ENDPOINT"),
+		S3Region:        os.Getenv("S3_REGION"),
+		S3Bucket:        os.Getenv("S3_BUCKET"),
+		S3AccessKey:     os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey:     os.Getenv("S3_SECRET_KEY"),
+		S3UseSSL:        os.Getenv("S3_USE_SSL") == "true",
+
+		AttachmentMaxSize: getEnvInt64("ATTACHMENT_MAX_SIZE_MB", 10) << 20,
+		AttachmentURLTTL:  time.Duration(getEnvInt64("ATTACHMENT_URL_TTL_MINUTES", 15)) * time.Minute,
+		ClamdAddr:         os.Getenv("CLAMD_ADDR"),
+
+		AutoMigrate: os.Getenv("AUTO_MIGRATE") == "true",
+		AppEnv:      os.Getenv("APP_ENV"),
+	}
+}
+
+func getEnv(key string, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func getEnvInt64(key string, fallback int64) int64 {
+	v, err := strconv.ParseInt(os.Getenv(key), 10, 64)
+	if err != nil || v <= 0 {
+		return fallback
 	}
+	return v
 }
@@ -31,8 +31,8 @@ func Connect() {
 // =====================================================
 // ===============   POSTGRESQL CONNECT   ===============
 // =====================================================
-func connectPostgres() {
-	dsn := fmt.Sprintf(
+func PostgresDSN() string {
+	return fmt.Sprintf(
 		"postgres://%s:%s@%s:%s/%s?sslmode=disable",
 		os.Getenv("DB_USER"),
 		os.Getenv("DB_PASSWORD"),
@@ -40,6 +40,10 @@ func connectPostgres() {
 		os.Getenv("DB_PORT"),
 		os.Getenv("DB_NAME"),
 	)
+}
+
+func connectPostgres() {
+	dsn := PostgresDSN()
 
 	db, err := sqlx.Connect("postgres", dsn)
 	if err != nil {
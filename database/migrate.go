@@ -1,218 +1,313 @@
 package database
 
 import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
 	"fmt"
-	"log"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/jmoiron/sqlx"
-	"golang.org/x/crypto/bcrypt"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func Migrate(db *sqlx.DB) {
-
-	fmt.Println("Running migration...")
-
-	// ============================================
-	// GENERATE HASH PASSWORD DEFAULT: "password123"
-	// ============================================
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
-	password := string(hashedPassword)
-
-	// ============================================
-	// INSERT ROLES
-	// ============================================
-	db.Exec(`
-		INSERT INTO roles (id, name, description)
-		VALUES 
-			(gen_random_uuid(), 'admin', 'Administrator'),
-			(gen_random_uuid(), 'student', 'Mahasiswa'),
-			(gen_random_uuid(), 'lecturer', 'Dosen Pembimbing')
-		ON CONFLICT (name) DO NOTHING
-	`)
+// File migration: migrations/NNNN_nama.up.sql dan NNNN_nama.down.sql.
+// Migration Mongo (index) didaftarkan di mongo.go dengan nomor versi
+// yang sama urutannya, sehingga kedua store ikut satu riwayat.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var migrationName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// kunci pg_advisory_lock supaya hanya satu proses yang migrasi
+const migrationLockID = 72310418
+
+// Migration adalah satu langkah skema, SQL (Postgres) atau fungsi (Mongo)
+type Migration struct {
+	Version   int
+	Name      string
+	UpSQL     string
+	DownSQL   string
+	MongoUp   func(ctx context.Context, db *mongo.Database) error
+	MongoDown func(ctx context.Context, db *mongo.Database) error
+}
 
-	// Ambil role ID
-	var adminRole, studentRole, lecturerRole string
-	db.Get(&adminRole, "SELECT id FROM roles WHERE name='admin'")
-	db.Get(&studentRole, "SELECT id FROM roles WHERE name='student'")
-	db.Get(&lecturerRole, "SELECT id FROM roles WHERE name='lecturer'")
-
-	// ============================================
-	// INSERT PERMISSIONS
-	// ============================================
-	permissions := []struct {
-		Resource string
-		Action   string
-	}{
-		// Student
-		{"achievements", "create"},
-		{"achievements", "update"},
-		{"achievements", "delete"},
-		{"achievements", "submit"},
-		{"students", "read-self"},
-
-		// Lecturer
-		{"achievements", "verify"},
-		{"achievements", "reject"},
-		{"students", "advisees"},
-
-		// Admin
-		{"achievements", "read-all"},
-		{"achievements", "stats"},
-		{"users", "create"},
-		{"users", "read"},
-		{"users", "update"},
-		{"users", "delete"},
-		{"users", "update-role"},
-		{"students", "assign-advisor"},
-	}
-
-	permissionIDs := make(map[string]string)
-
-	for _, p := range permissions {
-		var permissionID string
-
-		// Pakai _ agar tidak error
-		_ = db.QueryRow(`
-			INSERT INTO permissions (id, resource, action)
-			VALUES (gen_random_uuid(), $1, $2)
-			ON CONFLICT (resource, action) DO NOTHING
-			RETURNING id
-		`, p.Resource, p.Action).Scan(&permissionID)
-
-		// Jika RETURNING id kosong, ambil id yang sudah ada
-		if permissionID == "" {
-			db.Get(&permissionID,
-				"SELECT id FROM permissions WHERE resource=$1 AND action=$2",
-				p.Resource, p.Action)
-		}
+func (m Migration) checksum() string {
+	if m.MongoUp != nil {
+		return "mongo"
+	}
+	sum := sha256.Sum256([]byte(m.UpSQL))
+	return hex.EncodeToString(sum[:])
+}
+
+// MigrationStatus untuk `migrate status`
+type MigrationStatus struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Store     string     `json:"store"`
+	AppliedAt *time.Time `json:"applied_at"`
+	Modified  bool       `json:"modified"` // file berubah setelah dijalankan
+}
+
+type Migrator struct {
+	DB         *sqlx.DB
+	Mongo      *mongo.Database
+	Migrations []Migration
+}
 
-		permissionIDs[p.Resource+":"+p.Action] = permissionID
+func NewMigrator(db *sqlx.DB, mongoDB *mongo.Database) (*Migrator, error) {
+	list, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{DB: db, Mongo: mongoDB, Migrations: list}, nil
+}
+
+func loadMigrations() ([]Migration, error) {
+	byVersion := map[int]*Migration{}
+
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
 	}
 
-	// ============================================
-	// INSERT ROLE_PERMISSIONS
-	// ============================================
-	assign := func(roleID string, key string) {
-		permID := permissionIDs[key]
-		_, err := db.Exec(`
-			INSERT INTO role_permissions (id, role_id, permission_id)
-			VALUES (gen_random_uuid(), $1, $2)
-			ON CONFLICT DO NOTHING
-		`, roleID, permID)
+	for _, e := range entries {
+		match := migrationName.FindStringSubmatch(e.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migrate: invalid file name %s", e.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
 
+		body, err := migrationFiles.ReadFile(path.Join("migrations", e.Name()))
 		if err != nil {
-			log.Println("Error assign permission:", err)
+			return nil, err
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migrate: version %d used by %s and %s", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.UpSQL = string(body)
+		} else {
+			m.DownSQL = string(body)
 		}
 	}
 
-	// Student
-	for _, key := range []string{
-		"achievements:create",
-		"achievements:update",
-		"achievements:delete",
-		"achievements:submit",
-		"students:read-self",
-	} {
-		assign(studentRole, key)
-	}
-
-	// Lecturer
-	for _, key := range []string{
-		"achievements:verify",
-		"achievements:reject",
-		"students:advisees",
-	} {
-		assign(lecturerRole, key)
-	}
-
-	// Admin
-	for _, key := range []string{
-		"achievements:read-all",
-		"achievements:stats",
-		"users:create",
-		"users:read",
-		"users:update",
-		"users:delete",
-		"users:update-role",
-		"students:assign-advisor",
-	} {
-		assign(adminRole, key)
-	}
-
-	// ============================================
-	// INSERT STUDENTS
-	// ============================================
-	students := []struct {
-		Username string
-		FullName string
-		Email    string
-		NIM      string
-	}{
-		{"student1", "Mahasiswa Satu", "student1@mail.com", "2023001"},
-		{"student2", "Mahasiswa Dua", "student2@mail.com", "2023002"},
-		{"student3", "Mahasiswa Tiga", "student3@mail.com", "2023003"},
-		{"student4", "Mahasiswa Empat", "student4@mail.com", "2023004"},
-		{"student5", "Mahasiswa Lima", "student5@mail.com", "2023005"},
-		{"student6", "Mahasiswa Enam", "student6@mail.com", "2023006"},
-	}
-
-	for _, s := range students {
-
-		var userID string
-
-		err := db.QueryRow(`
-			INSERT INTO users (id, username, email, password_hash, full_name, role_id)
-			VALUES (gen_random_uuid(), $1, $2, $3, $4, $5)
-			RETURNING id
-		`, s.Username, s.Email, password, s.FullName, studentRole).Scan(&userID)
+	for _, mm := range mongoMigrations {
+		if _, dup := byVersion[mm.Version]; dup {
+			return nil, fmt.Errorf("migrate: version %d used by sql and mongo migration", mm.Version)
+		}
+		m := mm
+		byVersion[m.Version] = &m
+	}
 
-		if err != nil {
-			log.Println("Failed insert student:", err)
-			continue
+	list := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.MongoUp == nil && m.UpSQL == "" {
+			return nil, fmt.Errorf("migrate: %04d_%s has no up migration", m.Version, m.Name)
 		}
+		list = append(list, *m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+// =====================
+// LOCK + BOOKKEEPING
+// =====================
+
+// withLock menjalankan fn di satu koneksi yang memegang advisory lock.
+// Proses lain yang migrasi bersamaan akan menunggu sampai lock dilepas.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sqlx.Conn) error) error {
+	conn, err := m.DB.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
 
-		db.Exec(`
-			INSERT INTO students (id, user_id, student_id, program_study, academic_year)
-			VALUES (gen_random_uuid(), $1, $2, 'Informatika', '2023')
-		`, userID, s.NIM)
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("migrate: acquire lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID)
+
+	_, err = conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    BIGINT PRIMARY KEY,
+			name       TEXT NOT NULL,
+			checksum   TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("migrate: create schema_migrations: %w", err)
 	}
 
-	// ============================================
-	// INSERT LECTURERS
-	// ============================================
-	names := []string{"Tessa", "Arman", "Eto", "Alifian", "Indah", "Endah"}
+	return fn(conn)
+}
 
-	for _, name := range names {
+type appliedMigration struct {
+	Version   int       `db:"version"`
+	Checksum  string    `db:"checksum"`
+	AppliedAt time.Time `db:"applied_at"`
+}
 
-		var userID string
-		username := "lecturer_" + name
-		email := username + "@mail.com"
+func applied(ctx context.Context, q sqlx.QueryerContext) (map[int]appliedMigration, error) {
+	rows := []appliedMigration{}
+	if err := sqlx.SelectContext(ctx, q, &rows, `SELECT version, checksum, applied_at FROM schema_migrations`); err != nil {
+		return nil, err
+	}
 
-		err := db.QueryRow(`
-			INSERT INTO users (id, username, email, password_hash, full_name, role_id)
-			VALUES (gen_random_uuid(), $1, $2, $3, $4, $5)
-			RETURNING id
-		`, username, email, password, "Dosen "+name, lecturerRole).Scan(&userID)
+	done := map[int]appliedMigration{}
+	for _, r := range rows {
+		done[r.Version] = r
+	}
+	return done, nil
+}
 
+// =====================
+// UP / DOWN / STATUS
+// =====================
+
+// Up menjalankan migration yang belum diterapkan sampai target (0 = terbaru)
+func (m *Migrator) Up(ctx context.Context, target int) ([]Migration, error) {
+	ran := []Migration{}
+
+	err := m.withLock(ctx, func(conn *sqlx.Conn) error {
+		done, err := applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mg := range m.Migrations {
+			if target > 0 && mg.Version > target {
+				break
+			}
+			if _, ok := done[mg.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, mg, true); err != nil {
+				return fmt.Errorf("migrate: %04d_%s up: %w", mg.Version, mg.Name, err)
+			}
+			ran = append(ran, mg)
+		}
+		return nil
+	})
+
+	return ran, err
+}
+
+// Down membatalkan steps migration terakhir yang sudah diterapkan
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	ran := []Migration{}
+
+	err := m.withLock(ctx, func(conn *sqlx.Conn) error {
+		done, err := applied(ctx, conn)
 		if err != nil {
-			log.Println("Failed insert lecturer:", err)
-			continue
+			return err
+		}
+
+		for i := len(m.Migrations) - 1; i >= 0 && len(ran) < steps; i-- {
+			mg := m.Migrations[i]
+			if _, ok := done[mg.Version]; !ok {
+				continue
+			}
+			if (mg.MongoUp != nil && mg.MongoDown == nil) || (mg.MongoUp == nil && mg.DownSQL == "") {
+				return fmt.Errorf("migrate: %04d_%s has no down migration", mg.Version, mg.Name)
+			}
+			if err := m.apply(ctx, conn, mg, false); err != nil {
+				return fmt.Errorf("migrate: %04d_%s down: %w", mg.Version, mg.Name, err)
+			}
+			ran = append(ran, mg)
+		}
+		return nil
+	})
+
+	return ran, err
+}
+
+// apply menjalankan satu migration dan mencatatnya. Migration SQL berjalan
+// dalam satu transaksi bersama catatan schema_migrations; migration Mongo
+// harus idempotent karena tidak bisa ikut transaksi.
+func (m *Migrator) apply(ctx context.Context, conn *sqlx.Conn, mg Migration, up bool) error {
+	if mg.MongoUp != nil {
+		fn := mg.MongoUp
+		if !up {
+			fn = mg.MongoDown
 		}
+		if fn != nil {
+			if err := fn(ctx, m.Mongo); err != nil {
+				return err
+			}
+		}
+	}
+
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	body := mg.UpSQL
+	if !up {
+		body = mg.DownSQL
+	}
+	if body != "" {
+		if _, err := tx.ExecContext(ctx, body); err != nil {
+			return err
+		}
+	}
 
-		db.Exec(`
-			INSERT INTO lecturers (id, user_id, lecturer_id, department)
-			VALUES (gen_random_uuid(), $1, $2, 'Teknik Informatika')
-		`, userID, "D"+userID[:8])
+	if up {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO schema_migrations (version, name, checksum, applied_at)
+			VALUES ($1, $2, $3, NOW())
+		`, mg.Version, mg.Name, mg.checksum())
+	} else {
+		_, err = tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mg.Version)
 	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Status menampilkan semua migration beserta waktu diterapkan
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	list := []MigrationStatus{}
 
-	// ============================================
-	// INSERT ADMIN
-	// ============================================
-	db.Exec(`
-		INSERT INTO users (id, username, email, password_hash, full_name, role_id)
-		VALUES (gen_random_uuid(), 'admin', 'admin@mail.com', $1, 'Super Admin', $2)
-		ON CONFLICT (username) DO NOTHING
-	`, password, adminRole)
+	err := m.withLock(ctx, func(conn *sqlx.Conn) error {
+		done, err := applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mg := range m.Migrations {
+			st := MigrationStatus{Version: mg.Version, Name: mg.Name, Store: "postgres"}
+			if mg.MongoUp != nil {
+				st.Store = "mongo"
+			}
+			if a, ok := done[mg.Version]; ok {
+				at := a.AppliedAt
+				st.AppliedAt = &at
+				st.Modified = a.Checksum != mg.checksum()
+			}
+			list = append(list, st)
+		}
+		return nil
+	})
 
-	fmt.Println("Migration completed!")
+	return list, err
 }
@@ -0,0 +1,114 @@
+-- Skema awal: tabel yang sudah dipakai sebelum ada migration.
+-- Semua memakai IF NOT EXISTS supaya database lama bisa diadopsi.
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+CREATE TABLE IF NOT EXISTS roles (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name        VARCHAR(50) NOT NULL UNIQUE,
+    description TEXT,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    resource    VARCHAR(50) NOT NULL,
+    action      VARCHAR(50) NOT NULL,
+    description TEXT,
+    UNIQUE (resource, action)
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    role_id       UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id UUID NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+    UNIQUE (role_id, permission_id)
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    username      VARCHAR(50) NOT NULL UNIQUE,
+    email         VARCHAR(100) NOT NULL UNIQUE,
+    password_hash VARCHAR(255) NOT NULL,
+    full_name     VARCHAR(100) NOT NULL,
+    role_id       UUID NOT NULL REFERENCES roles(id),
+    is_active     BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS lecturers (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    lecturer_id VARCHAR(20) NOT NULL UNIQUE,
+    department  VARCHAR(100),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS students (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id       UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    student_id    VARCHAR(20) NOT NULL UNIQUE,
+    program_study VARCHAR(100),
+    academic_year VARCHAR(10),
+    advisor_id    UUID REFERENCES lecturers(id) ON DELETE SET NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_students_advisor ON students (advisor_id);
+
+CREATE TABLE IF NOT EXISTS achievement_references (
+    id                   UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    student_id           UUID NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    mongo_achievement_id VARCHAR(24) NOT NULL,
+    status               VARCHAR(30) NOT NULL DEFAULT 'draft',
+    submitted_at         TIMESTAMPTZ,
+    verified_at          TIMESTAMPTZ,
+    verified_by          UUID REFERENCES users(id),
+    rejection_note       TEXT,
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at           TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_references_student ON achievement_references (student_id);
+CREATE INDEX IF NOT EXISTS idx_achievement_references_status ON achievement_references (status);
+
+CREATE TABLE IF NOT EXISTS achievement_history (
+    id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    achievement_ref_id UUID NOT NULL REFERENCES achievement_references(id) ON DELETE CASCADE,
+    old_status         VARCHAR(30),
+    new_status         VARCHAR(30) NOT NULL,
+    changed_by         UUID REFERENCES users(id),
+    note               TEXT,
+    changed_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_history_ref ON achievement_history (achievement_ref_id, changed_at);
+
+CREATE TABLE IF NOT EXISTS achievement_attachments (
+    id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    achievement_ref_id UUID NOT NULL REFERENCES achievement_references(id) ON DELETE CASCADE,
+    file_url           TEXT NOT NULL,
+    file_type          VARCHAR(50),
+    uploaded_by        UUID REFERENCES users(id),
+    uploaded_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_attachments_ref ON achievement_attachments (achievement_ref_id);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title      VARCHAR(200) NOT NULL,
+    message    TEXT NOT NULL,
+    is_read    BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications (user_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    token      TEXT PRIMARY KEY,
+    user_id    UUID REFERENCES users(id) ON DELETE CASCADE,
+    expired_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS achievement_revision_comments;
+
+ALTER TABLE achievement_history DROP COLUMN IF EXISTS stage;
+
+ALTER TABLE achievement_references
+    DROP COLUMN IF EXISTS current_stage,
+    DROP COLUMN IF EXISTS workflow_stages;
+
+DROP TABLE IF EXISTS achievement_workflows;
@@ -0,0 +1,27 @@
+-- Rantai verifikasi bertahap per kategori / tingkat prestasi
+CREATE TABLE IF NOT EXISTS achievement_workflows (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name       VARCHAR(100) NOT NULL,
+    category   VARCHAR(50),
+    level      VARCHAR(50),
+    stages     TEXT[] NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE achievement_references
+    ADD COLUMN IF NOT EXISTS workflow_stages TEXT[],
+    ADD COLUMN IF NOT EXISTS current_stage INT NOT NULL DEFAULT 0;
+
+ALTER TABLE achievement_history
+    ADD COLUMN IF NOT EXISTS stage VARCHAR(50);
+
+CREATE TABLE IF NOT EXISTS achievement_revision_comments (
+    id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    history_id         UUID NOT NULL REFERENCES achievement_history(id) ON DELETE CASCADE,
+    achievement_ref_id UUID NOT NULL REFERENCES achievement_references(id) ON DELETE CASCADE,
+    field              VARCHAR(50) NOT NULL,
+    comment            TEXT NOT NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_revision_comments_history ON achievement_revision_comments (history_id);
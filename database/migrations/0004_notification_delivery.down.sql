@@ -0,0 +1,11 @@
+ALTER TABLE users DROP COLUMN IF EXISTS locale;
+
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notification_outbox;
+
+DROP INDEX IF EXISTS idx_notifications_unread;
+
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS read_at,
+    DROP COLUMN IF EXISTS reference_id,
+    DROP COLUMN IF EXISTS type;
@@ -0,0 +1,36 @@
+-- Notifikasi bertipe, outbox pengiriman email, preferensi dan locale user
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS type VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS reference_id UUID,
+    ADD COLUMN IF NOT EXISTS read_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications (user_id) WHERE NOT is_read;
+
+CREATE TABLE IF NOT EXISTS notification_outbox (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    notification_id UUID REFERENCES notifications(id) ON DELETE SET NULL,
+    channel         VARCHAR(20) NOT NULL,
+    recipient       VARCHAR(200) NOT NULL,
+    subject         TEXT NOT NULL,
+    body            TEXT NOT NULL,
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts        INT NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at         TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_outbox_due
+    ON notification_outbox (next_attempt_at) WHERE status IN ('pending', 'sending');
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id           UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel           VARCHAR(20) NOT NULL,
+    notification_type VARCHAR(50) NOT NULL,
+    enabled           BOOLEAN NOT NULL,
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, channel, notification_type)
+);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale VARCHAR(10);
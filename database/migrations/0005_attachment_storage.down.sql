@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_achievement_attachments_storage_key;
+
+ALTER TABLE achievement_attachments
+    DROP COLUMN IF EXISTS file_size,
+    DROP COLUMN IF EXISTS checksum,
+    DROP COLUMN IF EXISTS storage_key,
+    DROP COLUMN IF EXISTS content_type,
+    DROP COLUMN IF EXISTS file_name;
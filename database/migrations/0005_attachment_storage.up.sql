@@ -0,0 +1,9 @@
+-- Lampiran di storage content-addressed (local / S3)
+ALTER TABLE achievement_attachments
+    ADD COLUMN IF NOT EXISTS file_name VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS content_type VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS storage_key TEXT,
+    ADD COLUMN IF NOT EXISTS checksum VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS file_size BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_achievement_attachments_storage_key ON achievement_attachments (storage_key);
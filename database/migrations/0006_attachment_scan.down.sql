@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_achievement_attachments_scan_due;
+
+ALTER TABLE achievement_attachments
+    DROP COLUMN IF EXISTS scanned_at,
+    DROP COLUMN IF EXISTS scan_next_at,
+    DROP COLUMN IF EXISTS scan_attempts,
+    DROP COLUMN IF EXISTS scan_error,
+    DROP COLUMN IF EXISTS scan_signature,
+    DROP COLUMN IF EXISTS scan_status;
@@ -0,0 +1,13 @@
+-- Karantina + scan antivirus (clamd). Lampiran lama dianggap bersih.
+ALTER TABLE achievement_attachments
+    ADD COLUMN IF NOT EXISTS scan_status VARCHAR(20) NOT NULL DEFAULT 'clean',
+    ADD COLUMN IF NOT EXISTS scan_signature TEXT,
+    ADD COLUMN IF NOT EXISTS scan_error TEXT,
+    ADD COLUMN IF NOT EXISTS scan_attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS scan_next_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD COLUMN IF NOT EXISTS scanned_at TIMESTAMPTZ;
+
+ALTER TABLE achievement_attachments ALTER COLUMN scan_status SET DEFAULT 'pending_scan';
+
+CREATE INDEX IF NOT EXISTS idx_achievement_attachments_scan_due
+    ON achievement_attachments (scan_next_at) WHERE scan_status = 'pending_scan';
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_achievement_attachments_preview_due;
+
+ALTER TABLE achievement_attachments
+    DROP COLUMN IF EXISTS preview_next_at,
+    DROP COLUMN IF EXISTS preview_attempts,
+    DROP COLUMN IF EXISTS preview_error,
+    DROP COLUMN IF EXISTS preview_key,
+    DROP COLUMN IF EXISTS preview_status;
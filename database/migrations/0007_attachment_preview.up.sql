@@ -0,0 +1,13 @@
+-- Thumbnail / preview lampiran yang dibuat worker di background
+ALTER TABLE achievement_attachments
+    ADD COLUMN IF NOT EXISTS preview_status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    ADD COLUMN IF NOT EXISTS preview_key TEXT,
+    ADD COLUMN IF NOT EXISTS preview_error TEXT,
+    ADD COLUMN IF NOT EXISTS preview_attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS preview_next_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS idx_achievement_attachments_preview_due
+    ON achievement_attachments (preview_next_at) WHERE preview_status = 'pending';
+
+-- lampiran lama (file_url saja, tanpa storage_key) tidak bisa dibuatkan preview
+UPDATE achievement_attachments SET preview_status = 'unsupported' WHERE storage_key IS NULL;
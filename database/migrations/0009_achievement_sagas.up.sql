@@ -0,0 +1,16 @@
+-- Saga create/delete lintas Postgres ↔ Mongo (lihat package saga)
+CREATE TABLE IF NOT EXISTS achievement_sagas (
+    id              UUID PRIMARY KEY,
+    kind            VARCHAR(30) NOT NULL,
+    ref_id          UUID NOT NULL,
+    mongo_id        VARCHAR(24) NOT NULL,
+    state           VARCHAR(20) NOT NULL DEFAULT 'started',
+    attempts        INT NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_sagas_open
+    ON achievement_sagas (next_attempt_at) WHERE state IN ('started', 'compensating');
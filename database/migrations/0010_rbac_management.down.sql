@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE resource = 'roles' AND action IN ('read', 'manage');
+
+ALTER TABLE roles DROP COLUMN IF EXISTS is_system;
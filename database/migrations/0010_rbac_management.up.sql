@@ -0,0 +1,18 @@
+-- RBAC dinamis: role bawaan tidak boleh dihapus / diganti nama, dan admin
+-- mendapat permission untuk mengelola role & permission
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS is_system BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE roles SET is_system = TRUE WHERE name IN ('admin', 'student', 'lecturer');
+
+INSERT INTO permissions (id, resource, action, description)
+VALUES
+    (gen_random_uuid(), 'roles', 'read', 'Lihat role & permission'),
+    (gen_random_uuid(), 'roles', 'manage', 'Kelola role, permission dan assignment')
+ON CONFLICT (resource, action) DO NOTHING;
+
+INSERT INTO role_permissions (id, role_id, permission_id)
+SELECT gen_random_uuid(), r.id, p.id
+FROM roles r
+JOIN permissions p ON p.resource = 'roles'
+WHERE r.name = 'admin'
+ON CONFLICT (role_id, permission_id) DO NOTHING;
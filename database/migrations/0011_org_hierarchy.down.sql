@@ -0,0 +1,13 @@
+DELETE FROM role_permissions
+WHERE permission_id IN (SELECT id FROM permissions WHERE resource = 'org' AND action = 'manage');
+DELETE FROM permissions WHERE resource = 'org' AND action = 'manage';
+
+DROP TABLE IF EXISTS unit_admins;
+DROP VIEW IF EXISTS student_units;
+
+ALTER TABLE students DROP COLUMN IF EXISTS study_program_id;
+ALTER TABLE lecturers DROP COLUMN IF EXISTS department_id;
+
+DROP TABLE IF EXISTS study_programs;
+DROP TABLE IF EXISTS departments;
+DROP TABLE IF EXISTS faculties;
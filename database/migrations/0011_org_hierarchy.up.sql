@@ -0,0 +1,79 @@
+-- Struktur organisasi: fakultas → departemen → program studi. Kolom teks
+-- lama (lecturers.department, students.program_study) tetap ada untuk
+-- tampilan; relasi resmi lewat department_id / study_program_id.
+CREATE TABLE IF NOT EXISTS faculties (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code       VARCHAR(20) NOT NULL UNIQUE,
+    name       VARCHAR(150) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS departments (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    faculty_id   UUID NOT NULL REFERENCES faculties(id) ON DELETE RESTRICT,
+    code         VARCHAR(20) NOT NULL UNIQUE,
+    name         VARCHAR(150) NOT NULL,
+    head_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_departments_faculty ON departments (faculty_id);
+CREATE INDEX IF NOT EXISTS idx_departments_head ON departments (head_user_id);
+
+CREATE TABLE IF NOT EXISTS study_programs (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    department_id UUID NOT NULL REFERENCES departments(id) ON DELETE RESTRICT,
+    code          VARCHAR(20) NOT NULL UNIQUE,
+    name          VARCHAR(150) NOT NULL,
+    degree        VARCHAR(10) NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_study_programs_department ON study_programs (department_id);
+
+ALTER TABLE lecturers ADD COLUMN IF NOT EXISTS department_id UUID REFERENCES departments(id) ON DELETE SET NULL;
+ALTER TABLE students ADD COLUMN IF NOT EXISTS study_program_id UUID REFERENCES study_programs(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_lecturers_department ON lecturers (department_id);
+CREATE INDEX IF NOT EXISTS idx_students_study_program ON students (study_program_id);
+
+-- Unit tiap mahasiswa. Mahasiswa tanpa program studi memakai departemen
+-- dosen walinya supaya routing kepala departemen tetap jalan.
+CREATE OR REPLACE VIEW student_units AS
+SELECT
+    s.id           AS student_id,
+    sp.id          AS study_program_id,
+    d.id           AS department_id,
+    d.faculty_id   AS faculty_id,
+    d.head_user_id AS head_user_id
+FROM students s
+LEFT JOIN study_programs sp ON sp.id = s.study_program_id
+LEFT JOIN lecturers l ON l.id = s.advisor_id
+LEFT JOIN departments d ON d.id = COALESCE(sp.department_id, l.department_id);
+
+-- Delegasi admin per unit: admin fakultas hanya melihat fakultasnya
+CREATE TABLE IF NOT EXISTS unit_admins (
+    user_id    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    unit_type  VARCHAR(20) NOT NULL CHECK (unit_type IN ('faculty', 'department', 'study_program')),
+    unit_id    UUID NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, unit_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_unit_admins_unit ON unit_admins (unit_id);
+
+INSERT INTO permissions (id, resource, action, description)
+VALUES
+    (gen_random_uuid(), 'org', 'manage', 'Kelola fakultas, departemen, program studi dan admin unit')
+ON CONFLICT (resource, action) DO NOTHING;
+
+INSERT INTO role_permissions (id, role_id, permission_id)
+SELECT gen_random_uuid(), r.id, p.id
+FROM roles r
+JOIN permissions p ON p.resource = 'org' AND p.action = 'manage'
+WHERE r.name = 'admin'
+ON CONFLICT (role_id, permission_id) DO NOTHING;
@@ -0,0 +1,16 @@
+-- Riwayat impor user massal (CSV / XLSX); hasil per baris disimpan supaya
+-- laporan error bisa diunduh ulang
+CREATE TABLE IF NOT EXISTS user_imports (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    filename   VARCHAR(255) NOT NULL,
+    dry_run    BOOLEAN NOT NULL DEFAULT FALSE,
+    total      INT NOT NULL DEFAULT 0,
+    created    INT NOT NULL DEFAULT 0,
+    invalid    INT NOT NULL DEFAULT 0,
+    failed     INT NOT NULL DEFAULT 0,
+    rows       JSONB NOT NULL DEFAULT '[]',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_imports_created_at ON user_imports (created_at DESC);
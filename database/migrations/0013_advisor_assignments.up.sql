@@ -0,0 +1,27 @@
+-- Riwayat dosen wali per mahasiswa. students.advisor_id tetap menjadi
+-- advisor aktif; setiap pergantian menutup baris lama (effective_to) dan
+-- membuka baris baru, sehingga hanya ada satu baris aktif per mahasiswa.
+CREATE TABLE IF NOT EXISTS advisor_assignments (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    student_id          UUID NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    advisor_id          UUID REFERENCES lecturers(id) ON DELETE SET NULL,
+    previous_advisor_id UUID REFERENCES lecturers(id) ON DELETE SET NULL,
+    method              VARCHAR(20) NOT NULL CHECK (method IN ('initial', 'manual', 'bulk', 'auto', 'import')),
+    assigned_by         UUID REFERENCES users(id) ON DELETE SET NULL,
+    effective_from      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    effective_to        TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_advisor_assignments_active
+    ON advisor_assignments (student_id) WHERE effective_to IS NULL;
+CREATE INDEX IF NOT EXISTS idx_advisor_assignments_student
+    ON advisor_assignments (student_id, effective_from DESC);
+CREATE INDEX IF NOT EXISTS idx_advisor_assignments_advisor
+    ON advisor_assignments (advisor_id);
+
+-- advisor yang sudah ada dianggap berlaku sejak mahasiswa dibuat
+INSERT INTO advisor_assignments (student_id, advisor_id, method, effective_from)
+SELECT s.id, s.advisor_id, 'initial', s.created_at
+FROM students s
+WHERE s.advisor_id IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM advisor_assignments a WHERE a.student_id = s.id);
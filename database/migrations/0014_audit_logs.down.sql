@@ -0,0 +1,6 @@
+DELETE FROM role_permissions
+WHERE permission_id IN (SELECT id FROM permissions WHERE resource = 'audit' AND action = 'read');
+DELETE FROM permissions WHERE resource = 'audit' AND action = 'read';
+
+DROP TABLE IF EXISTS audit_logs;
+DROP FUNCTION IF EXISTS audit_logs_append_only();
@@ -0,0 +1,55 @@
+-- Jejak audit setiap request yang mengubah data. Tabel append-only:
+-- UPDATE / DELETE / TRUNCATE ditolak trigger, sehingga catatan tidak bisa
+-- diubah lewat aplikasi. Tanpa FK ke users supaya catatan tetap utuh
+-- walau user dihapus.
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    occurred_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    actor_user_id UUID,
+    actor_role    VARCHAR(50) NOT NULL DEFAULT '',
+    action        VARCHAR(100) NOT NULL,
+    resource_type VARCHAR(50) NOT NULL DEFAULT '',
+    resource_id   VARCHAR(100) NOT NULL DEFAULT '',
+    method        VARCHAR(10) NOT NULL,
+    path          VARCHAR(255) NOT NULL,
+    status        INT NOT NULL,
+    ip            VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent    VARCHAR(255) NOT NULL DEFAULT '',
+    request_id    VARCHAR(100) NOT NULL DEFAULT '',
+    before        JSONB,
+    after         JSONB,
+    diff          JSONB
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_occurred_at ON audit_logs (occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor ON audit_logs (actor_user_id, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_resource ON audit_logs (resource_type, resource_id, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs (action);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_request ON audit_logs (request_id);
+
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_logs_no_modify ON audit_logs;
+CREATE TRIGGER audit_logs_no_modify
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+
+DROP TRIGGER IF EXISTS audit_logs_no_truncate ON audit_logs;
+CREATE TRIGGER audit_logs_no_truncate
+    BEFORE TRUNCATE ON audit_logs
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only();
+
+INSERT INTO permissions (id, resource, action, description)
+VALUES (gen_random_uuid(), 'audit', 'read', 'Lihat jejak audit')
+ON CONFLICT (resource, action) DO NOTHING;
+
+INSERT INTO role_permissions (id, role_id, permission_id)
+SELECT gen_random_uuid(), r.id, p.id
+FROM roles r
+JOIN permissions p ON p.resource = 'audit' AND p.action = 'read'
+WHERE r.name = 'admin'
+ON CONFLICT (role_id, permission_id) DO NOTHING;
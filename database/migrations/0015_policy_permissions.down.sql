@@ -0,0 +1,19 @@
+-- Hanya permission yang baru diperkenalkan 0015 yang dihapus; grant ke
+-- permission lama dibiarkan karena tidak bisa dibedakan dari grant manual.
+DELETE FROM role_permissions
+WHERE permission_id IN (
+    SELECT id FROM permissions
+    WHERE (resource, action) IN (
+        ('achievements', 'manage'),
+        ('workflows', 'manage'),
+        ('students', 'read'),
+        ('lecturers', 'read-self')
+    )
+);
+DELETE FROM permissions
+WHERE (resource, action) IN (
+    ('achievements', 'manage'),
+    ('workflows', 'manage'),
+    ('students', 'read'),
+    ('lecturers', 'read-self')
+);
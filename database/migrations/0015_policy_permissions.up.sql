@@ -0,0 +1,56 @@
+-- Tabel policy memakai permission (bukan nama role), sehingga role buatan
+-- admin bisa diberi kemampuan yang sama dengan role bawaan. Grant di bawah
+-- mempertahankan akses role yang sudah ada sebelumnya.
+INSERT INTO permissions (id, resource, action, description)
+VALUES
+    (gen_random_uuid(), 'achievements', 'read-all', 'Lihat semua prestasi & data mahasiswa'),
+    (gen_random_uuid(), 'achievements', 'stats', 'Lihat statistik prestasi'),
+    (gen_random_uuid(), 'achievements', 'create', 'Buat prestasi sendiri'),
+    (gen_random_uuid(), 'achievements', 'update', 'Ubah prestasi sendiri'),
+    (gen_random_uuid(), 'achievements', 'delete', 'Hapus prestasi sendiri'),
+    (gen_random_uuid(), 'achievements', 'submit', 'Ajukan prestasi sendiri'),
+    (gen_random_uuid(), 'achievements', 'verify', 'Putuskan prestasi pada stage yang ditugaskan'),
+    (gen_random_uuid(), 'achievements', 'manage', 'Putuskan stage mana pun, kelola lampiran & cek integritas'),
+    (gen_random_uuid(), 'workflows', 'manage', 'Kelola workflow approval prestasi'),
+    (gen_random_uuid(), 'students', 'read', 'Lihat daftar mahasiswa'),
+    (gen_random_uuid(), 'students', 'read-self', 'Lihat profil mahasiswa sendiri'),
+    (gen_random_uuid(), 'students', 'assign-advisor', 'Tetapkan dosen wali'),
+    (gen_random_uuid(), 'students', 'advisees', 'Lihat mahasiswa bimbingan sendiri'),
+    (gen_random_uuid(), 'lecturers', 'read-self', 'Lihat profil dosen sendiri')
+ON CONFLICT (resource, action) DO NOTHING;
+
+INSERT INTO role_permissions (id, role_id, permission_id)
+SELECT gen_random_uuid(), r.id, p.id
+FROM (VALUES
+    ('admin', 'achievements', 'read-all'),
+    ('admin', 'achievements', 'stats'),
+    ('admin', 'achievements', 'manage'),
+    ('admin', 'workflows', 'manage'),
+    ('admin', 'students', 'read'),
+    ('admin', 'students', 'assign-advisor'),
+    ('student', 'achievements', 'create'),
+    ('student', 'achievements', 'update'),
+    ('student', 'achievements', 'delete'),
+    ('student', 'achievements', 'submit'),
+    ('student', 'students', 'read-self'),
+    ('lecturer', 'achievements', 'verify'),
+    ('lecturer', 'students', 'read'),
+    ('lecturer', 'students', 'advisees'),
+    ('lecturer', 'lecturers', 'read-self'),
+    -- dulu boleh melihat daftar mahasiswa berdasarkan nama role
+    ('head_of_department', 'students', 'read'),
+    ('student_affairs', 'students', 'read')
+) AS g(role, resource, action)
+JOIN roles r ON r.name = g.role
+JOIN permissions p ON p.resource = g.resource AND p.action = g.action
+ON CONFLICT (role_id, permission_id) DO NOTHING;
+
+-- role yang menjadi stage workflow tetap bisa memutuskan stagenya
+INSERT INTO role_permissions (id, role_id, permission_id)
+SELECT gen_random_uuid(), r.id, p.id
+FROM roles r
+JOIN permissions p ON p.resource = 'achievements' AND p.action = 'verify'
+WHERE r.name IN (
+    SELECT DISTINCT unnest(stages) FROM achievement_workflows
+)
+ON CONFLICT (role_id, permission_id) DO NOTHING;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_achievement_references_mongo_id;
@@ -0,0 +1,3 @@
+-- Search mencocokkan hasil Mongo ke reference per batch _id
+CREATE INDEX IF NOT EXISTS idx_achievement_references_mongo_id
+    ON achievement_references (mongo_achievement_id);
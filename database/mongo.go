@@ -1 +1,57 @@
-package database
\ No newline at end of file
+package database
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoMigrations berbagi penomoran dengan file di migrations/.
+// Membuat index bersifat idempotent, jadi aman bila diulang.
+var mongoMigrations = []Migration{
+	{
+		Version: 8,
+		Name:    "achievement_search_indexes",
+		// text index pencarian; bahasa "none" karena isi campuran
+		// Indonesia/Inggris (stemmer Mongo tidak mendukung ID)
+		MongoUp: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("achievements").Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{
+					Keys: bson.D{
+						{Key: "title", Value: "text"},
+						{Key: "description", Value: "text"},
+						{Key: "organizer", Value: "text"},
+					},
+					Options: options.Index().
+						SetName("achievements_text").
+						SetDefaultLanguage("none").
+						SetWeights(bson.M{"title": 10, "organizer": 5, "description": 1}),
+				},
+				// nama default dipertahankan supaya cocok dengan index yang
+				// dulu dibuat saat startup
+				{Keys: bson.D{{Key: "category", Value: 1}, {Key: "level", Value: 1}, {Key: "event_date", Value: -1}}},
+			})
+			return err
+		},
+		MongoDown: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndexes(ctx, db.Collection("achievements"), "achievements_text", "category_1_level_1_event_date_-1")
+		},
+	},
+}
+
+func dropIndexes(ctx context.Context, coll *mongo.Collection, names ...string) error {
+	for _, name := range names {
+		if _, err := coll.Indexes().DropOne(ctx, name); err != nil {
+			var cmdErr mongo.CommandError
+			// IndexNotFound (27) / NamespaceNotFound (26): sudah tidak ada
+			if errors.As(err, &cmdErr) && (cmdErr.Code == 27 || cmdErr.Code == 26) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+// Package seed mengisi database dengan data awal dari fixture YAML/JSON
+// per profil (dev, demo, test). Semua langkah idempotent: menjalankan
+// ulang hanya menambah yang belum ada dan tidak menimpa data yang sudah
+// diubah pengguna (mis. password).
+package seed
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed fixtures/*.yaml
+var fixtureFiles embed.FS
+
+// Fixture adalah isi satu file profil. JSON juga valid karena JSON
+// adalah subset YAML.
+type Fixture struct {
+	Include         []string           `yaml:"include"`
+	DefaultPassword string             `yaml:"default_password"`
+	Roles           []RoleFixture      `yaml:"roles"`
+	Users           []UserFixture      `yaml:"users"`
+	Lecturers       []LecturerFixture  `yaml:"lecturers"`
+	Students        []StudentFixture   `yaml:"students"`
+	Workflows       []WorkflowFixture  `yaml:"workflows"`
+	Faculties       []FacultyFixture   `yaml:"faculties"`
+	Synthetic       *SyntheticStudents `yaml:"synthetic_students"`
+	Achievements    *AchievementPlan   `yaml:"achievements"`
+}
+
+type RoleFixture struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	System      bool     `yaml:"system"`      // role bawaan, tidak bisa dihapus
+	Permissions []string `yaml:"permissions"` // "resource:action"
+}
+
+type UserFixture struct {
+	Username string `yaml:"username"`
+	Email    string `yaml:"email"`
+	FullName string `yaml:"full_name"`
+	Role     string `yaml:"role"`
+	Password string `yaml:"password"`
+}
+
+type LecturerFixture struct {
+	UserFixture `yaml:",inline"`
+	LecturerID  string `yaml:"lecturer_id"`
+	Department  string `yaml:"department"`
+}
+
+type StudentFixture struct {
+	UserFixture  `yaml:",inline"`
+	StudentID    string `yaml:"student_id"`
+	ProgramStudy string `yaml:"program_study"`
+	AcademicYear string `yaml:"academic_year"`
+	Advisor      string `yaml:"advisor"` // lecturer_id
+}
+
+type WorkflowFixture struct {
+	Name     string   `yaml:"name"`
+	Category *string  `yaml:"category"`
+	Level    *string  `yaml:"level"`
+	Stages   []string `yaml:"stages"`
+}
+
+// FacultyFixture: fakultas → departemen → program studi. Dosen & mahasiswa
+// ditautkan lewat nama (lecturers.department / students.program_study).
+type FacultyFixture struct {
+	Code        string              `yaml:"code"`
+	Name        string              `yaml:"name"`
+	Departments []DepartmentFixture `yaml:"departments"`
+}
+
+type DepartmentFixture struct {
+	Code          string                `yaml:"code"`
+	Name          string                `yaml:"name"`
+	Head          string                `yaml:"head"` // username kepala departemen
+	StudyPrograms []StudyProgramFixture `yaml:"study_programs"`
+}
+
+type StudyProgramFixture struct {
+	Code   string `yaml:"code"`
+	Name   string `yaml:"name"`
+	Degree string `yaml:"degree"`
+}
+
+// SyntheticStudents membuat mahasiswa tambahan dengan nama acak
+// (deterministik), advisor dibagi rata ke dosen di fixture
+type SyntheticStudents struct {
+	Count          int      `yaml:"count"`
+	ProgramStudies []string `yaml:"program_studies"`
+	AcademicYears  []string `yaml:"academic_years"`
+}
+
+// AchievementPlan: prestasi sintetis per mahasiswa di Postgres + Mongo
+type AchievementPlan struct {
+	PerStudent int   `yaml:"per_student"`
+	RandomSeed int64 `yaml:"random_seed"`
+}
+
+// Load membaca profil bawaan (nama) atau file (path berakhiran .yaml /
+// .yml / .json) beserta include-nya. Include dicari di folder yang sama,
+// lalu di fixture bawaan.
+func Load(profileOrFile string) (*Fixture, error) {
+	return load(profileOrFile, "", map[string]bool{})
+}
+
+func load(name string, dir string, seen map[string]bool) (*Fixture, error) {
+	body, source, err := readFixture(name, dir)
+	if err != nil {
+		return nil, err
+	}
+	if seen[source] {
+		return nil, fmt.Errorf("seed: include cycle at %s", source)
+	}
+	seen[source] = true
+
+	var own Fixture
+	if err := yaml.Unmarshal(body, &own); err != nil {
+		return nil, fmt.Errorf("seed: parse %s: %w", source, err)
+	}
+
+	merged := &Fixture{}
+	for _, inc := range own.Include {
+		base, err := load(inc, filepath.Dir(source), seen)
+		if err != nil {
+			return nil, err
+		}
+		merged.merge(base)
+	}
+	merged.merge(&own)
+	merged.Include = nil
+	return merged, nil
+}
+
+func readFixture(name string, dir string) ([]byte, string, error) {
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == ".yaml" || ext == ".yml" || ext == ".json" {
+		p := name
+		if !filepath.IsAbs(p) && dir != "" && dir != "." {
+			p = filepath.Join(dir, p)
+		}
+		body, err := os.ReadFile(p)
+		return body, p, err
+	}
+
+	p := path.Join("fixtures", name+".yaml")
+	body, err := fixtureFiles.ReadFile(p)
+	if err != nil {
+		return nil, "", fmt.Errorf("seed: unknown profile %q", name)
+	}
+	return body, p, nil
+}
+
+// merge: daftar digabung (fixture belakangan menang bila kuncinya sama),
+// nilai tunggal ditimpa bila diisi
+func (f *Fixture) merge(o *Fixture) {
+	if o.DefaultPassword != "" {
+		f.DefaultPassword = o.DefaultPassword
+	}
+	if o.Synthetic != nil {
+		f.Synthetic = o.Synthetic
+	}
+	if o.Achievements != nil {
+		f.Achievements = o.Achievements
+	}
+
+	f.Roles = mergeBy(f.Roles, o.Roles, func(r RoleFixture) string { return r.Name })
+	f.Users = mergeBy(f.Users, o.Users, func(u UserFixture) string { return u.Username })
+	f.Lecturers = mergeBy(f.Lecturers, o.Lecturers, func(l LecturerFixture) string { return l.Username })
+	f.Students = mergeBy(f.Students, o.Students, func(s StudentFixture) string { return s.Username })
+	f.Workflows = mergeBy(f.Workflows, o.Workflows, func(w WorkflowFixture) string { return w.Name })
+	f.Faculties = mergeBy(f.Faculties, o.Faculties, func(fa FacultyFixture) string { return fa.Code })
+}
+
+func mergeBy[T any](base []T, extra []T, key func(T) string) []T {
+	idx := map[string]int{}
+	for i, v := range base {
+		idx[key(v)] = i
+	}
+	for _, v := range extra {
+		if i, ok := idx[key(v)]; ok {
+			base[i] = v
+			continue
+		}
+		idx[key(v)] = len(base)
+		base = append(base, v)
+	}
+	return base
+}
@@ -0,0 +1,471 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// SeedEnvs adalah nilai APP_ENV yang boleh di-seed tanpa --force. Guard
+// fail closed: APP_ENV kosong atau salah ketik diperlakukan seperti production.
+var SeedEnvs = []string{"development", "dev", "demo", "test"}
+
+// ErrEnvNotAllowed: APP_ENV tidak ada di SeedEnvs dan tidak dipaksa (--force)
+var ErrEnvNotAllowed = errors.New("seed: APP_ENV must be set to " + strings.Join(SeedEnvs, ", ") + " (or pass --force)")
+
+// namespace UUID untuk ID deterministik data sintetis
+var seedNamespace = uuid.MustParse("5f0c7a52-8d0e-4b7e-9a55-0c1f3c6d2a41")
+
+type Options struct {
+	Profile string // nama profil bawaan atau path file fixture
+	AppEnv  string // config.Env.AppEnv
+	Force   bool
+}
+
+// Result menghitung baris yang benar-benar baru dibuat
+type Result struct {
+	Roles        int `json:"roles"`
+	Permissions  int `json:"permissions"`
+	Users        int `json:"users"`
+	Lecturers    int `json:"lecturers"`
+	Students     int `json:"students"`
+	Workflows    int `json:"workflows"`
+	Units        int `json:"units"`
+	Achievements int `json:"achievements"`
+}
+
+type Seeder struct {
+	DB    *sqlx.DB
+	Mongo *mongo.Database
+
+	fixture   *Fixture
+	result    Result
+	hashes    map[string]string
+	roleIDs   map[string]string
+	lecturers map[string]lecturerRef // key: lecturer_id
+	students  []studentRef
+}
+
+type lecturerRef struct {
+	ID     string
+	UserID string
+}
+
+type studentRef struct {
+	ID        string
+	UserID    string
+	StudentID string
+	Advisor   *lecturerRef
+}
+
+func Run(ctx context.Context, db *sqlx.DB, mongoDB *mongo.Database, opts Options) (*Result, error) {
+	if !opts.Force && !seedAllowed(opts.AppEnv) {
+		return nil, fmt.Errorf("%w; got %q", ErrEnvNotAllowed, opts.AppEnv)
+	}
+
+	fx, err := Load(opts.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Seeder{
+		DB:        db,
+		Mongo:     mongoDB,
+		fixture:   fx,
+		hashes:    map[string]string{},
+		roleIDs:   map[string]string{},
+		lecturers: map[string]lecturerRef{},
+	}
+
+	if err := s.seedPostgres(ctx); err != nil {
+		return &s.result, err
+	}
+	if fx.Achievements != nil && fx.Achievements.PerStudent > 0 {
+		if err := s.seedAchievements(ctx, *fx.Achievements); err != nil {
+			return &s.result, err
+		}
+	}
+	return &s.result, nil
+}
+
+func seedAllowed(appEnv string) bool {
+	for _, env := range SeedEnvs {
+		if strings.EqualFold(strings.TrimSpace(appEnv), env) {
+			return true
+		}
+	}
+	return false
+}
+
+// seedPostgres: role, permission, user, dosen, mahasiswa dan workflow
+// dalam satu transaksi, jadi fixture yang salah tidak meninggalkan separuh data
+func (s *Seeder) seedPostgres(ctx context.Context) error {
+	tx, err := s.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, r := range s.fixture.Roles {
+		if err := s.seedRole(tx, r); err != nil {
+			return fmt.Errorf("seed role %s: %w", r.Name, err)
+		}
+	}
+
+	for _, u := range s.fixture.Users {
+		if _, err := s.seedUser(tx, u); err != nil {
+			return fmt.Errorf("seed user %s: %w", u.Username, err)
+		}
+	}
+
+	for _, l := range s.fixture.Lecturers {
+		if err := s.seedLecturer(tx, l); err != nil {
+			return fmt.Errorf("seed lecturer %s: %w", l.Username, err)
+		}
+	}
+
+	students := s.fixture.Students
+	if s.fixture.Synthetic != nil {
+		students = append(students, s.syntheticStudents(*s.fixture.Synthetic)...)
+	}
+	for _, st := range students {
+		if err := s.seedStudent(tx, st); err != nil {
+			return fmt.Errorf("seed student %s: %w", st.Username, err)
+		}
+	}
+
+	for _, w := range s.fixture.Workflows {
+		if err := s.seedWorkflow(tx, w); err != nil {
+			return fmt.Errorf("seed workflow %s: %w", w.Name, err)
+		}
+	}
+
+	for _, f := range s.fixture.Faculties {
+		if err := s.seedFaculty(tx, f); err != nil {
+			return fmt.Errorf("seed faculty %s: %w", f.Code, err)
+		}
+	}
+	if len(s.fixture.Faculties) > 0 {
+		if err := linkUnits(tx); err != nil {
+			return fmt.Errorf("link units: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// =====================
+// ROLES & PERMISSIONS
+// =====================
+func (s *Seeder) seedRole(tx *sqlx.Tx, r RoleFixture) error {
+	// role yang sudah ada hanya bisa naik menjadi system, deskripsi tidak ditimpa
+	var row struct {
+		ID       string `db:"id"`
+		Inserted bool   `db:"inserted"`
+	}
+	err := tx.Get(&row, `
+		INSERT INTO roles (id, name, description, is_system)
+		VALUES (gen_random_uuid(), $1, $2, $3)
+		ON CONFLICT (name) DO UPDATE SET is_system = roles.is_system OR EXCLUDED.is_system
+		RETURNING id, (xmax = 0) AS inserted
+	`, r.Name, r.Description, r.System)
+	if err != nil {
+		return err
+	}
+	if row.Inserted {
+		s.result.Roles++
+	}
+	roleID := row.ID
+	s.roleIDs[r.Name] = roleID
+
+	for _, key := range r.Permissions {
+		resource, action, ok := strings.Cut(key, ":")
+		if !ok {
+			return fmt.Errorf("invalid permission %q, want resource:action", key)
+		}
+
+		var permID string
+		err := tx.Get(&permID, `
+			INSERT INTO permissions (id, resource, action)
+			VALUES (gen_random_uuid(), $1, $2)
+			ON CONFLICT (resource, action) DO NOTHING
+			RETURNING id
+		`, resource, action)
+		if err == nil {
+			s.result.Permissions++
+		} else if isNoRows(err) {
+			err = tx.Get(&permID, `SELECT id FROM permissions WHERE resource = $1 AND action = $2`, resource, action)
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO role_permissions (id, role_id, permission_id)
+			VALUES (gen_random_uuid(), $1, $2)
+			ON CONFLICT (role_id, permission_id) DO NOTHING
+		`, roleID, permID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// =====================
+// USERS
+// =====================
+
+// seedUser membuat user bila username belum ada; user yang sudah ada
+// tidak diubah (password / profil yang diganti pengguna tetap)
+func (s *Seeder) seedUser(tx *sqlx.Tx, u UserFixture) (string, error) {
+	roleID, ok := s.roleIDs[u.Role]
+	if !ok {
+		if err := tx.Get(&roleID, `SELECT id FROM roles WHERE name = $1`, u.Role); err != nil {
+			return "", fmt.Errorf("unknown role %q", u.Role)
+		}
+		s.roleIDs[u.Role] = roleID
+	}
+
+	var userID string
+	err := tx.Get(&userID, `SELECT id FROM users WHERE username = $1`, u.Username)
+	if err == nil {
+		return userID, nil
+	}
+	if !isNoRows(err) {
+		return "", err
+	}
+
+	hash, err := s.passwordHash(u.Password)
+	if err != nil {
+		return "", err
+	}
+
+	err = tx.Get(&userID, `
+		INSERT INTO users (id, username, email, password_hash, full_name, role_id, is_active)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, true)
+		RETURNING id
+	`, u.Username, u.Email, hash, u.FullName, roleID)
+	if err != nil {
+		return "", err
+	}
+	s.result.Users++
+	return userID, nil
+}
+
+// passwordHash: password per user > SEED_PASSWORD > default_password profil
+func (s *Seeder) passwordHash(password string) (string, error) {
+	if password == "" {
+		password = os.Getenv("SEED_PASSWORD")
+	}
+	if password == "" {
+		password = s.fixture.DefaultPassword
+	}
+	if password == "" {
+		return "", errors.New("no password: set password, SEED_PASSWORD or default_password")
+	}
+
+	if h, ok := s.hashes[password]; ok {
+		return h, nil
+	}
+	h, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	s.hashes[password] = string(h)
+	return string(h), nil
+}
+
+func (s *Seeder) seedLecturer(tx *sqlx.Tx, l LecturerFixture) error {
+	if l.Role == "" {
+		l.Role = "lecturer"
+	}
+	userID, err := s.seedUser(tx, l.UserFixture)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO lecturers (id, user_id, lecturer_id, department)
+		VALUES (gen_random_uuid(), $1, $2, $3)
+		ON CONFLICT (user_id) DO NOTHING
+	`, userID, l.LecturerID, l.Department)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		s.result.Lecturers++
+	}
+
+	var ref lecturerRef
+	if err := tx.Get(&ref.ID, `SELECT id FROM lecturers WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	ref.UserID = userID
+	s.lecturers[l.LecturerID] = ref
+	return nil
+}
+
+func (s *Seeder) seedStudent(tx *sqlx.Tx, st StudentFixture) error {
+	if st.Role == "" {
+		st.Role = "student"
+	}
+	userID, err := s.seedUser(tx, st.UserFixture)
+	if err != nil {
+		return err
+	}
+
+	var advisor *lecturerRef
+	if st.Advisor != "" {
+		l, ok := s.lecturers[st.Advisor]
+		if !ok {
+			return fmt.Errorf("unknown advisor %q", st.Advisor)
+		}
+		advisor = &l
+	}
+
+	var advisorID *string
+	if advisor != nil {
+		advisorID = &advisor.ID
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO students (id, user_id, student_id, program_study, academic_year, advisor_id)
+		VALUES (gen_random_uuid(), $1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO NOTHING
+	`, userID, st.StudentID, st.ProgramStudy, st.AcademicYear, advisorID)
+	if err != nil {
+		return err
+	}
+	created, _ := res.RowsAffected()
+	if created > 0 {
+		s.result.Students++
+	}
+
+	ref := studentRef{UserID: userID, StudentID: st.StudentID, Advisor: advisor}
+	if err := tx.Get(&ref.ID, `SELECT id FROM students WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	// riwayat dosen wali dimulai dari advisor fixture
+	if created > 0 && advisorID != nil {
+		_, err := tx.Exec(`
+			INSERT INTO advisor_assignments (id, student_id, advisor_id, method, effective_from)
+			VALUES (gen_random_uuid(), $1, $2, 'initial', NOW())
+		`, ref.ID, *advisorID)
+		if err != nil {
+			return err
+		}
+	}
+	s.students = append(s.students, ref)
+	return nil
+}
+
+// =====================
+// WORKFLOWS
+// =====================
+func (s *Seeder) seedWorkflow(tx *sqlx.Tx, w WorkflowFixture) error {
+	res, err := tx.Exec(`
+		INSERT INTO achievement_workflows (id, name, category, level, stages, created_at)
+		SELECT gen_random_uuid(), $1, $2, $3, $4, NOW()
+		WHERE NOT EXISTS (SELECT 1 FROM achievement_workflows WHERE name = $1)
+	`, w.Name, w.Category, w.Level, stringArray(w.Stages))
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		s.result.Workflows++
+	}
+	return nil
+}
+
+// =====================
+// ORGANISASI
+// =====================
+
+// upsertUnit membuat unit bila kodenya belum ada dan mengembalikan ID-nya
+func (s *Seeder) upsertUnit(tx *sqlx.Tx, insert string, lookup string, args ...interface{}) (string, error) {
+	var id string
+	err := tx.Get(&id, insert, args...)
+	if err == nil {
+		s.result.Units++
+		return id, nil
+	}
+	if !isNoRows(err) {
+		return "", err
+	}
+	err = tx.Get(&id, lookup, args[0])
+	return id, err
+}
+
+func (s *Seeder) seedFaculty(tx *sqlx.Tx, f FacultyFixture) error {
+	facultyID, err := s.upsertUnit(tx, `
+		INSERT INTO faculties (id, code, name) VALUES (gen_random_uuid(), $1, $2)
+		ON CONFLICT (code) DO NOTHING
+		RETURNING id
+	`, `SELECT id FROM faculties WHERE code = $1`, f.Code, f.Name)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range f.Departments {
+		var head *string
+		if d.Head != "" {
+			var userID string
+			if err := tx.Get(&userID, `SELECT id FROM users WHERE username = $1`, d.Head); err != nil {
+				return fmt.Errorf("department %s: unknown head %q", d.Code, d.Head)
+			}
+			head = &userID
+		}
+
+		departmentID, err := s.upsertUnit(tx, `
+			INSERT INTO departments (id, code, name, faculty_id, head_user_id)
+			VALUES (gen_random_uuid(), $1, $2, $3, $4)
+			ON CONFLICT (code) DO NOTHING
+			RETURNING id
+		`, `SELECT id FROM departments WHERE code = $1`, d.Code, d.Name, facultyID, head)
+		if err != nil {
+			return err
+		}
+
+		for _, sp := range d.StudyPrograms {
+			_, err := s.upsertUnit(tx, `
+				INSERT INTO study_programs (id, code, name, degree, department_id)
+				VALUES (gen_random_uuid(), $1, $2, $3, $4)
+				ON CONFLICT (code) DO NOTHING
+				RETURNING id
+			`, `SELECT id FROM study_programs WHERE code = $1`, sp.Code, sp.Name, sp.Degree, departmentID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// linkUnits menautkan dosen & mahasiswa yang belum punya unit berdasarkan
+// nama departemen / program studi (tidak peka huruf besar)
+func linkUnits(tx *sqlx.Tx) error {
+	_, err := tx.Exec(`
+		UPDATE lecturers l SET department_id = d.id
+		FROM departments d
+		WHERE l.department_id IS NULL AND LOWER(l.department) = LOWER(d.name)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE students s SET study_program_id = sp.id
+		FROM study_programs sp
+		WHERE s.study_program_id IS NULL AND LOWER(s.program_study) = LOWER(sp.name)
+	`)
+	return err
+}
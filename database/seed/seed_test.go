@@ -0,0 +1,25 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// guard fail closed: hanya APP_ENV non-production yang eksplisit
+func TestRunRefusesUnlistedEnv(t *testing.T) {
+	for _, env := range []string{"", "production", "prod", "staging", "develop"} {
+		_, err := Run(context.Background(), nil, nil, Options{Profile: "dev", AppEnv: env})
+		if !errors.Is(err, ErrEnvNotAllowed) {
+			t.Errorf("APP_ENV=%q: err = %v, want ErrEnvNotAllowed", env, err)
+		}
+	}
+}
+
+func TestSeedAllowed(t *testing.T) {
+	for _, env := range []string{"development", "dev", "Demo", " test "} {
+		if !seedAllowed(env) {
+			t.Errorf("seedAllowed(%q) = false", env)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package seed
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"project_uas/app/model"
+)
+
+// Data sintetis dibuat dari RNG yang di-seed per kunci (bukan satu RNG
+// global), jadi menambah mahasiswa tidak mengubah data yang sudah ada dan
+// menjalankan ulang menghasilkan ID yang sama.
+
+var (
+	firstNames = []string{"Andi", "Budi", "Citra", "Dewi", "Eka", "Fajar", "Gita", "Hendra", "Intan", "Joko",
+		"Kartika", "Lestari", "Made", "Nanda", "Oktavia", "Putri", "Rizky", "Sari", "Taufik", "Wulan", "Yoga", "Zahra"}
+	lastNames = []string{"Pratama", "Saputra", "Wijaya", "Nugroho", "Lestari", "Hidayat", "Kusuma", "Santoso",
+		"Permata", "Siregar", "Hasibuan", "Wibowo", "Utami", "Setiawan", "Rahmawati", "Purnomo"}
+
+	categories = []string{"kompetisi", "publikasi", "seminar"}
+	levels     = []string{"lokal", "nasional", "internasional"}
+	placements = []string{"Juara 1", "Juara 2", "Juara 3", "Finalis", "Harapan 1"}
+	fields     = []string{"Pemrograman", "Keamanan Siber", "UI/UX", "Data Science", "IoT", "Robotika",
+		"Karya Tulis Ilmiah", "Business Plan", "Game Development", "Kecerdasan Buatan"}
+	organizers = []string{"Kemendikbudristek", "Puspresnas", "Universitas Indonesia", "ITB", "UGM", "ITS",
+		"Google Developer Groups", "IEEE Indonesia Section", "Telkom Indonesia", "Bank Indonesia"}
+	venues   = []string{"Jakarta", "Bandung", "Yogyakarta", "Surabaya", "Malang", "Denpasar", "Daring", "Kuala Lumpur", "Singapura"}
+	journals = []string{"Jurnal Teknologi Informasi", "Jurnal Ilmu Komputer", "IEEE Access", "Procedia Computer Science"}
+)
+
+// bobot status prestasi sintetis
+var statusWeights = []struct {
+	Status string
+	Weight int
+}{
+	{"draft", 20},
+	{"submitted", 25},
+	{"verified", 40},
+	{"rejected", 10},
+	{"revision_requested", 5},
+}
+
+func rngFor(seed int64, key string) *rand.Rand {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d:%s", seed, key)
+	return rand.New(rand.NewSource(int64(h.Sum64())))
+}
+
+func pick(r *rand.Rand, list []string) string {
+	return list[r.Intn(len(list))]
+}
+
+// syntheticStudents: username demo_<angkatan><nnn>, advisor dibagi rata
+func (s *Seeder) syntheticStudents(cfg SyntheticStudents) []StudentFixture {
+	if len(cfg.ProgramStudies) == 0 || len(cfg.AcademicYears) == 0 {
+		return nil
+	}
+
+	advisors := make([]string, 0, len(s.lecturers))
+	for id := range s.lecturers {
+		advisors = append(advisors, id)
+	}
+	sort.Strings(advisors)
+
+	var seed int64
+	if s.fixture.Achievements != nil {
+		seed = s.fixture.Achievements.RandomSeed
+	}
+
+	list := make([]StudentFixture, 0, cfg.Count)
+	for i := 0; i < cfg.Count; i++ {
+		year := cfg.AcademicYears[i%len(cfg.AcademicYears)]
+		nim := fmt.Sprintf("%s9%03d", year, i+1)
+		r := rngFor(seed, "student:"+nim)
+
+		st := StudentFixture{
+			StudentID:    nim,
+			ProgramStudy: pick(r, cfg.ProgramStudies),
+			AcademicYear: year,
+		}
+		st.Username = "demo_" + nim
+		st.Email = st.Username + "@mail.com"
+		st.FullName = pick(r, firstNames) + " " + pick(r, lastNames)
+		if len(advisors) > 0 {
+			st.Advisor = advisors[i%len(advisors)]
+		}
+		list = append(list, st)
+	}
+	return list
+}
+
+// =====================
+// ACHIEVEMENTS (Postgres + Mongo)
+// =====================
+
+// seedAchievements membuat PerStudent prestasi per mahasiswa. Dokumen Mongo
+// di-upsert lebih dulu dengan ID deterministik, lalu reference Postgres;
+// bila proses terhenti di tengah, menjalankan ulang akan melengkapinya.
+func (s *Seeder) seedAchievements(ctx context.Context, plan AchievementPlan) error {
+	coll := s.Mongo.Collection("achievements")
+
+	for _, st := range s.students {
+		for i := 0; i < plan.PerStudent; i++ {
+			key := fmt.Sprintf("achievement:%s:%d", st.StudentID, i)
+			r := rngFor(plan.RandomSeed, key)
+
+			doc := syntheticAchievement(r, st.UserID)
+			doc.ID = objectIDFor(key)
+			status := pickStatus(r)
+
+			_, err := coll.UpdateOne(ctx,
+				bson.M{"_id": doc.ID},
+				bson.M{"$setOnInsert": doc},
+				options.Update().SetUpsert(true),
+			)
+			if err != nil {
+				return fmt.Errorf("seed achievement %s (mongo): %w", key, err)
+			}
+
+			created, err := s.insertReference(ctx, key, st, doc, status)
+			if err != nil {
+				return fmt.Errorf("seed achievement %s (postgres): %w", key, err)
+			}
+			if created {
+				s.result.Achievements++
+			}
+		}
+	}
+	return nil
+}
+
+func syntheticAchievement(r *rand.Rand, createdBy string) model.Achievement {
+	category := pick(r, categories)
+	level := levels[weighted(r, []int{45, 40, 15})]
+	organizer := pick(r, organizers)
+	field := pick(r, fields)
+
+	var title, description string
+	switch category {
+	case "publikasi":
+		journal := pick(r, journals)
+		title = fmt.Sprintf("Publikasi %s: Studi %s", journal, field)
+		description = fmt.Sprintf("Artikel bidang %s diterbitkan di %s.", field, journal)
+		organizer = journal
+	case "seminar":
+		title = fmt.Sprintf("Pemakalah Seminar %s %s", field, level)
+		description = fmt.Sprintf("Mempresentasikan makalah %s pada seminar yang diselenggarakan %s.", field, organizer)
+	default:
+		title = fmt.Sprintf("%s Lomba %s Tingkat %s", pick(r, placements), field, level)
+		description = fmt.Sprintf("Kompetisi %s tingkat %s yang diselenggarakan %s.", field, level, organizer)
+	}
+
+	event := time.Now().AddDate(0, 0, -r.Intn(730)).Truncate(24 * time.Hour)
+	created := event.AddDate(0, 0, 1+r.Intn(30))
+	if created.After(time.Now()) {
+		created = time.Now()
+	}
+
+	return model.Achievement{
+		Title:       title,
+		Description: description,
+		Category:    category,
+		Level:       level,
+		Organizer:   organizer,
+		Location:    pick(r, venues),
+		EventDate:   &event,
+		Score:       []int{10, 20, 40}[indexOf(levels, level)] + r.Intn(11),
+		CreatedBy:   createdBy,
+		CreatedAt:   created,
+		UpdatedAt:   created,
+	}
+}
+
+func pickStatus(r *rand.Rand) string {
+	weights := make([]int, len(statusWeights))
+	for i, w := range statusWeights {
+		weights[i] = w.Weight
+	}
+	return statusWeights[weighted(r, weights)].Status
+}
+
+func weighted(r *rand.Rand, weights []int) int {
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+	n := r.Intn(total)
+	for i, w := range weights {
+		if n < w {
+			return i
+		}
+		n -= w
+	}
+	return len(weights) - 1
+}
+
+func indexOf(list []string, v string) int {
+	for i, x := range list {
+		if x == v {
+			return i
+		}
+	}
+	return 0
+}
+
+// insertReference menulis reference + riwayat status dalam satu transaksi.
+// false bila reference sudah ada (seed pernah dijalankan).
+func (s *Seeder) insertReference(ctx context.Context, key string, st studentRef, doc model.Achievement, status string) (bool, error) {
+	tx, err := s.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	refID := uuid.NewSHA1(seedNamespace, []byte(key)).String()
+	created := doc.CreatedAt
+
+	var submittedAt, verifiedAt *time.Time
+	var verifiedBy, note *string
+	if status != "draft" {
+		t := created.Add(2 * time.Hour)
+		submittedAt = &t
+	}
+	if (status == "verified" || status == "rejected") && st.Advisor != nil {
+		t := created.Add(72 * time.Hour)
+		verifiedAt = &t
+		verifiedBy = &st.Advisor.UserID
+	}
+	if status == "rejected" {
+		n := "Bukti pendukung tidak sesuai dengan data prestasi."
+		note = &n
+	}
+
+	var stages pq.StringArray
+	if status != "draft" {
+		stages = pq.StringArray{"advisor"}
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO achievement_references
+		(id, student_id, mongo_achievement_id, status, submitted_at, verified_at, verified_by,
+		 rejection_note, workflow_stages, current_stage, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, 0, $10, $10)
+		ON CONFLICT (id) DO NOTHING
+	`, refID, st.ID, doc.ID.Hex(), status, submittedAt, verifiedAt, verifiedBy, note, stages, created)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	// riwayat: draft → submitted → (status akhir)
+	history := []historyRow{}
+	if submittedAt != nil {
+		history = append(history, historyRow{"draft", "submitted", st.UserID, *submittedAt, nil})
+	}
+	if status != "draft" && status != "submitted" && st.Advisor != nil {
+		history = append(history, historyRow{"submitted", status, st.Advisor.UserID, created.Add(72 * time.Hour), note})
+	}
+
+	for i, h := range history {
+		_, err := tx.Exec(`
+			INSERT INTO achievement_history
+			(id, achievement_ref_id, old_status, new_status, stage, changed_by, note, changed_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (id) DO NOTHING
+		`, uuid.NewSHA1(seedNamespace, []byte(fmt.Sprintf("%s:history:%d", key, i))).String(),
+			refID, h.From, h.To, stageFor(h.To), h.By, h.Note, h.At)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
+
+type historyRow struct {
+	From, To string
+	By       string
+	At       time.Time
+	Note     *string
+}
+
+func stageFor(to string) *string {
+	if to == "submitted" {
+		return nil
+	}
+	s := "advisor"
+	return &s
+}
+
+// objectIDFor: 12 byte pertama SHA-1 kunci, stabil antar run
+func objectIDFor(key string) primitive.ObjectID {
+	var oid primitive.ObjectID
+	sum := uuid.NewSHA1(seedNamespace, []byte(key))
+	copy(oid[:], sum[:12])
+	return oid
+}
+
+func stringArray(list []string) pq.StringArray {
+	return pq.StringArray(list)
+}
+
+func isNoRows(err error) bool {
+	return errors.Is(err, sql.ErrNoRows)
+}
@@ -13,27 +13,38 @@ type AccessTokenClaims struct {
 	Username    string   `json:"username"`
 	Role        string   `json:"role"`
 	Permissions []string `json:"permissions"`
+	SessionID   string   `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// RefreshTokenClaims membawa family_id supaya seluruh rantai rotasi
+// bisa dicabut sekaligus saat refresh token lama dipakai ulang.
+// ID token (jti) disimpan di RegisteredClaims.ID.
 type RefreshTokenClaims struct {
-	UserID string `json:"user_id"`
-	Role   string `json:"role"`
+	UserID   string `json:"user_id"`
+	Role     string `json:"role"`
+	FamilyID string `json:"family_id"`
 	jwt.RegisteredClaims
 }
 
+const (
+	AccessTokenTTL  = 1 * time.Hour
+	RefreshTokenTTL = 7 * 24 * time.Hour
+)
+
 // ==========================
 //  ACCESS TOKEN GENERATE
 // ==========================
-func GenerateAccessToken(userID string, username string, role string, permissions []string) (string, error) {
+func GenerateAccessToken(userID string, username string, role string, permissions []string, sessionID string) (string, error) {
 
 	claims := AccessTokenClaims{
 		UserID:      userID,
 		Username:    username,
 		Role:        role,
 		Permissions: permissions,
+		SessionID:   sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(1 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
@@ -47,13 +58,15 @@ func GenerateAccessToken(userID string, username string, role string, permission
 // ==========================
 //  REFRESH TOKEN GENERATE
 // ==========================
-func GenerateRefreshToken(userID string, role string) (string, error) {
+func GenerateRefreshToken(userID string, role string, familyID string, tokenID string, expiresAt time.Time) (string, error) {
 
 	claims := RefreshTokenClaims{
-		UserID: userID,
-		Role:   role,
+		UserID:   userID,
+		Role:     role,
+		FamilyID: familyID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)),
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
@@ -107,5 +120,10 @@ func VerifyRefreshToken(tokenString string) (*RefreshTokenClaims, error) {
 		return nil, errors.New("invalid token")
 	}
 
+	// token lama (sebelum rotasi) tidak punya jti / family → tolak
+	if claims.ID == "" || claims.FamilyID == "" {
+		return nil, errors.New("invalid token")
+	}
+
 	return claims, nil
 }
@@ -0,0 +1,24 @@
+package helper
+
+import (
+	"project_uas/app/repository"
+	"project_uas/database"
+)
+
+// IsSessionActive mengecek apakah session milik token masih aktif,
+// sekaligus memperbarui last_seen_at session tersebut.
+func IsSessionActive(sessionID string, ip string) bool {
+	repo := repository.NewSessionRepo(database.PostgresDB)
+
+	active, err := repo.IsActive(sessionID)
+	if err != nil {
+		// sama seperti blacklist: DB error → BLOCK
+		return false
+	}
+
+	if active {
+		_ = repo.Touch(sessionID, ip)
+	}
+
+	return active
+}
@@ -0,0 +1,36 @@
+package helper
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strconv"
+	"time"
+)
+
+// ==========================
+//  SIGNED DOWNLOAD URL
+// ==========================
+// Tanda tangan HMAC untuk link unduhan lampiran yang bisa dibuka tanpa
+// header Authorization (mis. dari <a href> atau viewer PDF) sampai kedaluwarsa.
+
+func signature(resource string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(os.Getenv("JWT_SECRET")))
+	mac.Write([]byte(resource + "|" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignResource mengembalikan unix expiry & signature untuk resource
+func SignResource(resource string, ttl time.Duration) (int64, string) {
+	expires := time.Now().Add(ttl).Unix()
+	return expires, signature(resource, expires)
+}
+
+// VerifyResourceSignature memeriksa signature & waktu kedaluwarsa
+func VerifyResourceSignature(resource string, expires int64, sig string) bool {
+	if time.Now().Unix() > expires {
+		return false
+	}
+	return hmac.Equal([]byte(signature(resource, expires)), []byte(sig))
+}
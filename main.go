@@ -1,17 +1,33 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/google/uuid"
 	fiberSwagger "github.com/swaggo/fiber-swagger"
 
 	"project_uas/config"
 	"project_uas/database"
 	"project_uas/route"
 
+	"project_uas/app/audit"
+	"project_uas/app/dispatch"
+	"project_uas/app/policy"
+	"project_uas/app/preview"
+	"project_uas/app/saga"
+	"project_uas/app/scan"
+	"project_uas/app/storage"
+	"project_uas/app/rbac"
+	"project_uas/app/realtime"
+	"project_uas/app/reconcile"
 	"project_uas/app/repository"
 	"project_uas/app/service"
+	"project_uas/app/userimport"
 
 	_ "project_uas/docs"
 )
@@ -24,6 +40,25 @@ func main() {
 	// Connect DB
 	database.Connect()
 
+	// Subcommand CLI (mis. `go run . migrate up`, `go run . reconcile --fix`)
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
+	if config.Env.AutoMigrate {
+		migrator, err := database.NewMigrator(database.PostgresDB, database.MongoDB)
+		if err != nil {
+			log.Fatal("FAILED LOAD MIGRATIONS:", err)
+		}
+		ran, err := migrator.Up(context.Background(), 0)
+		if err != nil {
+			log.Fatal("FAILED MIGRATE:", err)
+		}
+		for _, m := range ran {
+			log.Printf("MIGRATED %04d_%s", m.Version, m.Name)
+		}
+	}
+
 	// =====================
 	// INIT REPOSITORIES
 	// =====================
@@ -33,21 +68,117 @@ func main() {
 	userRepo := repository.NewUserRepo(database.PostgresDB)
 	lecturerRepo := repository.NewLecturerRepo(database.PostgresDB)
 	reportRepo := repository.NewReportRepo(database.PostgresDB)
+	sessionRepo := repository.NewSessionRepo(database.PostgresDB)
+	workflowRepo := repository.NewWorkflowRepo(database.PostgresDB)
+	notificationRepo := repository.NewNotificationRepo(database.PostgresDB)
+	outboxRepo := repository.NewOutboxRepo(database.PostgresDB)
+	attachmentScanRepo := repository.NewAttachmentScanRepo(database.PostgresDB)
+	attachmentPreviewRepo := repository.NewAttachmentPreviewRepo(database.PostgresDB)
+	sagaRepo := repository.NewSagaRepo(database.PostgresDB)
+	reconcileRepo := repository.NewReconcileRepo(database.PostgresDB, database.MongoDB)
+	rbacRepo := repository.NewRBACRepo(database.PostgresDB)
+	policyRepo := repository.NewPolicyRepo(database.PostgresDB)
+	orgRepo := repository.NewOrgRepo(database.PostgresDB)
+	userImportRepo := repository.NewUserImportRepo(database.PostgresDB)
+	advisorRepo := repository.NewAdvisorRepo(database.PostgresDB)
+	auditRepo := repository.NewAuditRepo(database.PostgresDB)
 
 	// =====================
 	// INIT SERVICES
 	// =====================
-	authService := service.NewAuthService(authRepo)
-	achievementService := service.NewAchievementService(achievementRepo,studentRepo,)
-	studentService := service.NewStudentService(studentRepo)
+	authService := service.NewAuthService(authRepo, sessionRepo)
+	workflowService := service.NewWorkflowService(workflowRepo)
+	eventHub := realtime.NewHub()
+	eventPublisher := realtime.NewPublisher(database.PostgresDB)
+	templates, err := dispatch.LoadTemplates(config.Env.DefaultLocale)
+	if err != nil {
+		log.Fatal("FAILED LOAD NOTIFICATION TEMPLATES:", err)
+	}
+
+	channels := []dispatch.Channel{}
+	if config.Env.SMTPHost != "" {
+		channels = append(channels, &dispatch.SMTPChannel{
+			Host:     config.Env.SMTPHost,
+			Port:     config.Env.SMTPPort,
+			Username: config.Env.SMTPUsername,
+			Password: config.Env.SMTPPassword,
+			From:     config.Env.SMTPFrom,
+		})
+	}
+	dispatcher := dispatch.NewDispatcher(outboxRepo, channels...)
+
+	notificationService := service.NewNotificationService(notificationRepo, eventPublisher, eventHub, templates, dispatcher)
+	attachmentStorage, err := storage.New(config.Env)
+	if err != nil {
+		log.Fatal("FAILED INIT ATTACHMENT STORAGE:", err)
+	}
+
+	coordinator := saga.NewCoordinator(sagaRepo, achievementRepo)
+	achievementService := service.NewAchievementService(achievementRepo,studentRepo,workflowService,notificationService,attachmentStorage,coordinator)
+	studentService := service.NewStudentService(studentRepo, achievementRepo, advisorRepo, notificationService)
 	userService := service.NewUserService(userRepo) // ✅ WAJIB
-	lecturerService := service.NewLecturerService(lecturerRepo)
+	lecturerService := service.NewLecturerService(lecturerRepo, advisorRepo)
 	reportService := service.NewReportService(reportRepo)
+	sessionService := service.NewSessionService(sessionRepo)
+	reconcileService := service.NewReconcileService(reconcile.New(reconcileRepo, achievementRepo, attachmentStorage))
+	rbacService := service.NewRBACService(rbacRepo)
+	orgService := service.NewOrgService(orgRepo)
+	userImportService := service.NewUserImportService(userimport.New(userImportRepo))
+	auditService := service.NewAuditService(auditRepo)
+
+	// =====================
+	// REALTIME (LISTEN/NOTIFY → SSE)
+	// =====================
+	go realtime.Listen(database.PostgresDSN(), eventHub)
+
+	// =====================
+	// RBAC CACHE (role & permission terkini, invalidasi via NOTIFY)
+	// =====================
+	rbac.Default = rbac.NewCache(rbacRepo, 5*time.Minute)
+	go rbac.Listen(database.PostgresDSN(), rbac.Default)
+
+	// =====================
+	// POLICY (subject, action, resource) untuk semua route
+	// =====================
+	policy.Default = policy.NewEngine(policy.Rules, policyRepo)
+
+	// =====================
+	// AUDIT LOG (setiap request yang mengubah data)
+	// =====================
+	audit.Default = audit.NewLogger(auditRepo)
+
+	// =====================
+	// NOTIFICATION OUTBOX WORKER
+	// =====================
+	go dispatcher.Run(context.Background(), 10*time.Second)
+
+	// =====================
+	// ATTACHMENT VIRUS SCAN WORKER
+	// =====================
+	scanner := scan.NewScanner(attachmentScanRepo, attachmentStorage, &scan.Clamd{Addr: config.Env.ClamdAddr})
+	go scanner.Run(context.Background(), 5*time.Second)
+
+	// =====================
+	// ATTACHMENT PREVIEW WORKER
+	// =====================
+	previewWorker := preview.NewWorker(attachmentPreviewRepo, attachmentStorage)
+	go previewWorker.Run(context.Background(), 5*time.Second)
+
+	// =====================
+	// SAGA RECOVERY (Postgres ↔ Mongo)
+	// =====================
+	go coordinator.Run(context.Background(), 30*time.Second)
 
 	// =====================
 	// INIT APP
 	// =====================
-	app := fiber.New()
+	app := fiber.New(fiber.Config{
+		// sisakan 1 MB untuk field multipart selain file
+		BodyLimit: int(config.Env.AttachmentMaxSize) + 1<<20,
+	})
+
+	// X-Request-ID: dipakai ulang dari client atau dibuat baru (jejak audit)
+	app.Use(requestid.New(requestid.Config{Generator: uuid.NewString}))
 
 	// Swagger
 	app.Get("/swagger/*", fiberSwagger.WrapHandler)
@@ -61,6 +192,14 @@ func main() {
 		userService,        // ✅ DITAMBAHKAN
 		lecturerService,
 		reportService,
+		sessionService,
+		workflowService,
+		notificationService,
+		reconcileService,
+		rbacService,
+		orgService,
+		userImportService,
+		auditService,
 	)
 
 	// Debug routes
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+
+	"project_uas/app/audit"
+)
+
+// Audit mencatat setiap request yang mengubah data (POST, PUT, PATCH,
+// DELETE) ke audit.Default setelah handler selesai, termasuk yang ditolak
+// atau gagal. Ditulis sinkron karena data request (path, header) hanya
+// valid selama request berjalan.
+func Audit() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if audit.Default == nil || !mutating(c.Method()) {
+			return c.Next()
+		}
+
+		err := c.Next()
+
+		// error yang dikembalikan handler baru menjadi status di ErrorHandler
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			var fe *fiber.Error
+			if errors.As(err, &fe) {
+				status = fe.Code
+			}
+		}
+
+		if werr := audit.Default.Write(c, status); werr != nil {
+			log.Println("failed write audit log:", werr)
+		}
+		return err
+	}
+}
+
+func mutating(method string) bool {
+	switch method {
+	case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+		return true
+	}
+	return false
+}
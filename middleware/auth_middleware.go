@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"project_uas/app/rbac"
 	"project_uas/helper"
 )
 
@@ -22,27 +23,68 @@ func AuthMiddleware() fiber.Handler {
 				JSON(fiber.Map{"error": "invalid Authorization header"})
 		}
 
-		token := parts[1]
+		return authenticate(c, parts[1])
+	}
+}
 
-		// ✅ STEP 1: CEK BLACKLIST (via helper)
-		if helper.IsTokenBlacklisted(token) {
+// StreamAuthMiddleware sama dengan AuthMiddleware, tetapi juga menerima
+// token dari query ?access_token= karena EventSource di browser tidak
+// bisa mengirim header Authorization. Hanya dipakai untuk endpoint stream.
+func StreamAuthMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+
+		parts := strings.Split(c.Get("Authorization"), " ")
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			return authenticate(c, parts[1])
+		}
+
+		token := c.Query("access_token")
+		if token == "" {
 			return c.Status(fiber.StatusUnauthorized).
-				JSON(fiber.Map{"error": "token has been revoked"})
+				JSON(fiber.Map{"error": "missing access token"})
 		}
 
-		// ✅ STEP 2: CEK JWT
-		claims, err := helper.VerifyAccessToken(token)
+		return authenticate(c, token)
+	}
+}
+
+func authenticate(c *fiber.Ctx, token string) error {
+
+	// ✅ STEP 1: CEK BLACKLIST (via helper)
+	if helper.IsTokenBlacklisted(token) {
+		return c.Status(fiber.StatusUnauthorized).
+			JSON(fiber.Map{"error": "token has been revoked"})
+	}
+
+	// ✅ STEP 2: CEK JWT
+	claims, err := helper.VerifyAccessToken(token)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).
+			JSON(fiber.Map{"error": "invalid or expired token"})
+	}
+
+	// ✅ STEP 3: CEK SESSION (remote sign-out)
+	if claims.SessionID != "" && !helper.IsSessionActive(claims.SessionID, c.IP()) {
+		return c.Status(fiber.StatusUnauthorized).
+			JSON(fiber.Map{"error": "session has been signed out"})
+	}
+
+	// ✅ STEP 4: ROLE & PERMISSION TERKINI (cache RBAC, bukan klaim token)
+	role, permissions := claims.Role, claims.Permissions
+	if rbac.Default != nil {
+		role, permissions, err = rbac.Default.Access(claims.UserID)
 		if err != nil {
 			return c.Status(fiber.StatusUnauthorized).
-				JSON(fiber.Map{"error": "invalid or expired token"})
+				JSON(fiber.Map{"error": "cannot load user permissions"})
 		}
+	}
 
-		// ✅ SET CONTEXT
-		c.Locals("user_id", claims.UserID)
-		c.Locals("username", claims.Username)
-		c.Locals("role", claims.Role)
-		c.Locals("permissions", claims.Permissions)
+	// ✅ SET CONTEXT
+	c.Locals("user_id", claims.UserID)
+	c.Locals("session_id", claims.SessionID)
+	c.Locals("username", claims.Username)
+	c.Locals("role", role)
+	c.Locals("permissions", permissions)
 
-		return c.Next()
-	}
+	return c.Next()
 }
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"project_uas/app/audit"
+	"project_uas/app/policy"
+)
+
+// On menunjuk resource dari path param, mis. On(policy.KindStudent, "id")
+func On(kind string, param string) func(c *fiber.Ctx) policy.Resource {
+	return func(c *fiber.Ctx) policy.Resource {
+		return policy.Resource{Kind: kind, ID: c.Params(param)}
+	}
+}
+
+// Authorize mengevaluasi action lewat policy.Default. Action wajib ada di
+// policy.Rules; route dengan action yang tidak terdaftar gagal saat startup.
+func Authorize(action string, resource ...func(c *fiber.Ctx) policy.Resource) fiber.Handler {
+	if _, ok := policy.Rules[action]; !ok {
+		log.Fatalf("policy: route uses undeclared action %q", action)
+	}
+
+	return func(c *fiber.Ctx) error {
+		engine := policy.Default
+		if engine == nil {
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "policy engine not configured",
+			})
+		}
+
+		res := policy.Resource{}
+		if len(resource) > 0 {
+			res = resource[0](c)
+		}
+		audit.SetAction(c, action, res.Kind, res.ID)
+
+		decision, err := engine.Check(policy.SubjectFrom(c), action, res)
+		if errors.Is(err, policy.ErrNotFound) {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": res.Kind + " not found",
+			})
+		}
+		if err != nil {
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "failed evaluate policy",
+			})
+		}
+		if !decision.Allowed {
+			return c.Status(http.StatusForbidden).JSON(fiber.Map{
+				"error": decision.Reason,
+			})
+		}
+		if decision.Relation == policy.RelUnitAdmin {
+			c.Locals("unit_scope", decision.Scope)
+		}
+
+		return c.Next()
+	}
+}
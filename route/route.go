@@ -3,6 +3,7 @@ package route
 import (
 	"github.com/gofiber/fiber/v2"
 
+	"project_uas/app/policy"
 	"project_uas/app/service"
 	"project_uas/middleware"
 )
@@ -15,19 +16,37 @@ func RegisterRoutes(
 	userService *service.UserService,
 	lecturerService *service.LecturerService,
 	reportService *service.ReportService,
+	sessionService *service.SessionService,
+	workflowService *service.WorkflowService,
+	notificationService *service.NotificationService,
+	reconcileService *service.ReconcileService,
+	rbacService *service.RBACService,
+	orgService *service.OrgService,
+	userImportService *service.UserImportService,
+	auditService *service.AuditService,
 ) {
 
-	api := app.Group("/api/v1")
+	// setiap route mendeklarasikan action policy (lihat policy.Rules)
+	can := middleware.Authorize
+	achievement := middleware.On(policy.KindAchievement, "id")
+	student := middleware.On(policy.KindStudent, "id")
+	lecturer := middleware.On(policy.KindLecturer, "id")
+
+	// setiap request yang mengubah data dicatat ke audit_logs
+	api := app.Group("/api/v1", middleware.Audit())
 
 	// =====================
 	// AUTH
 	// =====================
 	auth := api.Group("/auth")
 	{
-		auth.Post("/login", authService.Login)
-		auth.Post("/refresh", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNotImplemented) })
-		auth.Post("/logout",middleware.AuthMiddleware(),authService.Logout,)
-		auth.Get("/profile", middleware.AuthMiddleware(), authService.GetProfile)
+		auth.Post("/login", can("auth:login"), authService.Login)
+		auth.Post("/refresh", can("auth:refresh"), authService.Refresh)
+		auth.Post("/logout",middleware.AuthMiddleware(),can("auth:logout"),authService.Logout,)
+		auth.Get("/profile", middleware.AuthMiddleware(), can("auth:profile"), authService.GetProfile)
+		auth.Get("/sessions", middleware.AuthMiddleware(), can("session:own"), sessionService.ListMine)
+		auth.Delete("/sessions", middleware.AuthMiddleware(), can("session:own"), sessionService.RevokeOthers)
+		auth.Delete("/sessions/:id", middleware.AuthMiddleware(), can("session:own"), sessionService.RevokeMine)
 	}
 
 	// =====================
@@ -35,30 +54,114 @@ func RegisterRoutes(
 	// =====================
 	users := api.Group("/users", middleware.AuthMiddleware())
 {
-	users.Get("/", middleware.RequirePermission("users:read"), userService.GetAll)
-	users.Get("/:id", middleware.RequirePermission("users:read"), userService.GetByID)
-	users.Post("/", middleware.RequirePermission("users:create"), userService.Create)
-	users.Put("/:id", middleware.RequirePermission("users:update"), userService.Update)
-	users.Delete("/:id", middleware.RequirePermission("users:delete"), userService.Delete)
-	users.Put("/:id/role", middleware.RequirePermission("users:update-role"), userService.UpdateRole)
+	users.Get("/", can("user:list"), userService.GetAll)
+	users.Get("/:id", can("user:read"), userService.GetByID)
+	users.Post("/", can("user:create"), userService.Create)
+	users.Post("/import", can("user:import"), userImportService.Import)
+	users.Get("/imports/:id", can("user:import"), userImportService.GetImport)
+	users.Get("/imports/:id/report", can("user:import"), userImportService.DownloadReport)
+	users.Put("/:id", can("user:update"), userService.Update)
+	users.Delete("/:id", can("user:delete"), userService.Delete)
+	users.Put("/:id/role", can("user:update-role"), userService.UpdateRole)
+	users.Get("/:id/sessions", can("session:read"), sessionService.ListForUser)
+	users.Delete("/:id/sessions", can("session:revoke"), sessionService.RevokeAllForUser)
+	users.Delete("/:id/sessions/:sid", can("session:revoke"), sessionService.RevokeForUser)
 }
 
 
+	// =====================
+	// ROLES & PERMISSIONS (RBAC dinamis)
+	// =====================
+	roles := api.Group("/roles", middleware.AuthMiddleware())
+	{
+		roles.Get("/", can("role:read"), rbacService.ListRoles)
+		roles.Get("/:id", can("role:read"), rbacService.GetRole)
+		roles.Post("/", can("role:manage"), rbacService.CreateRole)
+		roles.Put("/:id", can("role:manage"), rbacService.UpdateRole)
+		roles.Delete("/:id", can("role:manage"), rbacService.DeleteRole)
+		roles.Put("/:id/permissions", can("role:manage"), rbacService.SetRolePermissions)
+		roles.Post("/:id/permissions", can("role:manage"), rbacService.SetRolePermissions)
+		roles.Delete("/:id/permissions/:permissionId", can("role:manage"), rbacService.RemoveRolePermission)
+	}
+
+	permissions := api.Group("/permissions", middleware.AuthMiddleware())
+	{
+		permissions.Get("/", can("role:read"), rbacService.ListPermissions)
+		permissions.Post("/", can("role:manage"), rbacService.CreatePermission)
+		permissions.Delete("/:id", can("role:manage"), rbacService.DeletePermission)
+	}
+
 	// =====================
 	// ACHIEVEMENTS
 	// =====================
+	// unduhan via signed URL didaftarkan sebelum group karena tanpa JWT
+	api.Get("/achievements/:id/attachments/:attachmentId/download", can("attachment:download"), achievementService.DownloadSignedAttachment)
+	api.Get("/achievements/:id/attachments/:attachmentId/preview/download", can("attachment:download"), achievementService.DownloadSignedPreview)
+
 	ach := api.Group("/achievements", middleware.AuthMiddleware())
 	{
-		ach.Get("/", middleware.OnlyAdmin(), achievementService.GetAll)
-		ach.Post("/", middleware.OnlyStudent(), achievementService.CreateAchievement)
-		ach.Post("/:id/submit", middleware.OnlyStudent(), achievementService.SubmitAchievement)
-		ach.Post("/:id/verify", middleware.OnlyLecturer(), achievementService.VerifyAchievement)
-		ach.Post("/:id/reject", middleware.OnlyLecturer(), achievementService.RejectAchievement)
-		ach.Get("/:id/history", achievementService.GetAchievementHistory)
-		ach.Post("/:id/attachments", achievementService.UploadAttachment)
-		ach.Get("/:id", achievementService.GetAchievementDetail)
-		ach.Delete("/:id", middleware.OnlyStudent(), achievementService.DeleteAchievement)
-		ach.Put("/:id", middleware.OnlyStudent(), achievementService.UpdateAchievement)
+		ach.Get("/", can("achievement:list"), achievementService.GetAll)
+		ach.Post("/", can("achievement:create"), achievementService.CreateAchievement)
+		ach.Post("/:id/submit", can("achievement:submit", achievement), achievementService.SubmitAchievement)
+		ach.Get("/pending", can("achievement:pending"), achievementService.GetPendingApprovals)
+		ach.Get("/search", can("achievement:search"), achievementService.SearchAchievements)
+		ach.Post("/:id/verify", can("achievement:decide", achievement), achievementService.VerifyAchievement)
+		ach.Post("/:id/reject", can("achievement:decide", achievement), achievementService.RejectAchievement)
+		ach.Post("/:id/request-revision", can("achievement:decide", achievement), achievementService.RequestRevision)
+		ach.Get("/:id/history", can("achievement:history", achievement), achievementService.GetAchievementHistory)
+		ach.Get("/:id/attachments", can("attachment:read", achievement), achievementService.ListAttachments)
+		ach.Post("/:id/attachments", can("attachment:write", achievement), achievementService.UploadAttachment)
+		ach.Get("/:id/attachments/:attachmentId", can("attachment:read", achievement), achievementService.DownloadAttachment)
+		ach.Get("/:id/attachments/:attachmentId/preview", can("attachment:read", achievement), achievementService.GetAttachmentPreview)
+		ach.Delete("/:id/attachments/:attachmentId", can("attachment:write", achievement), achievementService.DeleteAttachment)
+		ach.Get("/:id", can("achievement:read", achievement), achievementService.GetAchievementDetail)
+		ach.Delete("/:id", can("achievement:delete", achievement), achievementService.DeleteAchievement)
+		ach.Put("/:id", can("achievement:update", achievement), achievementService.UpdateAchievement)
+	}
+
+	// =====================
+	// WORKFLOWS (verification chains)
+	// =====================
+	workflows := api.Group("/workflows", middleware.AuthMiddleware(), can("workflow:manage"))
+	{
+		workflows.Get("/", workflowService.GetAll)
+		workflows.Post("/", workflowService.Create)
+		workflows.Delete("/:id", workflowService.Delete)
+	}
+
+	// =====================
+	// ADMIN: integritas Postgres ↔ Mongo ↔ storage
+	// =====================
+	admin := api.Group("/admin", middleware.AuthMiddleware(), can("integrity:check"))
+	{
+		admin.Get("/integrity", reconcileService.Check)
+		admin.Post("/integrity", reconcileService.Check)
+	}
+
+	// =====================
+	// ADMIN: jejak audit (append-only)
+	// =====================
+	auditLogs := api.Group("/audit-logs", middleware.AuthMiddleware(), can("audit:read"))
+	{
+		auditLogs.Get("/", auditService.GetAll)
+		auditLogs.Get("/:id", auditService.GetByID)
+	}
+
+	// =====================
+	// NOTIFICATIONS
+	// =====================
+	// stream didaftarkan sebelum group karena memakai auth via query token
+	api.Get("/notifications/stream", middleware.StreamAuthMiddleware(), can("notification:own"), notificationService.Stream)
+
+	notifications := api.Group("/notifications", middleware.AuthMiddleware(), can("notification:own"))
+	{
+		notifications.Get("/", notificationService.GetAll)
+		notifications.Get("/unread-count", notificationService.UnreadCount)
+		notifications.Get("/preferences", notificationService.GetPreferences)
+		notifications.Put("/preferences", notificationService.UpdatePreferences)
+		notifications.Put("/read-all", notificationService.MarkAllRead)
+		notifications.Put("/:id/read", notificationService.MarkRead)
+		notifications.Delete("/:id", notificationService.Delete)
 	}
 
 	// =====================
@@ -66,11 +169,15 @@ func RegisterRoutes(
 	// =====================
 	students := api.Group("/students", middleware.AuthMiddleware())
 {
-		students.Get("/", studentService.GetAll)
-		students.Get("/profile", studentService.GetProfile)
-		students.Get("/:id", studentService.GetByID)
-		students.Get("/:id/achievements", studentService.GetAchievements)
-		students.Put("/:id/advisor", middleware.OnlyAdmin(), studentService.UpdateAdvisor)
+		students.Get("/", can("student:list"), studentService.GetAll)
+		students.Get("/profile", can("student:profile"), studentService.GetProfile)
+		students.Get("/:id", can("student:read", student), studentService.GetByID)
+		students.Get("/:id/achievements", can("student:achievements", student), studentService.GetAchievements)
+		students.Post("/advisors", can("student:bulk-assign-advisor"), studentService.BulkAssignAdvisors)
+		students.Post("/advisors/auto", can("student:bulk-assign-advisor"), studentService.AutoAssignAdvisors)
+		students.Put("/:id/advisor", can("student:assign-advisor", student), studentService.UpdateAdvisor)
+		students.Get("/:id/advisor-history", can("student:advisor-history", student), studentService.GetAdvisorHistory)
+		students.Put("/:id/study-program", can("student:link-program", student), studentService.UpdateStudyProgram)
 		
 }
 
@@ -80,18 +187,59 @@ func RegisterRoutes(
 	// =====================
 	lecturers := api.Group("/lecturers", middleware.AuthMiddleware())
 {
-	lecturers.Get("/", lecturerService.GetAll)
-	lecturers.Get("/profile", middleware.OnlyLecturer(), lecturerService.GetProfile)
-	lecturers.Get("/:id/advisees",middleware.OnlyLecturer(),achievementService.GetAdviseeAchievements,)
+	lecturers.Get("/", can("lecturer:list"), lecturerService.GetAll)
+	lecturers.Get("/profile", can("lecturer:profile"), lecturerService.GetProfile)
+	lecturers.Get("/advisor-load", can("lecturer:advisor-load"), lecturerService.GetAdvisorLoad)
+	lecturers.Get("/:id/advisees",can("lecturer:advisees", lecturer),achievementService.GetAdviseeAchievements,)
+	lecturers.Put("/:id/department", can("lecturer:link-department", lecturer), lecturerService.UpdateDepartment)
 }
 
+	// =====================
+	// ORGANISASI: fakultas → departemen → program studi
+	// =====================
+	faculties := api.Group("/faculties", middleware.AuthMiddleware())
+	{
+		faculties.Get("/", can("org:read"), orgService.ListFaculties)
+		faculties.Get("/:id", can("org:read"), orgService.GetFaculty)
+		faculties.Post("/", can("org:manage"), orgService.CreateFaculty)
+		faculties.Put("/:id", can("org:manage"), orgService.UpdateFaculty)
+		faculties.Delete("/:id", can("org:manage"), orgService.DeleteFaculty)
+	}
+
+	departments := api.Group("/departments", middleware.AuthMiddleware())
+	{
+		departments.Get("/", can("org:read"), orgService.ListDepartments)
+		departments.Get("/:id", can("org:read"), orgService.GetDepartment)
+		departments.Post("/", can("org:manage"), orgService.CreateDepartment)
+		departments.Put("/:id", can("org:manage"), orgService.UpdateDepartment)
+		departments.Delete("/:id", can("org:manage"), orgService.DeleteDepartment)
+	}
+
+	programs := api.Group("/study-programs", middleware.AuthMiddleware())
+	{
+		programs.Get("/", can("org:read"), orgService.ListStudyPrograms)
+		programs.Get("/:id", can("org:read"), orgService.GetStudyProgram)
+		programs.Post("/", can("org:manage"), orgService.CreateStudyProgram)
+		programs.Put("/:id", can("org:manage"), orgService.UpdateStudyProgram)
+		programs.Delete("/:id", can("org:manage"), orgService.DeleteStudyProgram)
+	}
+
+	// delegasi scope admin per unit (admin fakultas / departemen / prodi)
+	unitAdmins := api.Group("/unit-admins", middleware.AuthMiddleware(), can("unit-admin:manage"))
+	{
+		unitAdmins.Get("/", orgService.ListUnitAdmins)
+		unitAdmins.Post("/", orgService.AddUnitAdmin)
+		unitAdmins.Delete("/:userId/:unitId", orgService.RemoveUnitAdmin)
+	}
+
 	// =====================
 	// REPORTS
 	// =====================
 	reports := api.Group("/reports", middleware.AuthMiddleware())
 {
-	reports.Get("/student/:id",middleware.OnlyAdmin(),reportService.GetStudentReport,)
-	reports.Get("/statistics",middleware.OnlyAdmin(),reportService.GetAchievementStats,)
+	reports.Get("/student/:id",can("report:student", student),reportService.GetStudentReport,)
+	reports.Get("/statistics",can("report:statistics"),reportService.GetAchievementStats,)
+	reports.Get("/units", can("report:units"), reportService.GetUnitReport)
 }
 
 }